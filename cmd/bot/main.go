@@ -6,12 +6,18 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // user time zones must resolve in minimal containers
 
 	"hh-vacancy-bot/internal/api/headhunter"
 	"hh-vacancy-bot/internal/bot"
+	"hh-vacancy-bot/internal/bot/middleware"
 	"hh-vacancy-bot/internal/bot/scheduler"
 	"hh-vacancy-bot/internal/config"
+	"hh-vacancy-bot/internal/dictionaries"
 	"hh-vacancy-bot/internal/logger"
+	"hh-vacancy-bot/internal/source"
+	"hh-vacancy-bot/internal/source/hh"
+	"hh-vacancy-bot/internal/source/rss"
 	"hh-vacancy-bot/internal/storage/postgres"
 	"hh-vacancy-bot/internal/storage/redis"
 
@@ -60,11 +66,28 @@ func main() {
 
 	log.Info("Redis connected successfully")
 
-	hhClient := headhunter.New(cfg.HHAPIBaseURL, cfg.HHAPITimeout, log)
+	hhClient := headhunter.New(cfg.HHAPIBaseURL, cfg.HHAPITimeout, headhunter.RetryPolicy{
+		MaxAttempts: cfg.HHAPIMaxAttempts,
+		BaseDelay:   cfg.HHAPIRetryBaseDelay,
+		MaxDelay:    cfg.HHAPIRetryMaxDelay,
+	}, log)
 	log.Info("HeadHunter API client created")
 
+	feedClient := rss.NewHTTPClient(cfg.HHAPITimeout)
+	sources := source.NewRegistry(
+		hh.New(hhClient),
+		func(url string) source.VacancySource { return rss.New(url, feedClient, log) },
+		log,
+	)
+
+	hhLimiter := middleware.NewHHAPILimiter(cache,
+		middleware.HHLimit{Rate: cfg.HHAPIInteractiveRPM, Burst: cfg.HHAPIInteractiveBurst},
+		middleware.HHLimit{Rate: cfg.HHAPIBackgroundRPM, Burst: cfg.HHAPIBackgroundBurst},
+		log,
+	)
+
 	log.Info("initializing Telegram bot...")
-	tgBot, err := bot.New(cfg, store, cache, hhClient, log)
+	tgBot, err := bot.New(cfg, store, cache, hhClient, sources, hhLimiter, log)
 	if err != nil {
 		log.Fatal("failed to create bot", zap.Error(err))
 	}
@@ -83,18 +106,28 @@ func main() {
 		cancel()
 	}()
 
+	dictLoader := dictionaries.NewLoader(hhClient, cache, log)
+	go dictLoader.Start(ctx)
+
 	log.Info("starting vacancy checker...")
 	checker := scheduler.New(
-		tgBot.GetBot(),
+		tgBot.GetSender(),
 		store,
 		cache,
-		hhClient,
+		sources,
+		hhLimiter,
 		cfg,
 		log,
 	)
 
 	go checker.Start(ctx)
 
+	dispatcher := scheduler.NewDispatcher(tgBot.GetSender(), store, log)
+	go dispatcher.Start(ctx)
+
+	maintenance := scheduler.NewMaintenance(store, cache, cfg, log)
+	go maintenance.Start(ctx)
+
 	log.Info("bot is running...")
 	log.Info("press Ctrl+C to stop")
 
@@ -105,4 +138,4 @@ func main() {
 	log.Info("shutting down gracefully...")
 
 	log.Info("bot stopped")
-}
\ No newline at end of file
+}
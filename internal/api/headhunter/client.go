@@ -16,11 +16,16 @@ import (
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	retry      RetryPolicy
 	logger     *zap.Logger
 	userAgent  string
 }
 
-func New(baseURL string, timeout time.Duration, logger *zap.Logger) *Client {
+func New(baseURL string, timeout time.Duration, retry RetryPolicy, logger *zap.Logger) *Client {
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+
 	return &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
@@ -31,6 +36,7 @@ func New(baseURL string, timeout time.Duration, logger *zap.Logger) *Client {
 				IdleConnTimeout:     90 * time.Second,
 			},
 		},
+		retry:     retry,
 		logger:    logger,
 		userAgent: "HH-Vacancy-Bot/1.0",
 	}
@@ -43,46 +49,38 @@ func (c *Client) doRequest(ctx context.Context, method, path string, params url.
 		fullURL += "?" + params.Encode()
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-
-	req.Header.Set("User-Agent", c.userAgent)
-	req.Header.Set("Accept", "application/json")
-
 	var lastErr error
-	for attempt := 0; attempt < 3; attempt++ {
+	var wait time.Duration
+	for attempt := 0; attempt < c.retry.MaxAttempts; attempt++ {
 		if attempt > 0 {
-			// Exponential backoff
-			backoff := time.Duration(attempt) * time.Second
+			if wait == 0 {
+				wait = c.retry.Backoff(attempt)
+			}
 			c.logger.Debug("retrying request",
 				zap.String("url", fullURL),
 				zap.Int("attempt", attempt),
-				zap.Duration("backoff", backoff),
+				zap.Duration("backoff", wait),
 			)
-			time.Sleep(backoff)
+			if err := sleepContext(ctx, wait); err != nil {
+				return nil, fmt.Errorf("request cancelled: %w (last error: %v)", err, lastErr)
+			}
+			wait = 0
 		}
 
-		resp, err := c.httpClient.Do(req)
+		status, header, body, err := c.attempt(ctx, method, fullURL)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("request cancelled: %w", ctx.Err())
+			}
 			lastErr = err
 			continue
 		}
 
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			lastErr = fmt.Errorf("read response body: %w", err)
-			continue
-		}
-
 		// check response code
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if status >= 200 && status < 300 {
 			c.logger.Debug("successful request",
 				zap.String("url", fullURL),
-				zap.Int("status", resp.StatusCode),
+				zap.Int("status", status),
 			)
 			return body, nil
 		}
@@ -90,35 +88,61 @@ func (c *Client) doRequest(ctx context.Context, method, path string, params url.
 		// log errors
 		c.logger.Error("API error",
 			zap.String("url", fullURL),
-			zap.Int("status", resp.StatusCode),
+			zap.Int("status", status),
 			zap.String("body", string(body)),
 		)
 
-		switch resp.StatusCode {
-		case http.StatusTooManyRequests:
-			c.logger.Warn("rate limit hit, backing off")
-			time.Sleep(5 * time.Second)
-			lastErr = fmt.Errorf("rate limit exceeded")
-			continue
-		case http.StatusBadRequest:
-			// Плохой запрос - не retry
-			var apiErr ErrorResponse
-			if err := json.Unmarshal(body, &apiErr); err == nil {
-				return nil, fmt.Errorf("bad request: %s", apiErr.Description)
+		apiErr := newAPIError(status, body)
+		retryAfter, hasRetryAfter := parseRetryAfter(header, time.Now())
+
+		switch {
+		case status == http.StatusTooManyRequests:
+			lastErr = &RateLimitError{RetryAfter: retryAfter, Err: apiErr}
+			// waiting longer than the policy allows would stall the caller
+			if hasRetryAfter && c.retry.MaxDelay > 0 && retryAfter > c.retry.MaxDelay {
+				return nil, lastErr
+			}
+			wait = retryAfter
+			c.logger.Warn("rate limit hit, backing off", zap.Duration("retry_after", retryAfter))
+		case status >= http.StatusInternalServerError:
+			lastErr = apiErr
+			if hasRetryAfter && c.retry.MaxDelay > 0 && retryAfter > c.retry.MaxDelay {
+				return nil, lastErr
 			}
-			return nil, fmt.Errorf("bad request: %s", string(body))
-		case http.StatusNotFound:
-			return nil, fmt.Errorf("not found")
-		case http.StatusForbidden:
-			return nil, fmt.Errorf("forbidden")
+			wait = retryAfter
 		default:
-			lastErr = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			// other 4xx will not get better on retry
+			return nil, apiErr
 		}
 	}
 
 	return nil, fmt.Errorf("request failed after retries: %w", lastErr)
 }
 
+// attempt sends one request; the body is read and closed before returning
+func (c *Client) attempt(ctx context.Context, method, fullURL string) (int, http.Header, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, nil)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	return resp.StatusCode, resp.Header, body, nil
+}
+
 // get выполняет GET запрос
 func (c *Client) get(ctx context.Context, path string, params url.Values) ([]byte, error) {
 	return c.doRequest(ctx, http.MethodGet, path, params)
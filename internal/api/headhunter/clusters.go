@@ -0,0 +1,35 @@
+package headhunter
+
+import "net/url"
+
+// Cluster is a facet of a search response, e.g. area or experience, returned with clusters=true
+type Cluster struct {
+	ID    string        `json:"id"`
+	Name  string        `json:"name"`
+	Items []ClusterItem `json:"items"`
+}
+
+// ClusterItem is one facet value; URL is the search narrowed down by it
+type ClusterItem struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Count int    `json:"count"`
+}
+
+// SearchArgument describes a param HH applied to the search
+type SearchArgument struct {
+	Argument         string  `json:"argument"`
+	Value            string  `json:"value"`
+	ValueDescription *string `json:"value_description"`
+	ClusterGroup     *IDName `json:"cluster_group"`
+	DisableURL       string  `json:"disable_url"`
+}
+
+// ParamValues returns values of the cluster's param in the narrowed search URL
+func (item ClusterItem) ParamValues(param string) []string {
+	u, err := url.Parse(item.URL)
+	if err != nil {
+		return nil
+	}
+	return u.Query()[param]
+}
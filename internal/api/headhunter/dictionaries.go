@@ -0,0 +1,51 @@
+package headhunter
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Dictionaries is the subset of /dictionaries the bot uses for filter options
+type Dictionaries struct {
+	Experience          []IDName             `json:"experience"`
+	Schedule            []IDName             `json:"schedule"`
+	Employment          []IDName             `json:"employment"`
+	Currency            []DictionaryCurrency `json:"currency"`
+	VacancySearchOrder  []IDName             `json:"vacancy_search_order"`
+	VacancySearchFields []IDName             `json:"vacancy_search_fields"`
+	VacancyLabel        []IDName             `json:"vacancy_label"`
+}
+
+type DictionaryCurrency struct {
+	Code    string  `json:"code"`
+	Abbr    string  `json:"abbr"`
+	Name    string  `json:"name"`
+	Default bool    `json:"default"`
+	Rate    float64 `json:"rate"`
+	InUse   bool    `json:"in_use"`
+}
+
+func (c *Client) GetDictionaries(ctx context.Context) (*Dictionaries, error) {
+	data, err := c.get(ctx, "/dictionaries", nil)
+	if err != nil {
+		c.logger.Error("failed to get dictionaries", zap.Error(err))
+		return nil, fmt.Errorf("get dictionaries: %w", err)
+	}
+
+	var dictionaries Dictionaries
+	if err := c.parseResponse(data, &dictionaries); err != nil {
+		c.logger.Error("failed to parse dictionaries response", zap.Error(err))
+		return nil, err
+	}
+
+	c.logger.Debug("dictionaries retrieved",
+		zap.Int("experience", len(dictionaries.Experience)),
+		zap.Int("schedule", len(dictionaries.Schedule)),
+		zap.Int("employment", len(dictionaries.Employment)),
+		zap.Int("currency", len(dictionaries.Currency)),
+	)
+
+	return &dictionaries, nil
+}
@@ -0,0 +1,134 @@
+package headhunter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound    = errors.New("hh api: not found")
+	ErrForbidden   = errors.New("hh api: forbidden")
+	ErrBadRequest  = errors.New("hh api: bad request")
+	ErrRateLimited = errors.New("hh api: rate limited")
+	ErrUnavailable = errors.New("hh api: unavailable")
+)
+
+// APIError is a non-2xx HH response. It matches the sentinel errors above via errors.Is.
+type APIError struct {
+	StatusCode  int
+	Description string
+	Errors      []ErrorItem
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: status}
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(body, &resp); err == nil {
+		apiErr.Description = resp.Description
+		apiErr.Errors = resp.Errors
+	} else if len(body) > 0 {
+		apiErr.Description = strings.TrimSpace(string(body))
+	}
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("hh api: status %d", e.StatusCode))
+
+	if e.Description != "" {
+		sb.WriteString(": " + e.Description)
+	}
+
+	for _, item := range e.Errors {
+		sb.WriteString(fmt.Sprintf(" [%s", item.Type))
+		if item.Value != "" {
+			sb.WriteString(": " + item.Value)
+		}
+		sb.WriteString("]")
+	}
+
+	return sb.String()
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnavailable:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// Values lists the arguments HH rejected, e.g. "text" for a malformed query
+func (e *APIError) Values() []string {
+	var values []string
+	for _, item := range e.Errors {
+		if item.Value != "" {
+			values = append(values, item.Value)
+		}
+	}
+	return values
+}
+
+// RateLimitError is returned when HH keeps answering 429 or asks to wait longer than the retry policy allows
+type RateLimitError struct {
+	RetryAfter time.Duration // zero when HH did not say
+	Err        *APIError
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("hh api: rate limited, retry after %s", e.RetryAfter)
+	}
+	return "hh api: rate limited"
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+func (e *RateLimitError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// RetryAfter reports how long HH asked to wait, if err is a rate limit
+func RetryAfter(err error) (time.Duration, bool) {
+	var rateErr *RateLimitError
+	if errors.As(err, &rateErr) {
+		return rateErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// IsTemporary reports failures worth retrying later: rate limits, HH 5xx, timeouts and network errors
+func IsTemporary(err error) bool {
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable) {
+		return true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -46,8 +46,8 @@ type VacancySearchResponse struct {
 	Pages      int           `json:"pages"`
 	Page       int           `json:"page"`
 	PerPage    int           `json:"per_page"`
-	Clusters   interface{}   `json:"clusters,omitempty"`
-	Arguments  interface{}   `json:"arguments,omitempty"`
+	Clusters   []Cluster        `json:"clusters,omitempty"`
+	Arguments  []SearchArgument `json:"arguments,omitempty"`
 	AlternateURL string      `json:"alternate_url,omitempty"`
 }
 
@@ -223,10 +223,12 @@ type City struct {
 }
 
 type ErrorResponse struct {
-	Errors []struct {
-		Type  string `json:"type"`
-		Value string `json:"value"`
-	} `json:"errors"`
-	Description string `json:"description"`
+	Errors      []ErrorItem `json:"errors"`
+	Description string      `json:"description"`
+}
+
+type ErrorItem struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
 }
 
@@ -0,0 +1,155 @@
+package headhunter
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const WebSearchURL = "https://hh.ru/search/vacancy"
+
+var ErrNotSearchURL = errors.New("not an hh.ru vacancy search url")
+
+// search_period values accepted by the hh.ru web UI
+var webSearchPeriods = []int{1, 3, 7, 30}
+
+// web-only params that do not change search results
+var webUIParams = map[string]bool{
+	"enable_snippets": true,
+	"L_save_area":     true,
+	"hhtmFrom":        true,
+	"hhtmFromLabel":   true,
+	"items_on_page":   true,
+	"page":            true,
+	"ored_clusters":   true,
+	"search_session":  true,
+	"from":            true,
+	"customDomain":    true,
+}
+
+// SearchPageURL builds an hh.ru web link showing the same results as params
+func SearchPageURL(params VacancySearchParams) string {
+	values := params.filterValues()
+
+	// the web UI names the currency param differently
+	if currency := values.Get("currency"); currency != "" {
+		values.Del("currency")
+		values.Set("currency_code", currency)
+	}
+
+	for _, period := range webSearchPeriods {
+		if params.PublishedWithinDays > 0 && params.PublishedWithinDays <= period {
+			values.Set("search_period", strconv.Itoa(period))
+			break
+		}
+	}
+
+	if len(values) == 0 {
+		return WebSearchURL
+	}
+
+	return WebSearchURL + "?" + values.Encode()
+}
+
+// ParseSearchPageURL maps an hh.ru web search link back to search params.
+// Names of params that cannot be represented are returned as ignored.
+func ParseSearchPageURL(raw string) (VacancySearchParams, []string, error) {
+	var params VacancySearchParams
+
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return params, nil, ErrNotSearchURL
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if host != "hh.ru" && !strings.HasSuffix(host, ".hh.ru") {
+		return params, nil, ErrNotSearchURL
+	}
+
+	if strings.TrimSuffix(u.Path, "/") != "/search/vacancy" {
+		return params, nil, ErrNotSearchURL
+	}
+
+	var ignored []string
+	for key, values := range u.Query() {
+		values = nonEmpty(values)
+
+		switch key {
+		case "text":
+			params.Text = strings.Join(values, " ")
+		case "excluded_text":
+			params.ExcludedText = strings.Join(values, ", ")
+		case "search_field":
+			params.SearchField = values
+		case "area":
+			params.Area = values
+		case "metro":
+			params.Metro = values
+		case "industry":
+			params.Industry = values
+		case "employer_id":
+			params.EmployerID = values
+		case "label":
+			params.Label = values
+		case "order_by":
+			if len(values) > 0 {
+				params.OrderBy = values[0]
+			}
+		case "currency", "currency_code":
+			if len(values) > 0 {
+				params.Currency = values[0]
+			}
+		case "experience":
+			params.Experience = values
+		case "schedule":
+			params.Schedule = values
+		case "employment":
+			params.Employment = values
+		case "professional_role":
+			params.ProfessionalRole = values
+		case "salary":
+			if len(values) > 0 {
+				if salary, err := strconv.Atoi(values[0]); err == nil && salary > 0 {
+					params.Salary = salary
+				}
+			}
+		case "search_period":
+			if len(values) > 0 {
+				if days, err := strconv.Atoi(values[0]); err == nil && days > 0 {
+					params.PublishedWithinDays = days
+				}
+			}
+		case "only_with_salary":
+			params.OnlyWithSalary = len(values) > 0 && values[0] == "true"
+		default:
+			if !webUIParams[key] && len(values) > 0 {
+				ignored = append(ignored, key)
+			}
+		}
+	}
+
+	sort.Strings(ignored)
+
+	return params, ignored, nil
+}
+
+// IsSearchPageURL reports whether text looks like an hh.ru web search link
+func IsSearchPageURL(text string) bool {
+	if strings.ContainsAny(strings.TrimSpace(text), " \n") {
+		return false
+	}
+	_, _, err := ParseSearchPageURL(text)
+	return err == nil
+}
+
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
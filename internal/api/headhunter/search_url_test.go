@@ -0,0 +1,143 @@
+package headhunter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSearchPageURLRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		params VacancySearchParams
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "text only",
+			params: VacancySearchParams{
+				Text: "golang developer",
+			},
+		},
+		{
+			name: "all web params",
+			params: VacancySearchParams{
+				Text:                "go OR golang",
+				ExcludedText:        "1с, битрикс",
+				SearchField:         []string{"name", "description"},
+				Area:                []string{"1", "2"},
+				Metro:               []string{"1.1"},
+				Experience:          []string{"between1And3", "between3And6"},
+				Salary:              250000,
+				Currency:            "RUR",
+				OnlyWithSalary:      true,
+				Schedule:            []string{"remote"},
+				Employment:          []string{"full"},
+				ProfessionalRole:    []string{"96", "104"},
+				Industry:            []string{"7"},
+				EmployerID:          []string{"1740"},
+				Label:               []string{"not_from_agency"},
+				OrderBy:             "publication_time",
+				PublishedWithinDays: 3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := SearchPageURL(tt.params)
+
+			got, ignored, err := ParseSearchPageURL(link)
+			if err != nil {
+				t.Fatalf("ParseSearchPageURL(%q): %v", link, err)
+			}
+			if len(ignored) > 0 {
+				t.Errorf("ParseSearchPageURL(%q) ignored %v", link, ignored)
+			}
+			if !reflect.DeepEqual(got, tt.params) {
+				t.Errorf("round trip of %q:\n got  %+v\n want %+v", link, got, tt.params)
+			}
+		})
+	}
+}
+
+func TestSearchPageURLPeriod(t *testing.T) {
+	tests := []struct {
+		days int
+		want string
+	}{
+		{0, WebSearchURL},
+		{1, WebSearchURL + "?search_period=1"},
+		{2, WebSearchURL + "?search_period=3"},
+		{7, WebSearchURL + "?search_period=7"},
+		{14, WebSearchURL + "?search_period=30"},
+		{30, WebSearchURL + "?search_period=30"},
+	}
+
+	for _, tt := range tests {
+		if got := SearchPageURL(VacancySearchParams{PublishedWithinDays: tt.days}); got != tt.want {
+			t.Errorf("SearchPageURL(days=%d) = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchPageURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		want        VacancySearchParams
+		wantIgnored []string
+		wantErr     error
+	}{
+		{
+			name: "regional subdomain and web ui params",
+			raw:  "https://spb.hh.ru/search/vacancy/?text=go&area=2&page=3&hhtmFrom=main&enable_snippets=true",
+			want: VacancySearchParams{Text: "go", Area: []string{"2"}},
+		},
+		{
+			name: "web currency param",
+			raw:  "https://hh.ru/search/vacancy?salary=100000&currency_code=USD",
+			want: VacancySearchParams{Salary: 100000, Currency: "USD"},
+		},
+		{
+			name:        "unsupported params are reported sorted",
+			raw:         "https://hh.ru/search/vacancy?text=go&part_time=employment_project&accept_temporary=true",
+			want:        VacancySearchParams{Text: "go"},
+			wantIgnored: []string{"accept_temporary", "part_time"},
+		},
+		{
+			name: "empty values are dropped",
+			raw:  "https://hh.ru/search/vacancy?text=go&area=&salary=abc&fancy=",
+			want: VacancySearchParams{Text: "go"},
+		},
+		{
+			name:    "another site",
+			raw:     "https://example.com/search/vacancy?text=go",
+			wantErr: ErrNotSearchURL,
+		},
+		{
+			name:    "not a search page",
+			raw:     "https://hh.ru/vacancy/123",
+			wantErr: ErrNotSearchURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ignored, err := ParseSearchPageURL(tt.raw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("params:\n got  %+v\n want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(ignored, tt.wantIgnored) {
+				t.Errorf("ignored = %v, want %v", ignored, tt.wantIgnored)
+			}
+		})
+	}
+}
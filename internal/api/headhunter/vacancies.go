@@ -2,20 +2,39 @@ package headhunter
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"time"
 
 	"go.uber.org/zap"
 )
 
+// OrderByPublicationTime sorts results newest first
+const OrderByPublicationTime = "publication_time"
+
 type VacancySearchParams struct {
 	Text                string
-	Area                string // area id
-	Experience          string
+	ExcludedText        string
+	SearchField         []string // name, company_name, description
+	Area                []string // area ids
+	Metro               []string // metro station or line ids
+	Experience          []string
 	Salary              int
-	Schedule            string
+	SalaryTo            int // HH has no upper bound param, applied after the search
+	Currency            string
+	OnlyWithSalary      bool
+	Schedule            []string
+	Employment          []string
+	ProfessionalRole    []string
+	Industry            []string
+	EmployerID          []string
+	Label               []string
+	OrderBy             string
+	Clusters            bool // ask HH for the facet breakdown
 	Page                int
 	PerPage             int
 	DateFrom            *time.Time
@@ -23,54 +42,129 @@ type VacancySearchParams struct {
 	PublishedWithinDays int
 }
 
-func (c *Client) SearchVacancies(ctx context.Context, params VacancySearchParams) (*VacancySearchResponse, error) {
+// QueryValues encodes params as /vacancies query parameters
+func (params VacancySearchParams) QueryValues() url.Values {
+	queryParams := params.filterValues()
+
+	// pagination
+	if params.Page > 0 {
+		queryParams.Set("page", strconv.Itoa(params.Page))
+	}
+
+	if params.PerPage > 0 {
+		queryParams.Set("per_page", strconv.Itoa(params.PerPage))
+	} else {
+		queryParams.Set("per_page", "20")
+	}
+
+	if params.DateFrom != nil {
+		queryParams.Set("date_from", params.DateFrom.Format("2006-01-02T15:04:05-0700"))
+	}
+
+	if params.DateTo != nil {
+		queryParams.Set("date_to", params.DateTo.Format("2006-01-02T15:04:05-0700"))
+	}
+
+	if params.Clusters {
+		queryParams.Set("clusters", "true")
+	}
+
+	return queryParams
+}
+
+// CanonicalKey identifies the HH request the params produce; searches with equal filters share it
+func (params VacancySearchParams) CanonicalKey() string {
+	values := params.QueryValues()
+	for key, v := range values {
+		sorted := append([]string(nil), v...)
+		sort.Strings(sorted)
+		values[key] = sorted
+	}
+
+	// Encode sorts keys, so equal filters give equal strings
+	sum := sha1.Sum([]byte(values.Encode()))
+	return hex.EncodeToString(sum[:10])
+}
+
+// filterValues encodes the search criteria shared by the API and hh.ru web links
+func (params VacancySearchParams) filterValues() url.Values {
 	queryParams := url.Values{}
 
 	if params.Text != "" {
 		queryParams.Set("text", params.Text)
 	}
 
-	if params.Area != "" {
-		queryParams.Set("area", params.Area)
+	if params.ExcludedText != "" {
+		queryParams.Set("excluded_text", params.ExcludedText)
 	}
 
-	if params.Experience != "" {
-		queryParams.Set("experience", params.Experience)
+	for _, field := range params.SearchField {
+		queryParams.Add("search_field", field)
+	}
+
+	for _, area := range params.Area {
+		queryParams.Add("area", area)
+	}
+
+	for _, metro := range params.Metro {
+		queryParams.Add("metro", metro)
+	}
+
+	for _, exp := range params.Experience {
+		queryParams.Add("experience", exp)
 	}
 
 	if params.Salary > 0 {
 		queryParams.Set("salary", strconv.Itoa(params.Salary))
+	}
+
+	if params.Currency != "" {
+		queryParams.Set("currency", params.Currency)
+	}
+
+	if params.OnlyWithSalary {
 		queryParams.Set("only_with_salary", "true")
 	}
 
-	if params.Schedule != "" {
-		queryParams.Set("schedule", params.Schedule)
+	for _, schedule := range params.Schedule {
+		queryParams.Add("schedule", schedule)
 	}
 
-	// pagination
-	if params.Page > 0 {
-		queryParams.Set("page", strconv.Itoa(params.Page))
+	for _, employment := range params.Employment {
+		queryParams.Add("employment", employment)
 	}
 
-	if params.PerPage > 0 {
-		queryParams.Set("per_page", strconv.Itoa(params.PerPage))
-	} else {
-		queryParams.Set("per_page", "20")
+	for _, role := range params.ProfessionalRole {
+		queryParams.Add("professional_role", role)
 	}
 
-	if params.DateFrom != nil {
-		queryParams.Set("date_from", params.DateFrom.Format("2006-01-02T15:04:05-0700"))
+	for _, industry := range params.Industry {
+		queryParams.Add("industry", industry)
 	}
 
-	if params.DateTo != nil {
-		queryParams.Set("date_to", params.DateTo.Format("2006-01-02T15:04:05-0700"))
+	for _, employer := range params.EmployerID {
+		queryParams.Add("employer_id", employer)
+	}
+
+	for _, label := range params.Label {
+		queryParams.Add("label", label)
+	}
+
+	if params.OrderBy != "" {
+		queryParams.Set("order_by", params.OrderBy)
 	}
 
+	return queryParams
+}
+
+func (c *Client) SearchVacancies(ctx context.Context, params VacancySearchParams) (*VacancySearchResponse, error) {
+	queryParams := params.QueryValues()
+
 	data, err := c.get(ctx, "/vacancies", queryParams)
 	if err != nil {
 		c.logger.Error("failed to search vacancies",
 			zap.String("text", params.Text),
-			zap.String("area", params.Area),
+			zap.Strings("area", params.Area),
 			zap.Error(err),
 		)
 		return nil, fmt.Errorf("search vacancies: %w", err)
@@ -86,7 +180,7 @@ func (c *Client) SearchVacancies(ctx context.Context, params VacancySearchParams
 		zap.Int("found", response.Found),
 		zap.Int("returned", len(response.Items)),
 		zap.String("text", params.Text),
-		zap.String("area", params.Area),
+		zap.Strings("area", params.Area),
 	)
 
 	return &response, nil
@@ -8,7 +8,9 @@ import (
 	"hh-vacancy-bot/internal/api/headhunter"
 	"hh-vacancy-bot/internal/bot/handlers"
 	"hh-vacancy-bot/internal/bot/middleware"
+	"hh-vacancy-bot/internal/bot/sender"
 	"hh-vacancy-bot/internal/config"
+	"hh-vacancy-bot/internal/source"
 	"hh-vacancy-bot/internal/storage/postgres"
 	"hh-vacancy-bot/internal/storage/redis"
 
@@ -22,6 +24,9 @@ type Bot struct {
 	store    *postgres.Store
 	cache    *redis.Cache
 	hhClient *headhunter.Client
+	sources  *source.Registry
+	limiter  *middleware.HHAPILimiter
+	sender   *sender.Queue
 	config   *config.Config
 	logger   *zap.Logger
 }
@@ -31,6 +36,8 @@ func New(
 	store *postgres.Store,
 	cache *redis.Cache,
 	hhClient *headhunter.Client,
+	sources *source.Registry,
+	limiter *middleware.HHAPILimiter,
 	logger *zap.Logger,
 ) (*Bot, error) {
 	pref := tele.Settings{
@@ -43,11 +50,19 @@ func New(
 		return nil, fmt.Errorf("failed to create bot: %w", err)
 	}
 
+	queue := sender.New(b, cache, sender.Limits{
+		PerSecond:       cfg.TelegramMessagesPerSecond,
+		PerChatInterval: cfg.TelegramChatInterval,
+	}, logger)
+
 	bot := &Bot{
 		bot:      b,
 		store:    store,
 		cache:    cache,
 		hhClient: hhClient,
+		sources:  sources,
+		limiter:  limiter,
+		sender:   queue,
 		config:   cfg,
 		logger:   logger,
 	}
@@ -62,6 +77,9 @@ func New(
 }
 
 func (b *Bot) setupMiddleware() {
+	// first, so replies from the other middleware are queued too
+	b.bot.Use(middleware.SendQueue(b.sender))
+
 	b.bot.Use(middleware.Recovery(b.logger))
 
 	b.bot.Use(middleware.Logger(b.logger))
@@ -71,20 +89,27 @@ func (b *Bot) setupMiddleware() {
 
 func (b *Bot) registerHandlers() {
 	ctx := &handlers.Context{
-		Store:    b.store,
-		Cache:    b.cache,
-		HHClient: b.hhClient,
-		Config:   b.config,
-		Logger:   b.logger,
+		Store:     b.store,
+		Cache:     b.cache,
+		HHClient:  b.hhClient,
+		Sources:   b.sources,
+		HHLimiter: b.limiter,
+		Sender:    b.sender,
+		Config:    b.config,
+		Logger:    b.logger,
 	}
 
 	b.bot.Handle("/start", handlers.HandleStart(ctx))
 	b.bot.Handle("/help", handlers.HandleHelp(ctx))
 	b.bot.Handle("/filters", handlers.HandleFilters(ctx))
 	b.bot.Handle("/vacancies", handlers.HandleVacancies(ctx))
+	b.bot.Handle("/saved", handlers.HandleSaved(ctx))
+	b.bot.Handle("/applications", handlers.HandleApplications(ctx))
 	b.bot.Handle("/settings", handlers.HandleSettings(ctx))
+	b.bot.Handle("/stats", handlers.HandleAdminStats(ctx))
 
 	b.bot.Handle(tele.OnText, handlers.HandleText(ctx))
+	b.bot.Handle(tele.OnLocation, handlers.HandleLocation(ctx))
 
 	b.bot.Handle(tele.OnCallback, handlers.HandleCallback(ctx))
 
@@ -94,6 +119,7 @@ func (b *Bot) registerHandlers() {
 func (b *Bot) Start(ctx context.Context) error {
 	b.logger.Info("starting bot...")
 
+	go b.sender.Start(ctx)
 	go b.bot.Start()
 
 	<-ctx.Done()
@@ -111,4 +137,8 @@ func (b *Bot) Stop() {
 
 func (b *Bot) GetBot() *tele.Bot {
 	return b.bot
-}
\ No newline at end of file
+}
+
+func (b *Bot) GetSender() *sender.Queue {
+	return b.sender
+}
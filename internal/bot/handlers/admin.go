@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/utils"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// /stats, only for ADMIN_IDS
+func HandleAdminStats(ctx *Context) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		if !ctx.Config.IsAdmin(c.Sender().ID) {
+			return c.Send("⛔ Команда доступна только администраторам")
+		}
+
+		dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		stats, err := ctx.Store.GetBotStats(dbCtx)
+		if err != nil {
+			ctx.Logger.Error("failed to get bot stats", zap.Error(err))
+			return c.Send("😔 Ошибка при получении статистики")
+		}
+
+		return c.Send(utils.FormatBotStats(stats), tele.ModeMarkdownV2)
+	}
+}
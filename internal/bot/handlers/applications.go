@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/models"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// StateAwaitingApplicationNote is followed by the application id, e.g. "awaiting_application_note:42"
+const StateAwaitingApplicationNote = "awaiting_application_note:"
+
+const maxApplicationNoteLength = 500
+
+// /applications
+func HandleApplications(ctx *Context) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		text, keyboard, err := renderApplicationsPage(ctx, c.Sender().ID, 0)
+		if err != nil {
+			return c.Send("😔 Ошибка при получении откликов")
+		}
+
+		return c.Send(text, keyboard, tele.ModeMarkdownV2, tele.NoPreview)
+	}
+}
+
+// renderApplicationsPage builds a page of the board, applications grouped by status
+func renderApplicationsPage(ctx *Context, userID int64, page int) (string, *tele.ReplyMarkup, error) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	applications, err := ctx.Store.GetApplications(dbCtx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(applications) == 0 {
+		return "📨 *Откликов пока нет*\n\nНажмите «📨 Откликнулся» под вакансией, чтобы следить за ответом работодателя\\.", nil, nil
+	}
+
+	utils.SortApplications(applications)
+
+	totalPages := (len(applications) + utils.ApplicationsPageSize - 1) / utils.ApplicationsPageSize
+	if page >= totalPages {
+		page = totalPages - 1
+	}
+	offset := page * utils.ApplicationsPageSize
+
+	end := offset + utils.ApplicationsPageSize
+	if end > len(applications) {
+		end = len(applications)
+	}
+	ids := make([]string, 0, end-offset)
+	for i := offset; i < end; i++ {
+		ids = append(ids, applications[i].VacancyID)
+	}
+
+	cached, err := ctx.Store.GetCachedVacanciesByIDs(dbCtx, ids)
+	if err != nil {
+		return "", nil, err
+	}
+
+	vacancies := make(map[string]models.Vacancy, len(cached))
+	for _, v := range cached {
+		vacancies[v.ID] = v
+	}
+
+	loc, err := userLocation(dbCtx, ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	text := utils.FormatApplicationsBoard(applications, vacancies, offset, loc)
+	return text, utils.ApplicationsBoardKeyboard(applications, offset, page, totalPages), nil
+}
+
+// renderApplication builds the card of one application, nil text when it is gone
+func renderApplication(ctx *Context, userID, applicationID int64, page int) (string, *tele.ReplyMarkup, error) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	application, err := ctx.Store.GetApplication(dbCtx, userID, applicationID)
+	if err != nil || application == nil {
+		return "", nil, err
+	}
+
+	vacancy, err := ctx.Store.GetVacancy(dbCtx, application.VacancyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	loc, err := userLocation(dbCtx, ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return utils.FormatApplication(application, vacancy, loc), utils.ApplicationKeyboard(application, page), nil
+}
+
+func userLocation(dbCtx context.Context, ctx *Context, userID int64) (*time.Location, error) {
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return time.Local, nil
+	}
+	return user.Location(), nil
+}
+
+// applicationRemindAt is when to ask about an application that entered status, nil when no reply is awaited
+func applicationRemindAt(ctx *Context, status string) *time.Time {
+	if !(&models.Application{Status: status}).AwaitingReply() {
+		return nil
+	}
+	remindAt := time.Now().AddDate(0, 0, ctx.Config.ApplicationReminderDays)
+	return &remindAt
+}
+
+// handleApplyVacancy records an application from a vacancy card: apply_vacancy:<cache id>
+func handleApplyVacancy(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	// feed ids carry their own "source:" prefix
+	vacancyID := strings.Join(parts[1:], ":")
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ensureVacancyCached(dbCtx, ctx, vacancyID); err != nil {
+		return respondVacancyNotCached(ctx, c, vacancyID, err)
+	}
+
+	created, err := ctx.Store.CreateApplication(dbCtx, c.Sender().ID, vacancyID, *applicationRemindAt(ctx, models.ApplicationApplied))
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Не удалось отметить отклик"})
+	}
+
+	if !created {
+		return c.Respond(&tele.CallbackResponse{Text: "📨 Уже в откликах — /applications"})
+	}
+
+	return c.Respond(&tele.CallbackResponse{
+		Text: "📨 Отклик отмечен. Если ответа не будет " + utils.FormatDays(ctx.Config.ApplicationReminderDays) + ", я напомню — /applications",
+	})
+}
+
+func handleApplicationsPage(ctx *Context, c tele.Context, payloadParts []string) error {
+	if len(payloadParts) == 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	if payloadParts[0] == "noop" {
+		return c.Respond(&tele.CallbackResponse{Text: "📄 Уже на этой странице"})
+	}
+
+	if len(payloadParts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	page, err := strconv.Atoi(payloadParts[1])
+	if err != nil || page < 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверная страница"})
+	}
+
+	if err := editApplicationsPage(ctx, c, page); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	return c.Respond()
+}
+
+func editApplicationsPage(ctx *Context, c tele.Context, page int) error {
+	text, keyboard, err := renderApplicationsPage(ctx, c.Sender().ID, page)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Edit(text, keyboard, tele.ModeMarkdownV2, tele.NoPreview); err != nil {
+		ctx.Logger.Warn("failed to edit applications", zap.Error(err))
+	}
+
+	return nil
+}
+
+// editApplication redraws the card in place, or the board when the application is gone
+func editApplication(ctx *Context, c tele.Context, applicationID int64, page int) error {
+	text, keyboard, err := renderApplication(ctx, c.Sender().ID, applicationID, page)
+	if err != nil {
+		return err
+	}
+
+	if text == "" {
+		return editApplicationsPage(ctx, c, page)
+	}
+
+	if err := c.Edit(text, keyboard, tele.ModeMarkdownV2, tele.NoPreview); err != nil {
+		ctx.Logger.Warn("failed to edit application", zap.Error(err))
+	}
+
+	return nil
+}
+
+// parseApplicationCallback reads the application id and the board page from parts[1] and parts[pageAt]
+func parseApplicationCallback(parts []string, pageAt int) (int64, int, bool) {
+	if len(parts) <= pageAt {
+		return 0, 0, false
+	}
+
+	applicationID, errID := strconv.ParseInt(parts[1], 10, 64)
+	page, errPage := strconv.Atoi(parts[pageAt])
+	if errID != nil || errPage != nil || page < 0 {
+		return 0, 0, false
+	}
+
+	return applicationID, page, true
+}
+
+// showApplication opens an application from the board: app:<id>:<page>
+func showApplication(ctx *Context, c tele.Context, parts []string) error {
+	applicationID, page, ok := parseApplicationCallback(parts, 2)
+	if !ok {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	if err := editApplication(ctx, c, applicationID, page); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	return c.Respond()
+}
+
+// handleApplicationStatus moves an application on: app_status:<id>:<status>:<page>
+func handleApplicationStatus(ctx *Context, c tele.Context, parts []string) error {
+	applicationID, page, ok := parseApplicationCallback(parts, 3)
+	if !ok || !models.ValidApplicationStatus(parts[2]) {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+	status := parts[2]
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctx.Store.SetApplicationStatus(dbCtx, c.Sender().ID, applicationID, status, applicationRemindAt(ctx, status)); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Не удалось сменить статус"})
+	}
+
+	if err := editApplication(ctx, c, applicationID, page); err != nil {
+		ctx.Logger.Warn("failed to redraw application", zap.Error(err))
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "Статус: " + utils.ApplicationStatusLabel(status)})
+}
+
+// handleApplicationReminder turns the follow-up reminder on or off: app_remind:<id>:on|off:<page>
+func handleApplicationReminder(ctx *Context, c tele.Context, parts []string) error {
+	applicationID, page, ok := parseApplicationCallback(parts, 3)
+	if !ok {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	var remindAt *time.Time
+	confirmation := "🔕 Напоминание выключено"
+	if parts[2] == "on" {
+		remindAt = applicationRemindAt(ctx, models.ApplicationApplied)
+		confirmation = "⏰ Напомню через " + utils.FormatDays(ctx.Config.ApplicationReminderDays)
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctx.Store.SetApplicationReminder(dbCtx, c.Sender().ID, applicationID, remindAt); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	if err := editApplication(ctx, c, applicationID, page); err != nil {
+		ctx.Logger.Warn("failed to redraw application", zap.Error(err))
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: confirmation})
+}
+
+// handleApplicationDelete forgets an application and returns to the board: app_del:<id>:<page>
+func handleApplicationDelete(ctx *Context, c tele.Context, parts []string) error {
+	applicationID, page, ok := parseApplicationCallback(parts, 2)
+	if !ok {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctx.Store.DeleteApplication(dbCtx, c.Sender().ID, applicationID); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Не удалось удалить"})
+	}
+
+	if err := editApplicationsPage(ctx, c, page); err != nil {
+		ctx.Logger.Warn("failed to redraw applications", zap.Error(err))
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "🗑 Удалено"})
+}
+
+// startApplicationNote asks for the note of an application: app_note:<id>
+func startApplicationNote(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	if _, err := strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	if err := setUserState(ctx, c.Sender().ID, StateAwaitingApplicationNote+parts[1]); err != nil {
+		ctx.Logger.Error("failed to set user state", zap.Error(err))
+	}
+
+	if err := c.Send(
+		"📝 Введите заметку к отклику, например: «рекрутер Анна, созвон в четверг».\n\nОтправьте «-», чтобы удалить заметку.",
+		utils.CancelKeyboard(),
+	); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+func handleApplicationNoteInput(ctx *Context, c tele.Context, rawID string) error {
+	text := strings.TrimSpace(c.Text())
+	userID := c.Sender().ID
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	if text == "" || text == "❌ Отмена" {
+		return c.Send("❌ Операция отменена", utils.MainMenuKeyboard())
+	}
+
+	applicationID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return c.Send("😔 Заметка не сохранена, попробуйте ещё раз из /applications", utils.MainMenuKeyboard())
+	}
+
+	var note *string
+	if text != "-" {
+		text = utils.TruncateString(text, maxApplicationNoteLength)
+		note = &text
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctx.Store.SetApplicationNote(dbCtx, userID, applicationID, note); err != nil {
+		return c.Send("😔 Ошибка при сохранении заметки", utils.MainMenuKeyboard())
+	}
+
+	confirmation := "✅ Заметка сохранена"
+	if note == nil {
+		confirmation = "🗑 Заметка удалена"
+	}
+	if err := c.Send(confirmation, utils.MainMenuKeyboard()); err != nil {
+		return err
+	}
+
+	text, keyboard, err := renderApplication(ctx, userID, applicationID, 0)
+	if err != nil || text == "" {
+		return HandleApplications(ctx)(c)
+	}
+
+	return c.Send(text, keyboard, tele.ModeMarkdownV2, tele.NoPreview)
+}
@@ -2,14 +2,18 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"hh-vacancy-bot/internal/bot/middleware"
+	"hh-vacancy-bot/internal/bot/query"
+	"hh-vacancy-bot/internal/bot/sender"
 	"hh-vacancy-bot/internal/bot/utils"
 	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
 
 	"go.uber.org/zap"
 	tele "gopkg.in/telebot.v3"
@@ -89,6 +93,100 @@ func HandleCallback(ctx *Context) tele.HandlerFunc {
 			return handleConfirmNo(ctx, c)
 		case "choose_area":
 			return handleChooseArea(ctx, c, uniqueParts)
+		case "toggle_experience":
+			return handleToggleFilterValue(ctx, c, models.FilterTypeExperience, uniqueParts)
+		case "toggle_schedule":
+			return handleToggleFilterValue(ctx, c, models.FilterTypeSchedule, uniqueParts)
+		case "toggle_employment":
+			return handleToggleFilterValue(ctx, c, models.FilterTypeEmployment, uniqueParts)
+		case "toggle_search_field":
+			return handleToggleFilterValue(ctx, c, models.FilterTypeSearchField, uniqueParts)
+		case "toggle_label":
+			return handleToggleFilterValue(ctx, c, models.FilterTypeLabel, uniqueParts)
+		case "toggle_only_with_salary":
+			return handleToggleOnlyWithSalary(ctx, c)
+		case "set_filter":
+			return handleSetFilter(ctx, c, uniqueParts)
+		case "refine":
+			return handleRefine(ctx, c)
+		case "refine_add":
+			return handleRefineAdd(ctx, c, uniqueParts)
+		case "more_filter":
+			return handleMoreFilter(ctx, c, uniqueParts)
+		case "toggle_area":
+			return handleToggleFilterValue(ctx, c, models.FilterTypeArea, uniqueParts)
+		case "area_add":
+			return handleAreaAdd(ctx, c)
+		case "filter_done":
+			return handleFilterDone(ctx, c, uniqueParts)
+		case "exclude_employer":
+			return handleExcludeEmployer(ctx, c, uniqueParts)
+		case "exclusions_words":
+			return handleExclusionsWords(ctx, c)
+		case "exclusions_reset_employers":
+			return handleExclusionsResetEmployers(ctx, c)
+		case "search_select":
+			return handleSearchSelect(ctx, c, uniqueParts)
+		case "search_delete":
+			return handleSearchDelete(ctx, c, uniqueParts)
+		case "search_new":
+			return startNewSearch(ctx, c)
+		case "search_rename":
+			return startRenameSearch(ctx, c)
+		case "search_import":
+			return startSearchImport(ctx, c)
+		case "feeds":
+			return handleFeeds(ctx, c)
+		case "feed_add":
+			return startFeedAdd(ctx, c)
+		case "feed_delete":
+			return handleFeedDelete(ctx, c, uniqueParts)
+		case "tz":
+			return handleTimezoneSelect(ctx, c, uniqueParts)
+		case "quiet":
+			return handleQuietHoursSelect(ctx, c, uniqueParts)
+		case "quiet_custom":
+			return startQuietHoursInput(ctx, c)
+		case "quiet_off":
+			return handleQuietHoursOff(ctx, c)
+		case "save_vacancy":
+			return handleSaveVacancy(ctx, c, uniqueParts)
+		case "saved_page":
+			return handleSavedPage(ctx, c, payloadParts)
+		case "saved_del":
+			return handleSavedDelete(ctx, c, uniqueParts)
+		case "saved_note":
+			return startSavedNote(ctx, c, uniqueParts)
+		case "apply_vacancy":
+			return handleApplyVacancy(ctx, c, uniqueParts)
+		case "apps_page":
+			return handleApplicationsPage(ctx, c, payloadParts)
+		case "app":
+			return showApplication(ctx, c, uniqueParts)
+		case "app_status":
+			return handleApplicationStatus(ctx, c, uniqueParts)
+		case "app_remind":
+			return handleApplicationReminder(ctx, c, uniqueParts)
+		case "app_note":
+			return startApplicationNote(ctx, c, uniqueParts)
+		case "app_del":
+			return handleApplicationDelete(ctx, c, uniqueParts)
+		case "check_schedule":
+			return handleCheckScheduleSelect(ctx, c, uniqueParts)
+		case "check_schedule_custom":
+			return startCheckScheduleInput(ctx, c)
+		case "check_schedule_off":
+			return handleCheckScheduleOff(ctx, c)
+		case "delivery":
+			return handleDeliveryMode(ctx, c, uniqueParts)
+		case "digest_day":
+			return handleDigestDay(ctx, c, uniqueParts)
+		case "digest_at":
+			return handleDigestAt(ctx, c, uniqueParts)
+		case "digest_group":
+			return handleDigestGroup(ctx, c, uniqueParts)
+		case "digest_page":
+			return handleDigestPage(ctx, c, uniqueParts, payloadParts)
 		default:
 			ctx.Logger.Warn("unknown callback action",
 				zap.String("action", action),
@@ -114,7 +212,13 @@ func handleFilterDelete(ctx *Context, c tele.Context, parts []string) error {
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := ctx.Store.DeleteFilter(dbCtx, userID, filterType); err != nil {
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка удаления"})
+	}
+
+	if err := ctx.Store.DeleteFilter(dbCtx, search.ID, filterType); err != nil {
 		ctx.Logger.Error("failed to delete filter", zap.Error(err))
 		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка удаления"})
 	}
@@ -211,7 +315,13 @@ func handleVacancyPage(ctx *Context, c tele.Context, parts []string) error {
 		dbCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
 
-		filtersMap, err := ctx.Store.GetFiltersMap(dbCtx, userID)
+		search, err := getActiveSearch(dbCtx, ctx, userID)
+		if err != nil {
+			ctx.Logger.Error("failed to get active search for pagination", zap.Error(err))
+			return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка фильтров"})
+		}
+
+		filtersMap, err := ctx.Store.GetSearchFiltersMap(dbCtx, search.ID)
 		if err != nil {
 			ctx.Logger.Error("failed to load filters for pagination", zap.Error(err))
 			return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка фильтров"})
@@ -220,23 +330,23 @@ func handleVacancyPage(ctx *Context, c tele.Context, parts []string) error {
 			return c.Respond(&tele.CallbackResponse{Text: "ℹ️ Настройте фильтры через /filters"})
 		}
 
-		if err := middleware.CheckHHAPIRateLimit(ctx.Cache, ctx.Logger); err != nil {
+		if err := ctx.HHLimiter.Allow(middleware.HHBudgetInteractive); err != nil {
 			ctx.Logger.Warn("HH API rate limit (pagination)", zap.Error(err))
 			return c.Respond(&tele.CallbackResponse{Text: "⚠️ Попробуйте позже"})
 		}
 
-		params := buildSearchParams(filtersMap)
-		if ctx.Config.MaxVacanciesPerCheck > 0 {
-			params.PerPage = ctx.Config.MaxVacanciesPerCheck
-		}
-		params.Page = targetPage
-
-		response, err := ctx.HHClient.SearchVacancies(dbCtx, params)
+		response, err := searchSource(dbCtx, ctx, search.ID).Search(dbCtx, source.Query{
+			Filters: filtersMap,
+			Page:    targetPage,
+			PerPage: ctx.Config.MaxVacanciesPerCheck,
+		})
 		if err != nil {
 			ctx.Logger.Error("failed to fetch vacancy page", zap.Error(err))
-			return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка запроса"})
+			return respondSearchError(c, err)
 		}
 
+		response.Items = query.ApplyLocalFilters(response.Items, filtersMap)
+
 		totalPages := response.Pages
 		if totalPages == 0 {
 			totalPages = 1
@@ -247,17 +357,18 @@ func handleVacancyPage(ctx *Context, c tele.Context, parts []string) error {
 		}
 
 		indicator := fmt.Sprintf("📄 Страница %d из %d", targetPage+1, totalPages)
-		if params.PublishedWithinDays > 0 {
-			indicator = fmt.Sprintf("%s • за %s", indicator, utils.FormatDays(params.PublishedWithinDays))
+		if response.PublishedWithinDays > 0 {
+			indicator = fmt.Sprintf("%s • за %s", indicator, utils.FormatDays(response.PublishedWithinDays))
 		}
 
-		if err := c.Edit(indicator, utils.InlinePaginationKeyboard(targetPage, totalPages, "vacancy_page")); err != nil {
+		if err := c.Edit(indicator, utils.VacancyNavigationKeyboard(targetPage, totalPages)); err != nil {
 			ctx.Logger.Warn("failed to edit pagination message", zap.Error(err))
 		}
 
 		cleanupPaginationMessages(ctx, c, userID)
 
-		go cacheVacancies(ctx, response.Items)
+		// cards offer save and apply buttons, which reference the cache row
+		cacheVacancies(ctx, response.Items)
 
 		if len(response.Items) == 0 {
 			if err := c.Send("🤷 На этой странице вакансий нет"); err != nil {
@@ -269,18 +380,22 @@ func handleVacancyPage(ctx *Context, c tele.Context, parts []string) error {
 		var messageIDs []int
 
 		header := fmt.Sprintf("📄 *Вакансии — страница %d/%d*", targetPage+1, totalPages)
-		headerMsg, err := c.Bot().Send(
+		sendCtx, cancelSend := context.WithTimeout(context.Background(), 30*time.Second)
+		headerMsg, err := ctx.Sender.Send(
+			sendCtx,
+			sender.Interactive,
 			c.Chat(),
 			header,
 			&tele.SendOptions{ParseMode: tele.ModeMarkdownV2},
 		)
+		cancelSend()
 		if err != nil {
 			ctx.Logger.Error("failed to send pagination header", zap.Error(err))
 		} else {
 			messageIDs = append(messageIDs, headerMsg.ID)
 		}
 
-		cardMessageIDs, err := deliverVacancyCards(ctx, c, response.Items, userID)
+		cardMessageIDs, delivered, err := deliverVacancyCards(ctx, c, response.Items, userID, search.ID)
 		if err != nil {
 			ctx.Logger.Error("failed to send vacancies page", zap.Error(err))
 			return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка отправки"})
@@ -290,7 +405,9 @@ func handleVacancyPage(ctx *Context, c tele.Context, parts []string) error {
 
 		rememberPaginationMessages(ctx, userID, messageIDs)
 
-		go markVacanciesAsSeen(ctx, userID, response.Items)
+		if len(delivered) > 0 {
+			go markVacanciesAsSeen(ctx, userID, delivered)
+		}
 
 		return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("📄 Стр. %d", targetPage+1)})
 	default:
@@ -340,52 +457,129 @@ func handleChooseArea(ctx *Context, c tele.Context, parts []string) error {
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Save the selected area as a filter
-	filter := &models.UserFilter{
-		UserID:      userID,
-		FilterType:  models.FilterTypeArea,
-		FilterValue: areaID,
-	}
-
-	if err := ctx.Store.SaveFilter(dbCtx, filter); err != nil {
+	// Add the selected area to the search's set
+	if _, err := addAreaToSearch(dbCtx, ctx, userID, areaID); errors.Is(err, errTooManyAreas) {
+		return c.Respond(&tele.CallbackResponse{
+			Text: fmt.Sprintf("⚠️ Не больше %d городов", models.MaxAreasPerSearch),
+		})
+	} else if err != nil {
 		ctx.Logger.Error("failed to save city filter", zap.Error(err))
 		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка при сохранении"})
 	}
 
-	// Get area name for display
-	areaName := "Город выбран"
-	if area, err := ctx.HHClient.GetArea(dbCtx, areaID); err == nil && area != nil {
-		areaName = area.Name
-		ctx.Logger.Info("area name resolved", zap.String("name", areaName))
-	} else {
-		ctx.Logger.Warn("failed to get area name", zap.Error(err))
-	}
-
 	// Clear conversation state
 	if err := clearUserState(ctx, userID); err != nil {
 		ctx.Logger.Warn("failed to clear state", zap.Error(err))
 	}
 
-	// Update the message with the result
-	editErr := c.Edit(
-		fmt.Sprintf("✅ Город установлен: *%s*", utils.EscapeMarkdown(areaName)),
-		utils.FiltersMenuKeyboard(),
-		tele.ModeMarkdownV2,
-	)
-
-	if editErr != nil {
-		ctx.Logger.Warn("failed to edit message", zap.Error(editErr))
+	if err := sendAreaSelection(ctx, c, dbCtx, userID, true); err != nil {
+		ctx.Logger.Warn("failed to edit message", zap.Error(err))
 		// Fallback: send new message if edit fails
-		return c.Send(
-			fmt.Sprintf("✅ Город установлен: *%s*", utils.EscapeMarkdown(areaName)),
-			utils.FiltersMenuKeyboard(),
-			tele.ModeMarkdownV2,
-		)
+		return sendAreaSelection(ctx, c, dbCtx, userID, false)
 	}
 
 	return c.Respond(&tele.CallbackResponse{Text: "✅ Выбрано"})
 }
 
+// ==================== Multi-value Filters ====================
+
+func handleToggleFilterValue(ctx *Context, c tele.Context, filterType string, parts []string) error {
+	if len(parts) < 2 || parts[1] == "" {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	values, err := toggleSearchFilterValue(dbCtx, ctx, userID, filterType, parts[1])
+	if err != nil {
+		ctx.Logger.Error("failed to toggle filter value",
+			zap.String("filter_type", filterType),
+			zap.Error(err),
+		)
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка при сохранении"})
+	}
+
+	switch filterType {
+	case models.FilterTypeArea:
+		if err := sendAreaSelection(ctx, c, dbCtx, userID, true); err != nil {
+			ctx.Logger.Warn("failed to edit area selection", zap.Error(err))
+		}
+	case models.FilterTypeExperience:
+		if _, err := queuedCall(ctx, c, func(bot *tele.Bot) (*tele.Message, error) {
+			return bot.EditReplyMarkup(c.Message(), utils.ExperienceKeyboard(values))
+		}); err != nil {
+			ctx.Logger.Warn("failed to edit experience keyboard", zap.Error(err))
+		}
+	case models.FilterTypeSchedule:
+		if _, err := queuedCall(ctx, c, func(bot *tele.Bot) (*tele.Message, error) {
+			return bot.EditReplyMarkup(c.Message(), utils.ScheduleKeyboard(values))
+		}); err != nil {
+			ctx.Logger.Warn("failed to edit schedule keyboard", zap.Error(err))
+		}
+	default:
+		if _, err := queuedCall(ctx, c, func(bot *tele.Bot) (*tele.Message, error) {
+			return bot.EditReplyMarkup(c.Message(), utils.ChecklistKeyboard(filterType, values))
+		}); err != nil {
+			ctx.Logger.Warn("failed to edit checklist keyboard", zap.Error(err))
+		}
+	}
+
+	return c.Respond()
+}
+
+func handleAreaAdd(ctx *Context, c tele.Context) error {
+	if err := startCityFilter(ctx, c); err != nil {
+		return err
+	}
+	return c.Respond()
+}
+
+func handleFilterDone(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	filterType := parts[1]
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	values, err := getSearchFilterValues(dbCtx, ctx, userID, filterType)
+	if err != nil {
+		ctx.Logger.Error("failed to get filter values", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	displayName := getFilterDisplayName(filterType)
+
+	var message string
+	switch {
+	case len(values) == 0:
+		message = fmt.Sprintf("✅ Фильтр *%s* не задан", utils.EscapeMarkdown(displayName))
+	case filterType == models.FilterTypeArea:
+		message = fmt.Sprintf("✅ Выбрано городов: *%d*", len(values))
+	default:
+		message = fmt.Sprintf("✅ Фильтр *%s* сохранён: %s",
+			utils.EscapeMarkdown(displayName),
+			utils.EscapeMarkdown(utils.FormatFilterValue(filterType, models.JoinFilterValues(values))),
+		)
+	}
+
+	if err := c.Delete(); err != nil {
+		ctx.Logger.Warn("failed to delete selection message", zap.Error(err))
+	}
+
+	if err := c.Send(message, utils.FiltersMenuKeyboard(), tele.ModeMarkdownV2); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
 // ==================== Helpers ====================
 
 func getFilterDisplayName(filterType string) string {
@@ -400,6 +594,32 @@ func getFilterDisplayName(filterType string) string {
 		return "Опыт"
 	case "schedule":
 		return "График"
+	case models.FilterTypeEmployment:
+		return "Занятость"
+	case models.FilterTypeProfessionalRole:
+		return "Роли"
+	case models.FilterTypeIndustry:
+		return "Отрасли"
+	case models.FilterTypeEmployer:
+		return "Работодатели"
+	case models.FilterTypeSearchField:
+		return "Где искать"
+	case models.FilterTypeOrderBy:
+		return "Сортировка"
+	case models.FilterTypeLabel:
+		return "Метки"
+	case models.FilterTypeMetro:
+		return "Метро"
+	case models.FilterTypeCurrency:
+		return "Валюта"
+	case models.FilterTypeOnlyWithSalary:
+		return "Только с зарплатой"
+	case models.FilterTypeSalaryTo:
+		return "Зарплата до"
+	case models.FilterTypeExcludedEmployers:
+		return "Скрытые работодатели"
+	case models.FilterTypeExcludedWords:
+		return "Стоп-слова"
 	default:
 		return filterType
 	}
@@ -411,7 +631,12 @@ func InlineFiltersKeyboard(ctx *Context, userID int64) (*tele.ReplyMarkup, error
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filters, err := ctx.Store.GetUserFilters(dbCtx, userID)
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filters, err := ctx.Store.GetSearchFilters(dbCtx, search.ID)
 	if err != nil {
 		return nil, err
 	}
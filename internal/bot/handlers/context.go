@@ -1,19 +1,44 @@
 package handlers
 
 import (
+	"context"
+	"time"
+
 	"hh-vacancy-bot/internal/api/headhunter"
+	"hh-vacancy-bot/internal/bot/middleware"
+	"hh-vacancy-bot/internal/bot/sender"
 	"hh-vacancy-bot/internal/config"
+	"hh-vacancy-bot/internal/source"
 	"hh-vacancy-bot/internal/storage/postgres"
 	"hh-vacancy-bot/internal/storage/redis"
 
 	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
 )
 
 // Context contains deps for all handlers
 type Context struct {
-	Store    *postgres.Store
-	Cache    *redis.Cache
-	HHClient *headhunter.Client
-	Config   *config.Config
-	Logger   *zap.Logger
-}
\ No newline at end of file
+	Store     *postgres.Store
+	Cache     *redis.Cache
+	HHClient  *headhunter.Client
+	Sources   *source.Registry
+	HHLimiter *middleware.HHAPILimiter
+	Sender    *sender.Queue // every message goes through it, middleware.SendQueue routes the tele.Context ones
+	Config    *config.Config
+	Logger    *zap.Logger
+}
+
+// queuedCall sends a Bot API call tele.Context has no method for, e.g. editing another message, through the send queue
+func queuedCall(ctx *Context, c tele.Context, call sender.Call) (*tele.Message, error) {
+	sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return ctx.Sender.Do(sendCtx, sender.Interactive, c.Recipient(), call)
+}
+
+func queuedDelete(ctx *Context, msg *tele.Message) error {
+	sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return ctx.Sender.Delete(sendCtx, sender.Interactive, msg)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/models"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+func showDelivery(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err != nil || user == nil {
+		ctx.Logger.Error("failed to get user", zap.Int64("user_id", userID), zap.Error(err))
+		return c.Send("😔 Ошибка при получении данных")
+	}
+
+	return c.Send(
+		"📬 Как присылать новые вакансии?\n\n"+
+			"Сразу — отдельная карточка на каждую вакансию.\n"+
+			"Дайджест — одно сообщение в выбранное время со всеми вакансиями, "+
+			"сгруппированными по компаниям или городам.\n\n"+
+			"Сейчас: "+utils.FormatDeliveryMode(user),
+		utils.DeliveryKeyboard(user),
+	)
+}
+
+func handleDeliveryMode(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	switch parts[1] {
+	case models.DeliveryInstant:
+		if err := saveDeliveryMode(ctx, c, models.DeliveryInstant, -1, -1); err != nil {
+			return err
+		}
+		return c.Respond(&tele.CallbackResponse{Text: "✅ Сохранено"})
+	case models.DeliveryDaily:
+		if err := c.Edit("📅 Во сколько присылать дайджест?", utils.DigestHourKeyboard(models.DeliveryDaily, 0)); err != nil {
+			ctx.Logger.Warn("failed to edit message", zap.Error(err))
+		}
+		return c.Respond()
+	case models.DeliveryWeekly:
+		if err := c.Edit("🗓 В какой день недели присылать дайджест?", utils.WeekdayKeyboard()); err != nil {
+			ctx.Logger.Warn("failed to edit message", zap.Error(err))
+		}
+		return c.Respond()
+	default:
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неизвестный режим"})
+	}
+}
+
+func handleDigestDay(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	weekday, err := strconv.Atoi(parts[1])
+	if err != nil || weekday < 0 || weekday > 6 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный день"})
+	}
+
+	if err := c.Edit("🗓 Во сколько присылать дайджест?", utils.DigestHourKeyboard(models.DeliveryWeekly, weekday)); err != nil {
+		ctx.Logger.Warn("failed to edit message", zap.Error(err))
+	}
+
+	return c.Respond()
+}
+
+// handleDigestAt saves the digest schedule: digest_at:<mode>:<weekday>:<hour>
+func handleDigestAt(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 4 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	mode := parts[1]
+	weekday, errDay := strconv.Atoi(parts[2])
+	hour, errHour := strconv.Atoi(parts[3])
+	if (mode != models.DeliveryDaily && mode != models.DeliveryWeekly) ||
+		errDay != nil || weekday < 0 || weekday > 6 ||
+		errHour != nil || hour < 0 || hour > 23 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверное время"})
+	}
+
+	if err := saveDeliveryMode(ctx, c, mode, hour, weekday); err != nil {
+		return err
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "✅ Сохранено"})
+}
+
+// saveDeliveryMode stores the mode; negative hour and weekday keep the current ones
+func saveDeliveryMode(ctx *Context, c tele.Context, mode string, hour, weekday int) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err != nil || user == nil {
+		ctx.Logger.Error("failed to get user", zap.Int64("user_id", userID), zap.Error(err))
+		return c.Send("😔 Ошибка при получении данных")
+	}
+
+	if hour < 0 {
+		hour = user.DigestHour
+	}
+	if weekday < 0 {
+		weekday = user.DigestWeekday
+	}
+
+	if err := ctx.Store.SetDeliveryMode(dbCtx, userID, mode, hour, weekday); err != nil {
+		return c.Send("😔 Ошибка при сохранении настроек доставки")
+	}
+
+	user.DeliveryMode, user.DigestHour, user.DigestWeekday = mode, hour, weekday
+	if err := c.Edit("📬 Доставка: "+utils.FormatDeliveryMode(user), utils.DeliveryKeyboard(user)); err != nil {
+		ctx.Logger.Warn("failed to edit message", zap.Error(err))
+	}
+
+	return nil
+}
+
+func handleDigestGroup(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 || (parts[1] != models.DigestGroupEmployer && parts[1] != models.DigestGroupCity) {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctx.Store.SetDigestGroup(dbCtx, userID, parts[1]); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка сохранения"})
+	}
+
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err == nil && user != nil {
+		if err := c.Edit("📬 Доставка: "+utils.FormatDeliveryMode(user), utils.DeliveryKeyboard(user)); err != nil {
+			ctx.Logger.Warn("failed to edit message", zap.Error(err))
+		}
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "✅ Сохранено"})
+}
+
+// handleDigestPage pages through a sent digest: digest_page:<digest id> with goto:<page> payload
+func handleDigestPage(ctx *Context, c tele.Context, uniqueParts, payloadParts []string) error {
+	if len(uniqueParts) < 2 || len(payloadParts) == 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	if payloadParts[0] == "noop" {
+		return c.Respond(&tele.CallbackResponse{Text: "📄 Уже на этой странице"})
+	}
+
+	digestID, err := strconv.ParseInt(uniqueParts[1], 10, 64)
+	if err != nil || len(payloadParts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	page, err := strconv.Atoi(payloadParts[1])
+	if err != nil || page < 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверная страница"})
+	}
+
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err != nil || user == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	vacancies, err := ctx.Store.GetDigestVacancies(dbCtx, userID, digestID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+	if len(vacancies) == 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "🗑 Дайджест устарел"})
+	}
+
+	pages := utils.FormatDigestPages(vacancies, user.DigestGroup)
+	if page >= len(pages) {
+		page = len(pages) - 1
+	}
+
+	if err := c.Edit(
+		pages[page],
+		utils.DigestPaginationKeyboard(digestID, page, len(pages)),
+		tele.ModeMarkdownV2,
+		tele.NoPreview,
+	); err != nil {
+		ctx.Logger.Warn("failed to edit digest page",
+			zap.Int64("digest_id", digestID),
+			zap.Error(err),
+		)
+	}
+
+	return c.Respond()
+}
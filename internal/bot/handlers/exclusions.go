@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/models"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const maxExcludedWords = 30
+
+// ==================== Exclusions Menu ====================
+
+func showExclusions(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при получении фильтров")
+	}
+
+	filtersMap, err := ctx.Store.GetSearchFiltersMap(dbCtx, search.ID)
+	if err != nil {
+		ctx.Logger.Error("failed to get search filters", zap.Error(err))
+		return c.Send("😔 Ошибка при получении фильтров")
+	}
+
+	hidden := len(models.SplitFilterValues(filtersMap[models.FilterTypeExcludedEmployers]))
+
+	return c.Send(
+		utils.FormatExclusionsMessage(search.Name, filtersMap),
+		utils.InlineExclusionsKeyboard(hidden),
+		tele.ModeMarkdownV2,
+	)
+}
+
+// ==================== Stop-words ====================
+
+func handleExclusionsWords(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	if err := setUserState(ctx, userID, StateAwaitingExcludedWords); err != nil {
+		ctx.Logger.Error("failed to set user state", zap.Error(err))
+	}
+
+	if err := c.Send(
+		"✏️ Введите стоп-слова через запятую (например: стажёр, junior, колл-центр).\n"+
+			"Вакансии с ними в названии или описании не будут показываться.\n"+
+			"Отправьте «-», чтобы очистить список.",
+		utils.CancelKeyboard(),
+	); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+func handleExcludedWordsInput(ctx *Context, c tele.Context) error {
+	text := strings.TrimSpace(c.Text())
+	userID := c.Sender().ID
+
+	if text == "" || text == "❌ Отмена" {
+		return cancelConversation(ctx, c)
+	}
+
+	var words []string
+	if text != "-" {
+		for _, word := range models.SplitFilterValues(text) {
+			word = strings.Join(strings.Fields(word), " ")
+			if !models.ContainsFilterValue(words, word) {
+				words = append(words, word)
+			}
+		}
+	}
+
+	if len(words) > maxExcludedWords {
+		return c.Send(fmt.Sprintf("❌ Не больше %d стоп-слов", maxExcludedWords))
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := saveSearchFilterValues(dbCtx, ctx, userID, models.FilterTypeExcludedWords, words); err != nil {
+		ctx.Logger.Error("failed to save excluded words", zap.Error(err))
+		return c.Send("😔 Ошибка при сохранении фильтра")
+	}
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	if len(words) == 0 {
+		return c.Send("✅ Стоп-слова очищены", utils.FiltersMenuKeyboard())
+	}
+
+	return c.Send(
+		fmt.Sprintf("✅ Стоп-слова установлены: *%s*", utils.EscapeMarkdown(strings.Join(words, ", "))),
+		utils.FiltersMenuKeyboard(),
+		tele.ModeMarkdownV2,
+	)
+}
+
+// ==================== Employers ====================
+
+// handleExcludeEmployer hides an employer in the search the vacancy card came from
+func handleExcludeEmployer(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 3 || parts[2] == "" {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	searchID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	employerID := parts[2]
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	search, err := ctx.Store.GetSearch(dbCtx, searchID)
+	if err != nil || search == nil || search.UserID != userID {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Поиск не найден"})
+	}
+
+	filter, err := ctx.Store.GetFilter(dbCtx, search.ID, models.FilterTypeExcludedEmployers)
+	if err != nil {
+		ctx.Logger.Error("failed to get excluded employers", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	var employers []string
+	if filter != nil {
+		employers = models.SplitFilterValues(filter.FilterValue)
+	}
+
+	if !models.ContainsFilterValue(employers, employerID) {
+		employers = append(employers, employerID)
+
+		if err := ctx.Store.SaveFilter(dbCtx, &models.UserFilter{
+			UserID:      userID,
+			SearchID:    search.ID,
+			FilterType:  models.FilterTypeExcludedEmployers,
+			FilterValue: models.JoinFilterValues(employers),
+		}); err != nil {
+			ctx.Logger.Error("failed to save excluded employers", zap.Error(err))
+			return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка при сохранении"})
+		}
+	}
+
+	ctx.Logger.Info("employer excluded",
+		zap.Int64("user_id", userID),
+		zap.Int64("search_id", search.ID),
+		zap.String("employer_id", employerID),
+	)
+
+	return c.Respond(&tele.CallbackResponse{
+		Text:      fmt.Sprintf("🚫 Вакансии этого работодателя больше не попадут в поиск «%s»", search.Name),
+		ShowAlert: true,
+	})
+}
+
+func handleExclusionsResetEmployers(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := saveSearchFilterValues(dbCtx, ctx, userID, models.FilterTypeExcludedEmployers, nil); err != nil {
+		ctx.Logger.Error("failed to reset excluded employers", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	if _, err := queuedCall(ctx, c, func(bot *tele.Bot) (*tele.Message, error) {
+		return bot.EditReplyMarkup(c.Message(), utils.InlineExclusionsKeyboard(0))
+	}); err != nil {
+		ctx.Logger.Warn("failed to edit exclusions keyboard", zap.Error(err))
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "♻️ Работодатели возвращены"})
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
+	"hh-vacancy-bot/internal/source/rss"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+func handleFeeds(ctx *Context, c tele.Context) error {
+	if err := showSearchFeeds(ctx, c); err != nil {
+		return err
+	}
+	return c.Respond()
+}
+
+func showSearchFeeds(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при получении лент")
+	}
+
+	feeds, err := ctx.Store.GetSearchFeeds(dbCtx, search.ID)
+	if err != nil {
+		ctx.Logger.Error("failed to get search feeds", zap.Error(err))
+		return c.Send("😔 Ошибка при получении лент")
+	}
+
+	return c.Send(formatFeedsMessage(search.Name, feeds), utils.FeedsKeyboard(feeds))
+}
+
+func formatFeedsMessage(searchName string, feeds []models.SearchFeed) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("📡 RSS-ленты поиска «%s»\n\n", searchName))
+	sb.WriteString("Вакансии из RSS/Atom-лент приходят вместе с hh.ru. ")
+	sb.WriteString("Стоп-слова и период публикации применяются и к ним.\n\n")
+
+	if len(feeds) == 0 {
+		sb.WriteString("Лент пока нет.")
+		return sb.String()
+	}
+
+	for i, feed := range feeds {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, feed.URL))
+	}
+	sb.WriteString("\nНажмите на ленту, чтобы удалить её.")
+
+	return sb.String()
+}
+
+func startFeedAdd(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	if err := setUserState(ctx, userID, StateAwaitingFeedURL); err != nil {
+		ctx.Logger.Error("failed to set user state", zap.Error(err))
+	}
+
+	if err := c.Send(
+		"📡 Отправьте ссылку на RSS или Atom ленту с вакансиями (http:// или https://).",
+		utils.CancelKeyboard(),
+	); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+func handleFeedURLInput(ctx *Context, c tele.Context) error {
+	text := strings.TrimSpace(c.Text())
+
+	if text == "" || text == "❌ Отмена" {
+		return cancelConversation(ctx, c)
+	}
+
+	feedURL, ok := parseFeedURL(text)
+	if !ok {
+		return c.Send("❌ Нужна ссылка вида https://example.com/jobs.rss")
+	}
+
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при добавлении ленты")
+	}
+
+	feeds, err := ctx.Store.GetSearchFeeds(dbCtx, search.ID)
+	if err != nil {
+		ctx.Logger.Error("failed to get search feeds", zap.Error(err))
+		return c.Send("😔 Ошибка при добавлении ленты")
+	}
+
+	if len(feeds) >= models.MaxFeedsPerSearch {
+		_ = clearUserState(ctx, userID)
+		return c.Send(
+			fmt.Sprintf("⚠️ Можно добавить не больше %d лент", models.MaxFeedsPerSearch),
+			utils.FiltersMenuKeyboard(),
+		)
+	}
+
+	if err := rss.CheckFeedURL(dbCtx, feedURL); err != nil {
+		ctx.Logger.Info("feed rejected",
+			zap.Int64("user_id", userID),
+			zap.String("url", feedURL),
+			zap.Error(err),
+		)
+		if errors.Is(err, rss.ErrForbiddenAddress) {
+			return c.Send("❌ Ленты с локальных и внутренних адресов не поддерживаются")
+		}
+		return c.Send("❌ Не удалось найти сайт ленты. Проверьте ссылку и отправьте её ещё раз.")
+	}
+
+	// read the feed once so broken links are rejected up front
+	if feed := ctx.Sources.Feed(feedURL); feed != nil {
+		if _, err := feed.Search(dbCtx, source.Query{}); err != nil {
+			ctx.Logger.Info("feed rejected",
+				zap.Int64("user_id", userID),
+				zap.String("url", feedURL),
+				zap.Error(err),
+			)
+			return c.Send("❌ Не удалось прочитать ленту. Проверьте, что это RSS или Atom, и отправьте ссылку ещё раз.")
+		}
+	}
+
+	if err := ctx.Store.AddSearchFeed(dbCtx, search.ID, feedURL); err != nil {
+		ctx.Logger.Error("failed to add search feed", zap.Error(err))
+		return c.Send("😔 Ошибка при добавлении ленты")
+	}
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	if err := c.Send("✅ Лента добавлена", utils.FiltersMenuKeyboard()); err != nil {
+		return err
+	}
+
+	return showSearchFeeds(ctx, c)
+}
+
+func handleFeedDelete(ctx *Context, c tele.Context, parts []string) error {
+	feedID, ok := parseSearchID(parts)
+	if !ok {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	if err := ctx.Store.DeleteSearchFeed(dbCtx, search.ID, feedID); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка удаления"})
+	}
+
+	feeds, err := ctx.Store.GetSearchFeeds(dbCtx, search.ID)
+	if err != nil {
+		ctx.Logger.Error("failed to get search feeds", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "✅ Лента удалена"})
+	}
+
+	if err := c.Edit(formatFeedsMessage(search.Name, feeds), utils.FeedsKeyboard(feeds)); err != nil {
+		ctx.Logger.Warn("failed to edit feeds message", zap.Error(err))
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "✅ Лента удалена"})
+}
+
+func parseFeedURL(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+
+	return u.String(), true
+}
@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"hh-vacancy-bot/internal/api/headhunter"
 	"hh-vacancy-bot/internal/bot/utils"
 	"hh-vacancy-bot/internal/models"
 
@@ -21,10 +23,19 @@ const (
 	StateAwaitingText     = "awaiting_text"
 	StateAwaitingCity     = "awaiting_city"
 	StateAwaitingSalary   = "awaiting_salary"
-	StateAwaitingExp      = "awaiting_experience"
-	StateAwaitingSchedule = "awaiting_schedule"
 	StateAwaitingPeriod   = "awaiting_period"
+	StateAwaitingSalaryTo = "awaiting_salary_to"
 	StateConfirmClear     = "confirm_clear_filters"
+
+	StateAwaitingExcludedWords = "awaiting_excluded_words"
+
+	StateAwaitingSearchName   = "awaiting_search_name"
+	StateAwaitingSearchRename = "awaiting_search_rename"
+	StateAwaitingSearchURL    = "awaiting_search_url"
+	StateAwaitingFeedURL      = "awaiting_feed_url"
+
+	StateAwaitingQuietHours    = "awaiting_quiet_hours"
+	StateAwaitingCheckSchedule = "awaiting_check_schedule"
 )
 
 // /filters command
@@ -37,13 +48,23 @@ func HandleFilters(ctx *Context) tele.HandlerFunc {
 			ctx.Logger.Warn("failed to clear user state", zap.Error(err))
 		}
 
+		dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		search, err := getActiveSearch(dbCtx, ctx, userID)
+		if err != nil {
+			ctx.Logger.Error("failed to get active search", zap.Error(err))
+			return c.Send("😔 Ошибка при получении фильтров")
+		}
+
 		message := "🔧 *Настройка фильтров*\n\n"
-		message += "Выберите параметр для настройки:"
+		message += fmt.Sprintf("Поиск: *%s*\n\n", utils.EscapeMarkdown(search.Name))
+		message += "Выберите параметр для настройки или переключите поиск в «🗂 Поиски»:"
 
 		return c.Send(
 			message,
 			utils.FiltersMenuKeyboard(),
-			tele.ModeMarkdown,
+			tele.ModeMarkdownV2,
 		)
 	}
 }
@@ -73,6 +94,10 @@ func HandleText(ctx *Context) tele.HandlerFunc {
 			return HandleFilters(ctx)(c)
 		case "📋 Вакансии":
 			return HandleVacancies(ctx)(c)
+		case "⭐ Сохранённые":
+			return HandleSaved(ctx)(c)
+		case "📨 Отклики":
+			return HandleApplications(ctx)(c)
 		case "⚙️ Настройки":
 			return HandleSettings(ctx)(c)
 		case "❓ Справка":
@@ -91,6 +116,14 @@ func HandleText(ctx *Context) tele.HandlerFunc {
 			return startScheduleFilter(ctx, c)
 		case "🗓 Период":
 			return startPeriodFilter(ctx, c)
+		case "🗂 Поиски":
+			return showSearches(ctx, c)
+		case "🚫 Исключения":
+			return showExclusions(ctx, c)
+		case "➕ Ещё фильтры":
+			return showMoreFilters(ctx, c)
+		case "🌐 Ссылка hh.ru":
+			return showSearchLink(ctx, c)
 		case "📊 Показать фильтры":
 			return showFilters(ctx, c)
 		case "🗑 Очистить фильтры":
@@ -103,6 +136,14 @@ func HandleText(ctx *Context) tele.HandlerFunc {
 			return toggleNotifications(ctx, c)
 		case "⏰ Изменить интервал":
 			return changeInterval(ctx, c)
+		case "🌍 Часовой пояс":
+			return showTimezones(ctx, c)
+		case "🌙 Тихие часы":
+			return showQuietHours(ctx, c)
+		case "📬 Доставка":
+			return showDelivery(ctx, c)
+		case "🗓 Расписание":
+			return showCheckSchedule(ctx, c)
 
 		// Cancel
 		case "❌ Отмена":
@@ -114,14 +155,9 @@ func HandleText(ctx *Context) tele.HandlerFunc {
 				return saveInterval(ctx, c, intervalMinutes)
 			}
 
-			// Handle experience selection
-			if models.IsValidExperience(text) {
-				return saveExperience(ctx, c, text)
-			}
-
-			// Handle schedule selection
-			if models.IsValidSchedule(text) {
-				return saveSchedule(ctx, c, text)
+			// hh.ru search links pasted outside the import flow
+			if headhunter.IsSearchPageURL(text) {
+				return importSearchURL(ctx, c, text)
 			}
 
 			return c.Reply("Используйте кнопки меню или команды")
@@ -155,13 +191,7 @@ func handleTextFilterInput(ctx *Context, c tele.Context) error {
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := &models.UserFilter{
-		UserID:      userID,
-		FilterType:  models.FilterTypeText,
-		FilterValue: text,
-	}
-
-	if err := ctx.Store.SaveFilter(dbCtx, filter); err != nil {
+	if err := saveSearchFilter(dbCtx, ctx, userID, models.FilterTypeText, text); err != nil {
 		ctx.Logger.Error("failed to save text filter", zap.Error(err))
 		return c.Send("😔 Ошибка при сохранении фильтра")
 	}
@@ -213,21 +243,14 @@ func handleCityFilterInput(ctx *Context, c tele.Context) error {
 
 	if len(areas) == 1 {
 		area := areas[0]
-		filter := &models.UserFilter{
-			UserID:      userID,
-			FilterType:  models.FilterTypeArea,
-			FilterValue: area.ID,
-		}
-		if err := ctx.Store.SaveFilter(dbCtx, filter); err != nil {
+		if _, err := addAreaToSearch(dbCtx, ctx, userID, area.ID); errors.Is(err, errTooManyAreas) {
+			return c.Send(fmt.Sprintf("⚠️ Можно выбрать не больше %d городов", models.MaxAreasPerSearch))
+		} else if err != nil {
 			ctx.Logger.Error("failed to save city filter", zap.Error(err))
 			return c.Send("😔 Ошибка при сохранении фильтра")
 		}
 		_ = clearUserState(ctx, userID)
-		return c.Send(
-			fmt.Sprintf("✅ Город установлен: *%s*", utils.EscapeMarkdown(area.Path)),
-			utils.FiltersMenuKeyboard(),
-			tele.ModeMarkdownV2,
-		)
+		return sendAreaSelection(ctx, c, dbCtx, userID, false)
 	}
 
 	// multiple matches → show inline list
@@ -251,6 +274,63 @@ func handleCityFilterInput(ctx *Context, c tele.Context) error {
 	return c.Send("Нашлось несколько вариантов — выберите точный:", menu)
 }
 
+var errTooManyAreas = errors.New("too many areas in search")
+
+// addAreaToSearch adds an area to the active search's set, keeping it under the limit
+func addAreaToSearch(dbCtx context.Context, ctx *Context, userID int64, areaID string) ([]string, error) {
+	values, err := getSearchFilterValues(dbCtx, ctx, userID, models.FilterTypeArea)
+	if err != nil {
+		return nil, err
+	}
+
+	if models.ContainsFilterValue(values, areaID) {
+		return values, nil
+	}
+
+	if len(values) >= models.MaxAreasPerSearch {
+		return nil, errTooManyAreas
+	}
+
+	values = append(values, areaID)
+
+	if err := saveSearchFilterValues(dbCtx, ctx, userID, models.FilterTypeArea, values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// sendAreaSelection shows the selected areas as a checkbox list (edits the message if edit is set)
+func sendAreaSelection(ctx *Context, c tele.Context, dbCtx context.Context, userID int64, edit bool) error {
+	values, err := getSearchFilterValues(dbCtx, ctx, userID, models.FilterTypeArea)
+	if err != nil {
+		ctx.Logger.Error("failed to get area filter", zap.Error(err))
+		return c.Send("😔 Ошибка при получении фильтров")
+	}
+
+	areas := make([]headhunter.IDName, 0, len(values))
+	for _, id := range values {
+		name := id
+		if area, err := ctx.HHClient.GetArea(dbCtx, id); err == nil && area != nil {
+			name = area.Name
+		} else {
+			ctx.Logger.Warn("failed to get area name", zap.String("area_id", id), zap.Error(err))
+		}
+		areas = append(areas, headhunter.IDName{ID: id, Name: name})
+	}
+
+	message := "📍 Выбранные города (нажмите, чтобы убрать):"
+	if len(areas) == 0 {
+		message = "📍 Города не выбраны — поиск по всем регионам."
+	}
+
+	if edit {
+		return c.Edit(message, utils.AreaKeyboard(areas))
+	}
+
+	return c.Send(message, utils.AreaKeyboard(areas))
+}
+
 // ==================== Salary Filter ====================
 
 func startSalaryFilter(ctx *Context, c tele.Context) error {
@@ -282,13 +362,7 @@ func handleSalaryFilterInput(ctx *Context, c tele.Context) error {
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := &models.UserFilter{
-		UserID:      userID,
-		FilterType:  models.FilterTypeSalary,
-		FilterValue: text,
-	}
-
-	if err := ctx.Store.SaveFilter(dbCtx, filter); err != nil {
+	if err := saveSearchFilter(dbCtx, ctx, userID, models.FilterTypeSalary, text); err != nil {
 		ctx.Logger.Error("failed to save salary filter", zap.Error(err))
 		return c.Send("😔 Ошибка при сохранении фильтра")
 	}
@@ -309,44 +383,18 @@ func handleSalaryFilterInput(ctx *Context, c tele.Context) error {
 func startExperienceFilter(ctx *Context, c tele.Context) error {
 	userID := c.Sender().ID
 
-	if err := setUserState(ctx, userID, StateAwaitingExp); err != nil {
-		ctx.Logger.Error("failed to set user state", zap.Error(err))
-	}
-
-	return c.Send(
-		"💼 Выберите требуемый опыт работы:",
-		utils.ExperienceKeyboard(),
-	)
-}
-
-func saveExperience(ctx *Context, c tele.Context, experience string) error {
-	userID := c.Sender().ID
-
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Get experience ID from map
-	expID := models.GetExperienceID(experience)
-
-	filter := &models.UserFilter{
-		UserID:      userID,
-		FilterType:  models.FilterTypeExperience,
-		FilterValue: expID,
-	}
-
-	if err := ctx.Store.SaveFilter(dbCtx, filter); err != nil {
-		ctx.Logger.Error("failed to save experience filter", zap.Error(err))
-		return c.Send("😔 Ошибка при сохранении фильтра")
-	}
-
-	if err := clearUserState(ctx, userID); err != nil {
-		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	selected, err := getSearchFilterValues(dbCtx, ctx, userID, models.FilterTypeExperience)
+	if err != nil {
+		ctx.Logger.Error("failed to get experience filter", zap.Error(err))
+		return c.Send("😔 Ошибка при получении фильтров")
 	}
 
 	return c.Send(
-		fmt.Sprintf("✅ Опыт работы установлен: *%s*", utils.EscapeMarkdown(experience)),
-		utils.FiltersMenuKeyboard(),
-		tele.ModeMarkdownV2,
+		"💼 Выберите требуемый опыт работы (можно несколько):",
+		utils.ExperienceKeyboard(selected),
 	)
 }
 
@@ -355,44 +403,18 @@ func saveExperience(ctx *Context, c tele.Context, experience string) error {
 func startScheduleFilter(ctx *Context, c tele.Context) error {
 	userID := c.Sender().ID
 
-	if err := setUserState(ctx, userID, StateAwaitingSchedule); err != nil {
-		ctx.Logger.Error("failed to set user state", zap.Error(err))
-	}
-
-	return c.Send(
-		"⏰ Выберите желаемый график работы:",
-		utils.ScheduleKeyboard(),
-	)
-}
-
-func saveSchedule(ctx *Context, c tele.Context, schedule string) error {
-	userID := c.Sender().ID
-
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Get schedule ID from map
-	scheduleID := models.GetScheduleID(schedule)
-
-	filter := &models.UserFilter{
-		UserID:      userID,
-		FilterType:  models.FilterTypeSchedule,
-		FilterValue: scheduleID,
-	}
-
-	if err := ctx.Store.SaveFilter(dbCtx, filter); err != nil {
-		ctx.Logger.Error("failed to save schedule filter", zap.Error(err))
-		return c.Send("😔 Ошибка при сохранении фильтра")
-	}
-
-	if err := clearUserState(ctx, userID); err != nil {
-		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	selected, err := getSearchFilterValues(dbCtx, ctx, userID, models.FilterTypeSchedule)
+	if err != nil {
+		ctx.Logger.Error("failed to get schedule filter", zap.Error(err))
+		return c.Send("😔 Ошибка при получении фильтров")
 	}
 
 	return c.Send(
-		fmt.Sprintf("✅ График работы установлен: *%s*", utils.EscapeMarkdown(schedule)),
-		utils.FiltersMenuKeyboard(),
-		tele.ModeMarkdownV2,
+		"⏰ Выберите желаемый график работы (можно несколько):",
+		utils.ScheduleKeyboard(selected),
 	)
 }
 
@@ -439,13 +461,7 @@ func handlePeriodFilterInput(ctx *Context, c tele.Context) error {
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := &models.UserFilter{
-		UserID:      userID,
-		FilterType:  models.FilterTypePublishedWithin,
-		FilterValue: strconv.Itoa(days),
-	}
-
-	if err := ctx.Store.SaveFilter(dbCtx, filter); err != nil {
+	if err := saveSearchFilter(dbCtx, ctx, userID, models.FilterTypePublishedWithin, strconv.Itoa(days)); err != nil {
 		ctx.Logger.Error("failed to save period filter", zap.Error(err))
 		return c.Send("😔 Ошибка при сохранении периода")
 	}
@@ -469,20 +485,26 @@ func showFilters(ctx *Context, c tele.Context) error {
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filters, err := ctx.Store.GetUserFilters(dbCtx, userID)
+	search, err := getActiveSearch(dbCtx, ctx, userID)
 	if err != nil {
-		ctx.Logger.Error("failed to get user filters", zap.Error(err))
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при получении фильтров")
+	}
+
+	filters, err := ctx.Store.GetSearchFilters(dbCtx, search.ID)
+	if err != nil {
+		ctx.Logger.Error("failed to get search filters", zap.Error(err))
 		return c.Send("😔 Ошибка при получении фильтров")
 	}
 
 	if len(filters) == 0 {
 		return c.Send(
-			"ℹ️ У вас нет установленных фильтров",
+			fmt.Sprintf("ℹ️ В поиске «%s» нет установленных фильтров", search.Name),
 			utils.FiltersMenuKeyboard(),
 		)
 	}
 
-	message := utils.FormatFiltersMessage(filters)
+	message := utils.FormatFiltersMessage(search.Name, filters)
 
 	return c.Send(
 		message,
@@ -499,7 +521,7 @@ func clearFilters(ctx *Context, c tele.Context) error {
 	}
 
 	return c.Send(
-		"🗑 Вы уверены, что хотите очистить все фильтры?",
+		"🗑 Вы уверены, что хотите очистить все фильтры текущего поиска?",
 		utils.ConfirmKeyboard(),
 	)
 }
@@ -510,7 +532,13 @@ func confirmClearFilters(ctx *Context, c tele.Context) error {
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := ctx.Store.ClearUserFilters(dbCtx, userID); err != nil {
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при очистке фильтров")
+	}
+
+	if err := ctx.Store.ClearSearchFilters(dbCtx, search.ID); err != nil {
 		ctx.Logger.Error("failed to clear filters", zap.Error(err))
 		return c.Send("😔 Ошибка при очистке фильтров")
 	}
@@ -535,23 +563,36 @@ func handleStateInput(ctx *Context, c tele.Context, state string) error {
 		return handleCityFilterInput(ctx, c)
 	case StateAwaitingSalary:
 		return handleSalaryFilterInput(ctx, c)
-	case StateAwaitingExp:
-		txt := strings.TrimSpace(c.Text())
-		if !models.IsValidExperience(txt) {
-			return c.Send("Выберите один из вариантов кнопками ниже", utils.ExperienceKeyboard())
-		}
-		return saveExperience(ctx, c, txt)
-	case StateAwaitingSchedule:
-		txt := strings.TrimSpace(c.Text())
-		if !models.IsValidSchedule(txt) {
-			return c.Send("Выберите один из вариантов кнопками ниже", utils.ScheduleKeyboard())
-		}
-		return saveSchedule(ctx, c, txt)
 	case StateAwaitingPeriod:
 		return handlePeriodFilterInput(ctx, c)
 	case StateConfirmClear:
 		return handleClearFiltersConfirm(ctx, c)
+	case StateAwaitingExcludedWords:
+		return handleExcludedWordsInput(ctx, c)
+	case StateAwaitingSearchName:
+		return handleSearchNameInput(ctx, c)
+	case StateAwaitingSearchRename:
+		return handleSearchRenameInput(ctx, c)
+	case StateAwaitingSearchURL:
+		return handleSearchURLInput(ctx, c)
+	case StateAwaitingSalaryTo:
+		return handleSalaryToInput(ctx, c)
+	case StateAwaitingFeedURL:
+		return handleFeedURLInput(ctx, c)
+	case StateAwaitingQuietHours:
+		return handleQuietHoursInput(ctx, c)
+	case StateAwaitingCheckSchedule:
+		return handleCheckScheduleInput(ctx, c)
 	default:
+		if filterType, ok := strings.CutPrefix(state, StateAwaitingFilterIDs); ok {
+			return handleFilterIDsInput(ctx, c, filterType)
+		}
+		if savedID, ok := strings.CutPrefix(state, StateAwaitingSavedNote); ok {
+			return handleSavedNoteInput(ctx, c, savedID)
+		}
+		if applicationID, ok := strings.CutPrefix(state, StateAwaitingApplicationNote); ok {
+			return handleApplicationNoteInput(ctx, c, applicationID)
+		}
 		_ = clearUserState(ctx, c.Sender().ID)
 		return c.Reply("Используйте кнопки меню или команды")
 	}
@@ -573,6 +614,76 @@ func handleClearFiltersConfirm(ctx *Context, c tele.Context) error {
 	}
 }
 
+// saveSearchFilter stores a filter value in the user's active search
+func saveSearchFilter(dbCtx context.Context, ctx *Context, userID int64, filterType, value string) error {
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	filter := &models.UserFilter{
+		UserID:      userID,
+		SearchID:    search.ID,
+		FilterType:  filterType,
+		FilterValue: value,
+	}
+
+	return ctx.Store.SaveFilter(dbCtx, filter)
+}
+
+// getSearchFilterValues returns the value set of a multi-value filter in the active search
+func getSearchFilterValues(dbCtx context.Context, ctx *Context, userID int64, filterType string) ([]string, error) {
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := ctx.Store.GetFilter(dbCtx, search.ID, filterType)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return nil, nil
+	}
+
+	return models.SplitFilterValues(filter.FilterValue), nil
+}
+
+// saveSearchFilterValues stores a value set, removing the filter once the set is empty
+func saveSearchFilterValues(dbCtx context.Context, ctx *Context, userID int64, filterType string, values []string) error {
+	if len(values) > 0 {
+		return saveSearchFilter(dbCtx, ctx, userID, filterType, models.JoinFilterValues(values))
+	}
+
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := ctx.Store.GetFilter(dbCtx, search.ID, filterType)
+	if err != nil || existing == nil {
+		return err
+	}
+
+	return ctx.Store.DeleteFilter(dbCtx, search.ID, filterType)
+}
+
+// toggleSearchFilterValue flips one value of a multi-value filter and returns the new set
+func toggleSearchFilterValue(dbCtx context.Context, ctx *Context, userID int64, filterType, value string) ([]string, error) {
+	values, err := getSearchFilterValues(dbCtx, ctx, userID, filterType)
+	if err != nil {
+		return nil, err
+	}
+
+	values = models.ToggleFilterValue(values, value)
+
+	if err := saveSearchFilterValues(dbCtx, ctx, userID, filterType, values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
 func setUserState(ctx *Context, userID int64, state string) error {
 	key := fmt.Sprintf("user:%d:state", userID)
 	return ctx.Cache.SetString(context.Background(), key, state, 30*time.Minute)
@@ -645,7 +756,7 @@ func toggleNotifications(ctx *Context, c tele.Context) error {
 
 func changeInterval(ctx *Context, c tele.Context) error {
 	return c.Send(
-		"⏰ Выберите интервал проверки новых вакансий:",
+		"⏰ Выберите интервал проверки новых вакансий для текущего поиска:",
 		utils.IntervalKeyboard(),
 	)
 }
@@ -656,21 +767,27 @@ func saveInterval(ctx *Context, c tele.Context, intervalMinutes int) error {
 	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := ctx.Store.SetNotifyInterval(dbCtx, userID, intervalMinutes); err != nil {
-		ctx.Logger.Error("failed to set notify interval", zap.Error(err))
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при сохранении интервала")
+	}
+
+	if err := ctx.Store.SetSearchInterval(dbCtx, search.ID, intervalMinutes); err != nil {
+		ctx.Logger.Error("failed to set search interval", zap.Error(err))
 		return c.Send("😔 Ошибка при сохранении интервала")
 	}
 
-	user, err := ctx.Store.GetUser(dbCtx, userID)
+	user, searches, err := loadSettings(dbCtx, ctx, userID)
 	if err != nil {
-		ctx.Logger.Error("failed to get user", zap.Error(err))
+		ctx.Logger.Error("failed to load settings", zap.Error(err))
 		return c.Send("😔 Ошибка при получении данных")
 	}
 
-	message := utils.FormatSettingsMessage(user)
+	message := utils.FormatSettingsMessage(user, searches)
 
 	return c.Send(
-		"✅ Интервал проверки обновлен\n\n"+message,
+		fmt.Sprintf("✅ Интервал проверки поиска *%s* обновлен\n\n", utils.EscapeMarkdown(search.Name))+message,
 		utils.SettingsKeyboard(user.CheckEnabled),
 		tele.ModeMarkdownV2,
 	)
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/api/headhunter"
+	"hh-vacancy-bot/internal/bot/query"
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/models"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// ==================== Export ====================
+
+func showSearchLink(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при получении фильтров")
+	}
+
+	filtersMap, err := ctx.Store.GetSearchFiltersMap(dbCtx, search.ID)
+	if err != nil {
+		ctx.Logger.Error("failed to get search filters", zap.Error(err))
+		return c.Send("😔 Ошибка при получении фильтров")
+	}
+
+	link := headhunter.SearchPageURL(query.BuildSearchParams(filtersMap))
+
+	message := fmt.Sprintf("🌐 Поиск *%s* на hh\\.ru\\.\n\n", utils.EscapeMarkdown(search.Name))
+	message += "Чтобы перенести поиск с сайта в бот, отправьте ссылку вида " +
+		"`https://hh.ru/search/vacancy?...` — фильтры текущего поиска будут заменены\\."
+
+	return c.Send(message, utils.InlineSearchLinkKeyboard(link), tele.ModeMarkdownV2)
+}
+
+// ==================== Import ====================
+
+func startSearchImport(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	if err := setUserState(ctx, userID, StateAwaitingSearchURL); err != nil {
+		ctx.Logger.Error("failed to set user state", zap.Error(err))
+	}
+
+	if err := c.Send(
+		"📥 Отправьте ссылку на поиск с hh.ru (https://hh.ru/search/vacancy?...).\n"+
+			"Фильтры текущего поиска будут заменены.",
+		utils.CancelKeyboard(),
+	); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+func handleSearchURLInput(ctx *Context, c tele.Context) error {
+	text := strings.TrimSpace(c.Text())
+
+	if text == "" || text == "❌ Отмена" {
+		return cancelConversation(ctx, c)
+	}
+
+	return importSearchURL(ctx, c, text)
+}
+
+// importSearchURL replaces filters of the active search with those of an hh.ru link.
+// Hidden employers are kept since hh.ru links cannot express them.
+func importSearchURL(ctx *Context, c tele.Context, raw string) error {
+	userID := c.Sender().ID
+
+	params, ignored, err := headhunter.ParseSearchPageURL(raw)
+	if errors.Is(err, headhunter.ErrNotSearchURL) {
+		return c.Send("❌ Это не похоже на ссылку поиска hh.ru. Нужна ссылка вида https://hh.ru/search/vacancy?...")
+	}
+
+	filters, dropped := query.FiltersFromParams(params)
+	ignored = append(ignored, dropped...)
+	if len(filters) == 0 {
+		return c.Send("❌ В ссылке нет параметров поиска, которые бот умеет переносить")
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при импорте поиска")
+	}
+
+	if err := ctx.Store.ReplaceSearchFilters(dbCtx, userID, search.ID, filters, models.FilterTypeExcludedEmployers); err != nil {
+		ctx.Logger.Error("failed to import search filters", zap.Error(err))
+		return c.Send("😔 Ошибка при импорте поиска")
+	}
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	ctx.Logger.Info("search imported from hh.ru link",
+		zap.Int64("user_id", userID),
+		zap.Int64("search_id", search.ID),
+		zap.Int("filters", len(filters)),
+		zap.Strings("ignored", ignored),
+	)
+
+	saved, err := ctx.Store.GetSearchFilters(dbCtx, search.ID)
+	if err != nil {
+		ctx.Logger.Error("failed to get search filters", zap.Error(err))
+		return c.Send("✅ Поиск импортирован", utils.FiltersMenuKeyboard())
+	}
+
+	message := "✅ Поиск импортирован с hh\\.ru\n\n" + utils.FormatFiltersMessage(search.Name, saved)
+	if len(ignored) > 0 {
+		message += fmt.Sprintf("\n⚠️ Не перенесены параметры: %s", utils.EscapeMarkdown(strings.Join(ignored, ", ")))
+	}
+
+	return c.Send(message, utils.FiltersMenuKeyboard(), tele.ModeMarkdownV2)
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/models"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// StateAwaitingFilterIDs is followed by the filter type, e.g. "awaiting_filter_ids:metro"
+const StateAwaitingFilterIDs = "awaiting_filter_ids:"
+
+// HH ids are numeric, metro ones are dotted ("1.2" station, "1" line)
+var filterIDRegexp = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+var filterIDPrompts = map[string]string{
+	models.FilterTypeProfessionalRole: "🧑‍💻 Введите ID профессиональных ролей через запятую (например: 96, 160).\n" +
+		"ID видны в ссылке поиска hh.ru в параметре professional_role.",
+	models.FilterTypeIndustry: "🏭 Введите ID отраслей через запятую (например: 7, 43).\n" +
+		"ID видны в ссылке поиска hh.ru в параметре industry.",
+	models.FilterTypeEmployer: "🏢 Введите ID работодателей через запятую (например: 1740, 3529).\n" +
+		"ID есть в адресе страницы компании: hh.ru/employer/1740.",
+	models.FilterTypeMetro: "🚇 Введите ID станций или линий метро через запятую (например: 1.2, 4).\n" +
+		"ID видны в ссылке поиска hh.ru в параметре metro.",
+}
+
+// ==================== Menu ====================
+
+func showMoreFilters(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при получении фильтров")
+	}
+
+	filtersMap, err := ctx.Store.GetSearchFiltersMap(dbCtx, search.ID)
+	if err != nil {
+		ctx.Logger.Error("failed to get search filters", zap.Error(err))
+		return c.Send("😔 Ошибка при получении фильтров")
+	}
+
+	return c.Send(
+		fmt.Sprintf("➕ Дополнительные фильтры поиска «%s»:", search.Name),
+		utils.MoreFiltersKeyboard(filtersMap),
+	)
+}
+
+func handleMoreFilter(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	filterType := parts[1]
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	values, err := getSearchFilterValues(dbCtx, ctx, userID, filterType)
+	if err != nil {
+		ctx.Logger.Error("failed to get filter values", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	var sendErr error
+	switch filterType {
+	case models.FilterTypeEmployment, models.FilterTypeSearchField, models.FilterTypeLabel:
+		sendErr = c.Send(
+			fmt.Sprintf("Выберите значения фильтра «%s» (можно несколько):", getFilterDisplayName(filterType)),
+			utils.ChecklistKeyboard(filterType, values),
+		)
+	case models.FilterTypeOrderBy, models.FilterTypeCurrency:
+		selected := ""
+		if len(values) > 0 {
+			selected = values[0]
+		}
+		sendErr = c.Send(
+			fmt.Sprintf("Выберите значение фильтра «%s»:", getFilterDisplayName(filterType)),
+			utils.ChoiceKeyboard(filterType, selected),
+		)
+	case models.FilterTypeSalaryTo:
+		if err := setUserState(ctx, userID, StateAwaitingSalaryTo); err != nil {
+			ctx.Logger.Error("failed to set user state", zap.Error(err))
+		}
+		sendErr = c.Send(
+			"💰 Введите максимальную зарплату (например: 250000).\n"+
+				"Вакансии, где зарплата начинается выше, не будут показываться. Отправьте «-», чтобы убрать ограничение.",
+			utils.CancelKeyboard(),
+		)
+	default:
+		prompt, ok := filterIDPrompts[filterType]
+		if !ok {
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Неизвестный фильтр"})
+		}
+		if err := setUserState(ctx, userID, StateAwaitingFilterIDs+filterType); err != nil {
+			ctx.Logger.Error("failed to set user state", zap.Error(err))
+		}
+		sendErr = c.Send(prompt+"\nОтправьте «-», чтобы очистить фильтр.", utils.CancelKeyboard())
+	}
+
+	if sendErr != nil {
+		return sendErr
+	}
+
+	return c.Respond()
+}
+
+// ==================== Choice Callbacks ====================
+
+// handleSetFilter stores a single-choice value; an empty value clears the filter
+func handleSetFilter(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	filterType := parts[1]
+	value := ""
+	if len(parts) > 2 {
+		value = parts[2]
+	}
+
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var values []string
+	if value != "" {
+		values = []string{value}
+	}
+
+	if err := saveSearchFilterValues(dbCtx, ctx, userID, filterType, values); err != nil {
+		ctx.Logger.Error("failed to save filter",
+			zap.String("filter_type", filterType),
+			zap.Error(err),
+		)
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка при сохранении"})
+	}
+
+	if _, err := queuedCall(ctx, c, func(bot *tele.Bot) (*tele.Message, error) {
+		return bot.EditReplyMarkup(c.Message(), utils.ChoiceKeyboard(filterType, value))
+	}); err != nil {
+		ctx.Logger.Warn("failed to edit choice keyboard", zap.Error(err))
+	}
+
+	return c.Respond()
+}
+
+func handleToggleOnlyWithSalary(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	values, err := toggleSearchFilterValue(dbCtx, ctx, userID, models.FilterTypeOnlyWithSalary, "true")
+	if err != nil {
+		ctx.Logger.Error("failed to toggle only_with_salary", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка при сохранении"})
+	}
+
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err == nil {
+		if filtersMap, err := ctx.Store.GetSearchFiltersMap(dbCtx, search.ID); err == nil {
+			if _, err := queuedCall(ctx, c, func(bot *tele.Bot) (*tele.Message, error) {
+				return bot.EditReplyMarkup(c.Message(), utils.MoreFiltersKeyboard(filtersMap))
+			}); err != nil {
+				ctx.Logger.Warn("failed to edit more filters keyboard", zap.Error(err))
+			}
+		}
+	}
+
+	text := "💵 Показываются все вакансии"
+	if len(values) > 0 {
+		text = "💵 Только вакансии с указанной зарплатой"
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: text})
+}
+
+// ==================== Text Input ====================
+
+func handleSalaryToInput(ctx *Context, c tele.Context) error {
+	text := strings.TrimSpace(c.Text())
+	userID := c.Sender().ID
+
+	if text == "" || text == "❌ Отмена" {
+		return cancelConversation(ctx, c)
+	}
+
+	var values []string
+	if text != "-" {
+		salary, err := strconv.Atoi(text)
+		if err != nil || salary <= 0 {
+			return c.Send("❌ Неверный формат. Введите число (например: 250000):")
+		}
+		values = []string{strconv.Itoa(salary)}
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := saveSearchFilterValues(dbCtx, ctx, userID, models.FilterTypeSalaryTo, values); err != nil {
+		ctx.Logger.Error("failed to save salary_to filter", zap.Error(err))
+		return c.Send("😔 Ошибка при сохранении фильтра")
+	}
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	if len(values) == 0 {
+		return c.Send("✅ Ограничение зарплаты сверху убрано", utils.FiltersMenuKeyboard())
+	}
+
+	return c.Send(
+		fmt.Sprintf("✅ Максимальная зарплата установлена: *%s*", utils.EscapeMarkdown(values[0])),
+		utils.FiltersMenuKeyboard(),
+		tele.ModeMarkdownV2,
+	)
+}
+
+func handleFilterIDsInput(ctx *Context, c tele.Context, filterType string) error {
+	text := strings.TrimSpace(c.Text())
+	userID := c.Sender().ID
+
+	if text == "" || text == "❌ Отмена" {
+		return cancelConversation(ctx, c)
+	}
+
+	var ids []string
+	if text != "-" {
+		for _, id := range models.SplitFilterValues(strings.ReplaceAll(text, " ", ",")) {
+			if !filterIDRegexp.MatchString(id) {
+				return c.Send(fmt.Sprintf("❌ «%s» не похоже на ID. Введите числа через запятую:", id))
+			}
+			if !models.ContainsFilterValue(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if len(ids) > models.MaxIDsPerFilter {
+		return c.Send(fmt.Sprintf("❌ Не больше %d значений", models.MaxIDsPerFilter))
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := saveSearchFilterValues(dbCtx, ctx, userID, filterType, ids); err != nil {
+		ctx.Logger.Error("failed to save filter",
+			zap.String("filter_type", filterType),
+			zap.Error(err),
+		)
+		return c.Send("😔 Ошибка при сохранении фильтра")
+	}
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	displayName := getFilterDisplayName(filterType)
+
+	if len(ids) == 0 {
+		return c.Send(fmt.Sprintf("✅ Фильтр «%s» очищен", displayName), utils.FiltersMenuKeyboard())
+	}
+
+	return c.Send(
+		fmt.Sprintf("✅ Фильтр *%s* установлен: %s",
+			utils.EscapeMarkdown(displayName),
+			utils.EscapeMarkdown(strings.Join(ids, ", ")),
+		),
+		utils.FiltersMenuKeyboard(),
+		tele.ModeMarkdownV2,
+	)
+}
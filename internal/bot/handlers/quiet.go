@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/utils"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+func showTimezones(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err != nil || user == nil {
+		ctx.Logger.Error("failed to get user", zap.Int64("user_id", userID), zap.Error(err))
+		return c.Send("😔 Ошибка при получении данных")
+	}
+
+	if err := c.Send(
+		"🌍 Выберите часовой пояс — по нему считаются тихие часы:",
+		utils.TimezoneKeyboard(user.Location().String()),
+	); err != nil {
+		return err
+	}
+
+	return c.Send(
+		"Или отправьте геопозицию, и пояс определится автоматически.",
+		utils.LocationKeyboard(),
+	)
+}
+
+func handleTimezoneSelect(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 || index >= len(utils.TimezonePresets) {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неизвестный часовой пояс"})
+	}
+
+	if err := saveTimezone(ctx, c, utils.TimezonePresets[index]); err != nil {
+		return err
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "✅ Сохранено"})
+}
+
+// HandleLocation sets the time zone from a shared location
+func HandleLocation(ctx *Context) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		location := c.Message().Location
+		if location == nil {
+			return nil
+		}
+
+		preset, ok := utils.NearestTimezone(float64(location.Lat), float64(location.Lng))
+		if !ok {
+			return c.Send(
+				"🌍 Не удалось определить часовой пояс по геопозиции — выберите его из списка:",
+				utils.TimezoneKeyboard(""),
+			)
+		}
+
+		return saveTimezone(ctx, c, preset)
+	}
+}
+
+func saveTimezone(ctx *Context, c tele.Context, preset utils.TimezonePreset) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctx.Store.SetUserTimezone(dbCtx, userID, preset.Name); err != nil {
+		return c.Send("😔 Ошибка при сохранении часового пояса")
+	}
+
+	return sendSettingsUpdate(ctx, c, dbCtx, fmt.Sprintf("✅ Часовой пояс: %s", preset.Label))
+}
+
+func showQuietHours(ctx *Context, c tele.Context) error {
+	return c.Send(
+		"🌙 Тихие часы\n\n"+
+			"В это время бот не присылает уведомления. "+
+			"Найденные вакансии придут одной пачкой, когда тихие часы закончатся.",
+		utils.QuietHoursKeyboard(),
+	)
+}
+
+func handleQuietHoursSelect(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 3 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	start, errStart := strconv.Atoi(parts[1])
+	end, errEnd := strconv.Atoi(parts[2])
+	if errStart != nil || errEnd != nil || !validQuietHours(start, end) {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверные часы"})
+	}
+
+	if err := saveQuietHours(ctx, c, &start, &end); err != nil {
+		return err
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "✅ Сохранено"})
+}
+
+func handleQuietHoursOff(ctx *Context, c tele.Context) error {
+	if err := saveQuietHours(ctx, c, nil, nil); err != nil {
+		return err
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "🔔 Тихие часы выключены"})
+}
+
+func startQuietHoursInput(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	if err := setUserState(ctx, userID, StateAwaitingQuietHours); err != nil {
+		ctx.Logger.Error("failed to set user state", zap.Error(err))
+	}
+
+	if err := c.Send(
+		"✏️ Введите начало и конец тихих часов по местному времени, например: 22-8",
+		utils.CancelKeyboard(),
+	); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+var quietHoursRegexp = regexp.MustCompile(`^(\d{1,2})(?::00)?\s*[-–—]\s*(\d{1,2})(?::00)?$`)
+
+func handleQuietHoursInput(ctx *Context, c tele.Context) error {
+	text := strings.TrimSpace(c.Text())
+	userID := c.Sender().ID
+
+	if text == "" || text == "❌ Отмена" {
+		if err := clearUserState(ctx, userID); err != nil {
+			ctx.Logger.Warn("failed to clear state", zap.Error(err))
+		}
+		return HandleSettings(ctx)(c)
+	}
+
+	match := quietHoursRegexp.FindStringSubmatch(text)
+	if match == nil {
+		return c.Send("❌ Не понял часы. Введите, например: 22-8 или 23:00-07:00")
+	}
+
+	start, _ := strconv.Atoi(match[1])
+	end, _ := strconv.Atoi(match[2])
+	if !validQuietHours(start, end) {
+		return c.Send("❌ Часы должны быть от 0 до 23 и не совпадать")
+	}
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	return saveQuietHours(ctx, c, &start, &end)
+}
+
+func validQuietHours(start, end int) bool {
+	return start >= 0 && start <= 23 && end >= 0 && end <= 23 && start != end
+}
+
+func saveQuietHours(ctx *Context, c tele.Context, start, end *int) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctx.Store.SetQuietHours(dbCtx, userID, start, end); err != nil {
+		return c.Send("😔 Ошибка при сохранении тихих часов")
+	}
+
+	header := "🔔 Тихие часы выключены"
+	if start != nil && end != nil {
+		header = "🌙 Тихие часы: " + utils.FormatQuietHours(*start, *end)
+	}
+
+	return sendSettingsUpdate(ctx, c, dbCtx, header)
+}
+
+// sendSettingsUpdate confirms a change and shows the settings again
+func sendSettingsUpdate(ctx *Context, c tele.Context, dbCtx context.Context, header string) error {
+	user, searches, err := loadSettings(dbCtx, ctx, c.Sender().ID)
+	if err != nil {
+		ctx.Logger.Error("failed to load settings", zap.Error(err))
+		return c.Send("😔 Ошибка при получении данных")
+	}
+
+	return c.Send(
+		utils.EscapeMarkdown(header)+"\n\n"+utils.FormatSettingsMessage(user, searches),
+		utils.SettingsKeyboard(user.CheckEnabled),
+		tele.ModeMarkdownV2,
+	)
+}
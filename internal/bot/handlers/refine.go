@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/middleware"
+	"hh-vacancy-bot/internal/bot/query"
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/models"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const facetsPerCluster = 6
+
+var errRefineRateLimited = errors.New("hh api rate limited")
+
+// buildRefineView asks HH for clusters of the active search and renders them as facets
+func buildRefineView(dbCtx context.Context, ctx *Context, userID int64) (string, *tele.ReplyMarkup, error) {
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		return "", nil, fmt.Errorf("get active search: %w", err)
+	}
+
+	filtersMap, err := ctx.Store.GetSearchFiltersMap(dbCtx, search.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("get filters: %w", err)
+	}
+
+	if err := ctx.HHLimiter.Allow(middleware.HHBudgetInteractive); err != nil {
+		return "", nil, errRefineRateLimited
+	}
+
+	params := query.BuildSearchParams(filtersMap)
+	params.Clusters = true
+	params.PerPage = 1
+
+	response, err := ctx.HHClient.SearchVacancies(dbCtx, params)
+	if err != nil {
+		return "", nil, fmt.Errorf("search clusters: %w", err)
+	}
+
+	groups := query.Facets(response.Clusters, params, facetsPerCluster)
+
+	return utils.FormatRefineMessage(search.Name, groups), utils.RefineKeyboard(groups), nil
+}
+
+func handleRefine(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	text, keyboard, err := buildRefineView(dbCtx, ctx, userID)
+	if errors.Is(err, errRefineRateLimited) {
+		return c.Respond(&tele.CallbackResponse{Text: "⚠️ Попробуйте позже"})
+	}
+	if err != nil {
+		ctx.Logger.Error("failed to build refine view", zap.Int64("user_id", userID), zap.Error(err))
+		return respondSearchError(c, err)
+	}
+
+	if err := c.Send(text, keyboard, tele.ModeMarkdownV2); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+// handleRefineAdd adds a facet to the active search and refreshes the breakdown
+func handleRefineAdd(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 3 || parts[2] == "" || !query.IsFacetFilter(parts[1]) {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	filterType, value := parts[1], parts[2]
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var err error
+	switch filterType {
+	case models.FilterTypeSalary:
+		err = saveSearchFilter(dbCtx, ctx, userID, filterType, value)
+	case models.FilterTypeArea:
+		_, err = addAreaToSearch(dbCtx, ctx, userID, value)
+	default:
+		var values []string
+		values, err = getSearchFilterValues(dbCtx, ctx, userID, filterType)
+		if err == nil && !models.ContainsFilterValue(values, value) {
+			err = saveSearchFilterValues(dbCtx, ctx, userID, filterType, append(values, value))
+		}
+	}
+
+	if errors.Is(err, errTooManyAreas) {
+		return c.Respond(&tele.CallbackResponse{
+			Text: fmt.Sprintf("⚠️ Не больше %d городов в поиске", models.MaxAreasPerSearch),
+		})
+	}
+	if err != nil {
+		ctx.Logger.Error("failed to add facet",
+			zap.String("filter_type", filterType),
+			zap.String("value", value),
+			zap.Error(err),
+		)
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка при сохранении"})
+	}
+
+	text, keyboard, err := buildRefineView(dbCtx, ctx, userID)
+	if err == nil {
+		if err := c.Edit(text, keyboard, tele.ModeMarkdownV2); err != nil {
+			ctx.Logger.Warn("failed to edit refine view", zap.Error(err))
+		}
+	} else if !errors.Is(err, errRefineRateLimited) {
+		ctx.Logger.Warn("failed to refresh refine view", zap.Error(err))
+	}
+
+	return c.Respond(&tele.CallbackResponse{
+		Text: fmt.Sprintf("✅ Добавлено в фильтр «%s». Обновите /vacancies", getFilterDisplayName(filterType)),
+	})
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/api/headhunter"
+	"hh-vacancy-bot/internal/bot/middleware"
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// StateAwaitingSavedNote is followed by the bookmark id, e.g. "awaiting_saved_note:42"
+const StateAwaitingSavedNote = "awaiting_saved_note:"
+
+const (
+	// savedRecheckInterval is how long an open vacancy is trusted before hh.ru is asked again
+	savedRecheckInterval = 6 * time.Hour
+	maxSavedNoteLength   = 500
+)
+
+// /saved
+func HandleSaved(ctx *Context) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		text, keyboard, err := renderSavedPage(ctx, c.Sender().ID, 0)
+		if err != nil {
+			return c.Send("😔 Ошибка при получении сохранённых вакансий")
+		}
+
+		return c.Send(text, keyboard, tele.ModeMarkdownV2, tele.NoPreview)
+	}
+}
+
+// renderSavedPage builds a page of bookmarks, checking first whether the vacancies on it were archived
+func renderSavedPage(ctx *Context, userID int64, page int) (string, *tele.ReplyMarkup, error) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	total, err := ctx.Store.CountSavedVacancies(dbCtx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if total == 0 {
+		return "⭐ *Сохранённых вакансий нет*\n\nНажмите «⭐ Сохранить» под вакансией, чтобы вернуться к ней позже\\.", nil, nil
+	}
+
+	totalPages := (total + utils.SavedPageSize - 1) / utils.SavedPageSize
+	if page >= totalPages {
+		page = totalPages - 1
+	}
+	offset := page * utils.SavedPageSize
+
+	saved, err := ctx.Store.GetSavedVacancies(dbCtx, userID, utils.SavedPageSize, offset)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ids := make([]string, len(saved))
+	for i := range saved {
+		ids[i] = saved[i].VacancyID
+	}
+
+	cached, err := ctx.Store.GetCachedVacanciesByIDs(dbCtx, ids)
+	if err != nil {
+		return "", nil, err
+	}
+
+	vacancies := make(map[string]models.Vacancy, len(cached))
+	for _, v := range cached {
+		vacancies[v.ID] = v
+	}
+
+	refreshArchived(dbCtx, ctx, saved, vacancies)
+
+	text := utils.FormatSavedVacancies(saved, vacancies, offset, total)
+	return text, utils.SavedVacanciesKeyboard(saved, offset, page, totalPages), nil
+}
+
+// refreshArchived asks hh.ru whether the open vacancies on the page are still published.
+// Feed vacancies have no status to ask about; when the budget runs out the rest wait for the next view.
+func refreshArchived(dbCtx context.Context, ctx *Context, saved []models.SavedVacancy, vacancies map[string]models.Vacancy) {
+	for i := range saved {
+		item := &saved[i]
+
+		v, ok := vacancies[item.VacancyID]
+		if !ok || v.Source != source.SourceHH || item.ArchivedAt != nil {
+			continue
+		}
+		if item.CheckedAt != nil && time.Since(*item.CheckedAt) < savedRecheckInterval {
+			continue
+		}
+
+		if err := ctx.HHLimiter.Allow(middleware.HHBudgetInteractive); err != nil {
+			return
+		}
+
+		detail, err := ctx.HHClient.GetVacancy(dbCtx, v.ExternalID)
+		archived := errors.Is(err, headhunter.ErrNotFound)
+		switch {
+		case err == nil:
+			archived = detail.Archived
+		case !archived:
+			ctx.Logger.Warn("failed to check saved vacancy",
+				zap.String("vacancy_id", v.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if err := ctx.Store.MarkSavedVacancyChecked(dbCtx, item.ID, archived); err != nil {
+			continue
+		}
+
+		now := time.Now()
+		item.CheckedAt = &now
+		if archived {
+			item.ArchivedAt = &now
+		}
+	}
+}
+
+// handleSaveVacancy bookmarks a vacancy from its card: save_vacancy:<cache id>
+func handleSaveVacancy(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	// feed ids carry their own "source:" prefix
+	vacancyID := strings.Join(parts[1:], ":")
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ensureVacancyCached(dbCtx, ctx, vacancyID); err != nil {
+		return respondVacancyNotCached(ctx, c, vacancyID, err)
+	}
+
+	created, err := ctx.Store.SaveVacancy(dbCtx, userID, vacancyID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Не удалось сохранить вакансию"})
+	}
+
+	if !created {
+		return c.Respond(&tele.CallbackResponse{Text: "⭐ Уже в сохранённых — /saved"})
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "⭐ Сохранено — список в /saved"})
+}
+
+func handleSavedPage(ctx *Context, c tele.Context, payloadParts []string) error {
+	if len(payloadParts) == 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	if payloadParts[0] == "noop" {
+		return c.Respond(&tele.CallbackResponse{Text: "📄 Уже на этой странице"})
+	}
+
+	if len(payloadParts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	page, err := strconv.Atoi(payloadParts[1])
+	if err != nil || page < 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверная страница"})
+	}
+
+	if err := editSavedPage(ctx, c, page); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	return c.Respond()
+}
+
+// handleSavedDelete removes a bookmark and redraws the page it was on: saved_del:<id>:<page>
+func handleSavedDelete(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 3 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	savedID, errID := strconv.ParseInt(parts[1], 10, 64)
+	page, errPage := strconv.Atoi(parts[2])
+	if errID != nil || errPage != nil || page < 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctx.Store.DeleteSavedVacancy(dbCtx, c.Sender().ID, savedID); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Не удалось удалить"})
+	}
+
+	if err := editSavedPage(ctx, c, page); err != nil {
+		ctx.Logger.Warn("failed to redraw saved vacancies", zap.Error(err))
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "🗑 Удалено"})
+}
+
+func editSavedPage(ctx *Context, c tele.Context, page int) error {
+	text, keyboard, err := renderSavedPage(ctx, c.Sender().ID, page)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Edit(text, keyboard, tele.ModeMarkdownV2, tele.NoPreview); err != nil {
+		ctx.Logger.Warn("failed to edit saved vacancies", zap.Error(err))
+	}
+
+	return nil
+}
+
+// startSavedNote asks for the note of a bookmark: saved_note:<id>
+func startSavedNote(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	if _, err := strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	if err := setUserState(ctx, c.Sender().ID, StateAwaitingSavedNote+parts[1]); err != nil {
+		ctx.Logger.Error("failed to set user state", zap.Error(err))
+	}
+
+	if err := c.Send(
+		"📝 Введите заметку к вакансии, например: «откликнулся 12.03, ждать ответа».\n\nОтправьте «-», чтобы удалить заметку.",
+		utils.CancelKeyboard(),
+	); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+func handleSavedNoteInput(ctx *Context, c tele.Context, rawID string) error {
+	text := strings.TrimSpace(c.Text())
+	userID := c.Sender().ID
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	if text == "" || text == "❌ Отмена" {
+		return c.Send("❌ Операция отменена", utils.MainMenuKeyboard())
+	}
+
+	savedID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return c.Send("😔 Заметка не сохранена, попробуйте ещё раз из /saved", utils.MainMenuKeyboard())
+	}
+
+	var note *string
+	if text != "-" {
+		text = utils.TruncateString(text, maxSavedNoteLength)
+		note = &text
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctx.Store.SetSavedVacancyNote(dbCtx, userID, savedID, note); err != nil {
+		return c.Send("😔 Ошибка при сохранении заметки", utils.MainMenuKeyboard())
+	}
+
+	confirmation := "✅ Заметка сохранена"
+	if note == nil {
+		confirmation = "🗑 Заметка удалена"
+	}
+	if err := c.Send(confirmation, utils.MainMenuKeyboard()); err != nil {
+		return err
+	}
+
+	return HandleSaved(ctx)(c)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/cron"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// minScheduleGap keeps a custom schedule from polling hh.ru more often than the shortest interval
+	minScheduleGap = 15 * time.Minute
+	// schedulePreviewRuns is how many upcoming checks a new schedule shows
+	schedulePreviewRuns = 5
+)
+
+func showCheckSchedule(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err != nil || user == nil {
+		ctx.Logger.Error("failed to get user", zap.Int64("user_id", userID), zap.Error(err))
+		return c.Send("😔 Ошибка при получении данных")
+	}
+
+	current := "по интервалам поисков"
+	if user.CheckSchedule != nil {
+		current = utils.FormatCheckSchedule(*user.CheckSchedule)
+	}
+
+	return c.Send(
+		"🗓 Расписание проверок\n\n"+
+			"Вместо интервалов у каждого поиска бот может проверять все поиски по расписанию "+
+			"в вашем часовом поясе ("+utils.TimezoneLabel(user.Location().String())+").\n\n"+
+			"Сейчас: "+current,
+		utils.CheckScheduleKeyboard(user.CheckSchedule),
+	)
+}
+
+func handleCheckScheduleSelect(ctx *Context, c tele.Context, parts []string) error {
+	if len(parts) < 2 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 || index >= len(utils.CheckSchedulePresets) {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неизвестное расписание"})
+	}
+
+	schedule, err := cron.Parse(utils.CheckSchedulePresets[index].Expr)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверное расписание"})
+	}
+
+	if err := saveCheckSchedule(ctx, c, schedule); err != nil {
+		return err
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "✅ Сохранено"})
+}
+
+func handleCheckScheduleOff(ctx *Context, c tele.Context) error {
+	if err := saveCheckSchedule(ctx, c, nil); err != nil {
+		return err
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "⏰ Проверки по интервалам поисков"})
+}
+
+func startCheckScheduleInput(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	if err := setUserState(ctx, userID, StateAwaitingCheckSchedule); err != nil {
+		ctx.Logger.Error("failed to set user state", zap.Error(err))
+	}
+
+	if err := c.Send(
+		"✏️ Введите расписание в формате cron: минуты, часы, день месяца, месяц, день недели.\n\n"+
+			"Например:\n"+
+			"0 9,18 * * 1-5 — по будням в 09:00 и 18:00\n"+
+			"*/30 10-19 * * * — каждые 30 минут с 10:00 до 19:30\n\n"+
+			"Время — по вашему часовому поясу, проверки не чаще раза в 15 минут.",
+		utils.CancelKeyboard(),
+	); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+func handleCheckScheduleInput(ctx *Context, c tele.Context) error {
+	text := strings.TrimSpace(c.Text())
+	userID := c.Sender().ID
+
+	if text == "" || text == "❌ Отмена" {
+		if err := clearUserState(ctx, userID); err != nil {
+			ctx.Logger.Warn("failed to clear state", zap.Error(err))
+		}
+		return HandleSettings(ctx)(c)
+	}
+
+	schedule, err := cron.Parse(text)
+	if err != nil {
+		return c.Send("❌ Не понял расписание: " + err.Error() + "\n\nПопробуйте ещё раз, например: 0 9,18 * * 1-5")
+	}
+
+	gap, err := schedule.MinGap(time.Now(), 24)
+	if errors.Is(err, cron.ErrNoRuns) {
+		return c.Send("❌ По этому расписанию проверки никогда не запустятся. Попробуйте другое выражение.")
+	}
+	if err != nil {
+		return c.Send("❌ Не удалось разобрать расписание, попробуйте другое выражение.")
+	}
+	if gap < minScheduleGap {
+		return c.Send("❌ Слишком часто: между проверками должно быть не меньше 15 минут.")
+	}
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	return saveCheckSchedule(ctx, c, schedule)
+}
+
+// saveCheckSchedule stores the schedule with its first run and previews the next ones; nil goes back to intervals
+func saveCheckSchedule(ctx *Context, c tele.Context, schedule *cron.Schedule) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if schedule == nil {
+		// due right away, the checker then works out the earliest search interval
+		if err := ctx.Store.SetCheckSchedule(dbCtx, userID, nil, time.Now()); err != nil {
+			return c.Send("😔 Ошибка при сохранении расписания")
+		}
+		return sendSettingsUpdate(ctx, c, dbCtx, "⏰ Поиски проверяются по своим интервалам")
+	}
+
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err != nil || user == nil {
+		ctx.Logger.Error("failed to get user", zap.Int64("user_id", userID), zap.Error(err))
+		return c.Send("😔 Ошибка при получении данных")
+	}
+
+	runs, err := schedule.NextRuns(time.Now().In(user.Location()), schedulePreviewRuns)
+	if err != nil {
+		return c.Send("❌ По этому расписанию проверки никогда не запустятся")
+	}
+
+	// next_check_at is a TIMESTAMP without zone, store the run in server time like the checker does
+	expr := schedule.String()
+	if err := ctx.Store.SetCheckSchedule(dbCtx, userID, &expr, runs[0].In(time.Local)); err != nil {
+		return c.Send("😔 Ошибка при сохранении расписания")
+	}
+
+	return sendSettingsUpdate(ctx, c, dbCtx,
+		"🗓 Расписание: "+utils.FormatCheckSchedule(expr)+"\n\nБлижайшие проверки:\n"+utils.FormatScheduleRuns(runs))
+}
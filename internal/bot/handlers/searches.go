@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/models"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// getActiveSearch returns the search the user is editing, creating a default one if needed
+func getActiveSearch(dbCtx context.Context, ctx *Context, userID int64) (*models.Search, error) {
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user %d not found", userID)
+	}
+
+	if user.ActiveSearchID != nil {
+		search, err := ctx.Store.GetSearch(dbCtx, *user.ActiveSearchID)
+		if err != nil {
+			return nil, err
+		}
+		if search != nil {
+			return search, nil
+		}
+	}
+
+	searches, err := ctx.Store.GetUserSearches(dbCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var search *models.Search
+	if len(searches) > 0 {
+		search = &searches[0]
+	} else {
+		search = &models.Search{
+			UserID:         userID,
+			Name:           models.DefaultSearchName,
+			NotifyInterval: user.NotifyInterval,
+		}
+		if err := ctx.Store.CreateSearch(dbCtx, search); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ctx.Store.SetActiveSearch(dbCtx, userID, search.ID); err != nil {
+		return nil, err
+	}
+
+	return search, nil
+}
+
+// ==================== Searches List ====================
+
+func showSearches(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	active, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при получении поисков")
+	}
+
+	searches, err := ctx.Store.GetUserSearches(dbCtx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get user searches", zap.Error(err))
+		return c.Send("😔 Ошибка при получении поисков")
+	}
+
+	return c.Send(
+		utils.FormatSearchesMessage(searches, active.ID),
+		utils.InlineSearchesKeyboard(searches, active.ID),
+		tele.ModeMarkdownV2,
+	)
+}
+
+func refreshSearchesMessage(ctx *Context, c tele.Context, dbCtx context.Context, userID int64) {
+	active, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Warn("failed to get active search", zap.Error(err))
+		return
+	}
+
+	searches, err := ctx.Store.GetUserSearches(dbCtx, userID)
+	if err != nil {
+		ctx.Logger.Warn("failed to get user searches", zap.Error(err))
+		return
+	}
+
+	if err := c.Edit(
+		utils.FormatSearchesMessage(searches, active.ID),
+		utils.InlineSearchesKeyboard(searches, active.ID),
+		tele.ModeMarkdownV2,
+	); err != nil {
+		ctx.Logger.Warn("failed to edit searches message", zap.Error(err))
+	}
+}
+
+// ==================== Create & Rename ====================
+
+func startNewSearch(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := ctx.Store.CountUserSearches(dbCtx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to count searches", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	if count >= models.MaxSearchesPerUser {
+		return c.Respond(&tele.CallbackResponse{
+			Text: fmt.Sprintf("⚠️ Не больше %d поисков", models.MaxSearchesPerUser),
+		})
+	}
+
+	if err := setUserState(ctx, userID, StateAwaitingSearchName); err != nil {
+		ctx.Logger.Error("failed to set user state", zap.Error(err))
+	}
+
+	if err := c.Send(
+		"🆕 Введите название нового поиска (например: Go backend Москва):",
+		utils.CancelKeyboard(),
+	); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+func startRenameSearch(ctx *Context, c tele.Context) error {
+	userID := c.Sender().ID
+
+	if err := setUserState(ctx, userID, StateAwaitingSearchRename); err != nil {
+		ctx.Logger.Error("failed to set user state", zap.Error(err))
+	}
+
+	if err := c.Send(
+		"✏️ Введите новое название для текущего поиска:",
+		utils.CancelKeyboard(),
+	); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+func parseSearchName(text string) (string, bool) {
+	name := strings.Join(strings.Fields(text), " ")
+	if name == "" || len([]rune(name)) > models.MaxSearchNameLength {
+		return "", false
+	}
+	return name, true
+}
+
+func handleSearchNameInput(ctx *Context, c tele.Context) error {
+	text := strings.TrimSpace(c.Text())
+	userID := c.Sender().ID
+
+	if text == "" || text == "❌ Отмена" {
+		return cancelConversation(ctx, c)
+	}
+
+	name, ok := parseSearchName(text)
+	if !ok {
+		return c.Send(fmt.Sprintf("❌ Название должно быть от 1 до %d символов", models.MaxSearchNameLength))
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err != nil || user == nil {
+		ctx.Logger.Error("failed to get user", zap.Error(err))
+		return c.Send("😔 Ошибка при создании поиска")
+	}
+
+	search := &models.Search{
+		UserID:         userID,
+		Name:           name,
+		NotifyInterval: user.NotifyInterval,
+	}
+
+	if err := ctx.Store.CreateSearch(dbCtx, search); err != nil {
+		ctx.Logger.Error("failed to create search", zap.Error(err))
+		return c.Send("😔 Не удалось создать поиск. Возможно, такое название уже есть.")
+	}
+
+	if err := ctx.Store.SetActiveSearch(dbCtx, userID, search.ID); err != nil {
+		ctx.Logger.Error("failed to set active search", zap.Error(err))
+	}
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	return c.Send(
+		fmt.Sprintf("✅ Поиск *%s* создан и выбран\\. Настройте для него фильтры:", utils.EscapeMarkdown(name)),
+		utils.FiltersMenuKeyboard(),
+		tele.ModeMarkdownV2,
+	)
+}
+
+func handleSearchRenameInput(ctx *Context, c tele.Context) error {
+	text := strings.TrimSpace(c.Text())
+	userID := c.Sender().ID
+
+	if text == "" || text == "❌ Отмена" {
+		return cancelConversation(ctx, c)
+	}
+
+	name, ok := parseSearchName(text)
+	if !ok {
+		return c.Send(fmt.Sprintf("❌ Название должно быть от 1 до %d символов", models.MaxSearchNameLength))
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	search, err := getActiveSearch(dbCtx, ctx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to get active search", zap.Error(err))
+		return c.Send("😔 Ошибка при переименовании поиска")
+	}
+
+	if err := ctx.Store.RenameSearch(dbCtx, userID, search.ID, name); err != nil {
+		ctx.Logger.Error("failed to rename search", zap.Error(err))
+		return c.Send("😔 Не удалось переименовать поиск. Возможно, такое название уже есть.")
+	}
+
+	if err := clearUserState(ctx, userID); err != nil {
+		ctx.Logger.Warn("failed to clear state", zap.Error(err))
+	}
+
+	return c.Send(
+		fmt.Sprintf("✅ Поиск переименован: *%s*", utils.EscapeMarkdown(name)),
+		utils.FiltersMenuKeyboard(),
+		tele.ModeMarkdownV2,
+	)
+}
+
+// ==================== Callbacks ====================
+
+func handleSearchSelect(ctx *Context, c tele.Context, parts []string) error {
+	searchID, ok := parseSearchID(parts)
+	if !ok {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	search, err := ctx.Store.GetSearch(dbCtx, searchID)
+	if err != nil || search == nil || search.UserID != userID {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Поиск не найден"})
+	}
+
+	if err := ctx.Store.SetActiveSearch(dbCtx, userID, searchID); err != nil {
+		ctx.Logger.Error("failed to set active search", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	refreshSearchesMessage(ctx, c, dbCtx, userID)
+
+	return c.Respond(&tele.CallbackResponse{Text: "✅ Выбран поиск: " + search.Name})
+}
+
+func handleSearchDelete(ctx *Context, c tele.Context, parts []string) error {
+	searchID, ok := parseSearchID(parts)
+	if !ok {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Неверный формат"})
+	}
+
+	userID := c.Sender().ID
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := ctx.Store.CountUserSearches(dbCtx, userID)
+	if err != nil {
+		ctx.Logger.Error("failed to count searches", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка"})
+	}
+
+	if count <= 1 {
+		return c.Respond(&tele.CallbackResponse{Text: "⚠️ Нельзя удалить единственный поиск"})
+	}
+
+	if err := ctx.Store.DeleteSearch(dbCtx, userID, searchID); err != nil {
+		ctx.Logger.Error("failed to delete search", zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка удаления"})
+	}
+
+	refreshSearchesMessage(ctx, c, dbCtx, userID)
+
+	return c.Respond(&tele.CallbackResponse{Text: "✅ Поиск удалён"})
+}
+
+func parseSearchID(parts []string) (int64, bool) {
+	if len(parts) < 2 {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+
+	return id, true
+}
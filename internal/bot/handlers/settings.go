@@ -20,9 +20,9 @@ func HandleSettings(ctx *Context) tele.HandlerFunc {
 		dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		user, err := ctx.Store.GetUser(dbCtx, userID)
+		user, searches, err := loadSettings(dbCtx, ctx, userID)
 		if err != nil {
-			ctx.Logger.Error("failed to get user",
+			ctx.Logger.Error("failed to load settings",
 				zap.Int64("user_id", userID),
 				zap.Error(err),
 			)
@@ -46,7 +46,7 @@ func HandleSettings(ctx *Context) tele.HandlerFunc {
 			)
 		}
 
-		message := utils.FormatSettingsMessage(user)
+		message := utils.FormatSettingsMessage(user, searches)
 
 		return c.Send(
 			message,
@@ -56,6 +56,24 @@ func HandleSettings(ctx *Context) tele.HandlerFunc {
 	}
 }
 
+// loadSettings fetches the user together with their searches
+func loadSettings(dbCtx context.Context, ctx *Context, userID int64) (*models.User, []models.Search, error) {
+	user, err := ctx.Store.GetUser(dbCtx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, fmt.Errorf("user %d not found", userID)
+	}
+
+	searches, err := ctx.Store.GetUserSearches(dbCtx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, searches, nil
+}
+
 // Handle settings text buttons (legacy support)
 func HandleSettingsText(ctx *Context, c tele.Context, text string) error {
 	userID := c.Sender().ID
@@ -110,7 +128,12 @@ func enableNotifications(ctx *Context, c tele.Context, user *models.User) error
 	}
 
 	user.CheckEnabled = true
-	message := utils.FormatSettingsMessage(user)
+
+	searches, err := ctx.Store.GetUserSearches(dbCtx, user.ID)
+	if err != nil {
+		ctx.Logger.Warn("failed to get user searches", zap.Error(err))
+	}
+	message := utils.FormatSettingsMessage(user, searches)
 
 	return c.Send(
 		"✅ Уведомления включены\\!\n\n"+message,
@@ -132,7 +155,12 @@ func disableNotifications(ctx *Context, c tele.Context, user *models.User) error
 	}
 
 	user.CheckEnabled = false
-	message := utils.FormatSettingsMessage(user)
+
+	searches, err := ctx.Store.GetUserSearches(dbCtx, user.ID)
+	if err != nil {
+		ctx.Logger.Warn("failed to get user searches", zap.Error(err))
+	}
+	message := utils.FormatSettingsMessage(user, searches)
 
 	return c.Send(
 		"🔕 Уведомления отключены\n\n"+message,
@@ -38,6 +38,7 @@ func HandleStart(ctx *Context) tele.HandlerFunc {
 		}
 
 		userCreated := false
+		userReactivated := false
 
 		if user == nil {
 			user = &models.User{
@@ -55,6 +56,17 @@ func HandleStart(ctx *Context) tele.HandlerFunc {
 			ctx.Logger.Info("new user created", zap.Int64("user_id", userID))
 			userCreated = true
 		} else {
+			// the user blocked the bot earlier and came back
+			if user.DeactivatedReason != nil {
+				reactivated, err := ctx.Store.ReactivateUser(dbCtx, userID)
+				if err != nil {
+					ctx.Logger.Error("failed to reactivate user", zap.Int64("user_id", userID), zap.Error(err))
+				} else if reactivated {
+					user.CheckEnabled = true
+					userReactivated = true
+				}
+			}
+
 			needUpdate := false
 			if (user.Username == nil && userName != "") || (user.Username != nil && *user.Username != userName) {
 				user.Username = stringPtr(userName)
@@ -98,6 +110,12 @@ func HandleStart(ctx *Context) tele.HandlerFunc {
 		}
 		welcomeMsg := utils.FormatWelcomeMessage(name)
 
+		if userReactivated {
+			if err := c.Send("🔔 С возвращением! Уведомления о новых вакансиях снова включены."); err != nil {
+				ctx.Logger.Warn("failed to send reactivation notice", zap.Int64("user_id", userID), zap.Error(err))
+			}
+		}
+
 		return c.Send(
 			welcomeMsg,
 			utils.MainMenuKeyboard(),
@@ -114,23 +132,12 @@ func stringPtr(s string) *string {
 }
 
 func setDefaultLinguistFilter(ctx context.Context, handlerCtx *Context, userID int64) error {
-	textFilter := &models.UserFilter{
-		UserID:      userID,
-		FilterType:  models.FilterTypeText,
-		FilterValue: defaultLinguistQuery,
-	}
-
-	if err := handlerCtx.Store.SaveFilter(ctx, textFilter); err != nil {
+	if err := saveSearchFilter(ctx, handlerCtx, userID, models.FilterTypeText, defaultLinguistQuery); err != nil {
 		return err
 	}
 
-	periodFilter := &models.UserFilter{
-		UserID:      userID,
-		FilterType:  models.FilterTypePublishedWithin,
-		FilterValue: strconv.Itoa(models.DefaultPublishedWithinDays),
-	}
-
-	if err := handlerCtx.Store.SaveFilter(ctx, periodFilter); err != nil {
+	days := strconv.Itoa(models.DefaultPublishedWithinDays)
+	if err := saveSearchFilter(ctx, handlerCtx, userID, models.FilterTypePublishedWithin, days); err != nil {
 		return err
 	}
 
@@ -2,14 +2,19 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
+	"strings"
 	"time"
 
 	"hh-vacancy-bot/internal/api/headhunter"
 	"hh-vacancy-bot/internal/bot/middleware"
+	"hh-vacancy-bot/internal/bot/query"
+	"hh-vacancy-bot/internal/bot/sender"
 	"hh-vacancy-bot/internal/bot/utils"
 	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
+	"hh-vacancy-bot/internal/source/hh"
 
 	"go.uber.org/zap"
 	tele "gopkg.in/telebot.v3"
@@ -23,7 +28,13 @@ func HandleVacancies(ctx *Context) tele.HandlerFunc {
 		dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		filtersMap, err := ctx.Store.GetFiltersMap(dbCtx, userID)
+		search, err := getActiveSearch(dbCtx, ctx, userID)
+		if err != nil {
+			ctx.Logger.Error("failed to get active search", zap.Error(err))
+			return c.Reply("😔 Ошибка при получении фильтров")
+		}
+
+		filtersMap, err := ctx.Store.GetSearchFiltersMap(dbCtx, search.ID)
 		if err != nil {
 			ctx.Logger.Error("failed to get user filters", zap.Error(err))
 			return c.Reply("😔 Ошибка при получении фильтров")
@@ -34,65 +45,88 @@ func HandleVacancies(ctx *Context) tele.HandlerFunc {
 			return c.Send(message, tele.ModeMarkdownV2)
 		}
 
-		searchMsg, _ := c.Bot().Send(c.Recipient(), "🔍 Ищу вакансии...")
+		searchMsg, err := queuedCall(ctx, c, func(bot *tele.Bot) (*tele.Message, error) {
+			return bot.Send(c.Recipient(), "🔍 Ищу вакансии...")
+		})
+		if err != nil {
+			ctx.Logger.Warn("failed to send search status", zap.Error(err))
+		}
 
-		if err := middleware.CheckHHAPIRateLimit(ctx.Cache, ctx.Logger); err != nil {
-			ctx.Logger.Warn("HH API rate limit", zap.Error(err))
-			c.Bot().Edit(searchMsg, "⚠️ Слишком много запросов. Попробуйте через минуту.")
-			return nil
+		// replaces the "searching" message, or sends the text when that one did not get through
+		setStatus := func(text string) {
+			_, err := queuedCall(ctx, c, func(bot *tele.Bot) (*tele.Message, error) {
+				if searchMsg == nil {
+					return bot.Send(c.Recipient(), text)
+				}
+				return bot.Edit(searchMsg, text)
+			})
+			if err != nil {
+				ctx.Logger.Warn("failed to update search status", zap.Error(err))
+			}
 		}
 
-		searchParams := buildSearchParams(filtersMap)
-		if ctx.Config.MaxVacanciesPerCheck > 0 {
-			searchParams.PerPage = ctx.Config.MaxVacanciesPerCheck
+		if err := ctx.HHLimiter.Allow(middleware.HHBudgetInteractive); err != nil {
+			ctx.Logger.Warn("HH API rate limit", zap.Error(err))
+			setStatus("⚠️ Слишком много запросов. Попробуйте через минуту.")
+			return nil
 		}
 
-		response, err := ctx.HHClient.SearchVacancies(dbCtx, searchParams)
+		response, err := searchSource(dbCtx, ctx, search.ID).Search(dbCtx, source.Query{
+			Filters: filtersMap,
+			PerPage: ctx.Config.MaxVacanciesPerCheck,
+		})
 		if err != nil {
 			ctx.Logger.Error("failed to search vacancies",
 				zap.Int64("user_id", userID),
 				zap.Error(err),
 			)
-			c.Bot().Edit(searchMsg, "😔 Ошибка при поиске вакансий")
+			setStatus(utils.FormatSearchError(err))
 			return nil
 		}
 
-		c.Bot().Delete(searchMsg)
+		if searchMsg != nil {
+			if err := queuedDelete(ctx, searchMsg); err != nil {
+				ctx.Logger.Warn("failed to delete search status", zap.Error(err))
+			}
+		}
+
+		response.Items = query.ApplyLocalFilters(response.Items, filtersMap)
 
 		if len(response.Items) == 0 {
 			message := utils.FormatNoVacanciesMessage()
 			return c.Send(message, tele.ModeMarkdownV2)
 		}
 
-		go cacheVacancies(ctx, response.Items)
+		// cards offer save and apply buttons, which reference the cache row
+		cacheVacancies(ctx, response.Items)
 
-		vacancyIDs := headhunter.ExtractVacancyIDs(response)
+		vacancyIDs := source.CacheIDs(response.Items)
 		unseenIDs, err := ctx.Store.GetUnseenVacancies(dbCtx, userID, vacancyIDs)
 		if err != nil {
 			ctx.Logger.Error("failed to get unseen vacancies", zap.Error(err))
 			unseenIDs = vacancyIDs
 		}
 
-		var unseenVacancies []headhunter.VacancyItem
+		var unseenVacancies []source.Vacancy
 		unseenMap := make(map[string]bool)
 		for _, id := range unseenIDs {
 			unseenMap[id] = true
 		}
 
 		for _, vacancy := range response.Items {
-			if unseenMap[vacancy.ID] {
+			if unseenMap[vacancy.CacheID()] {
 				unseenVacancies = append(unseenVacancies, vacancy)
 			}
 		}
 
-		var delivered []headhunter.VacancyItem
+		var delivered []source.Vacancy
 
 		cleanupPaginationMessages(ctx, c, userID)
 
 		if len(unseenVacancies) == 0 {
 			infoMessage := fmt.Sprintf(
 				"ℹ️ *Новых вакансий нет*\n\nПоказываю вакансии за %s.",
-				utils.EscapeMarkdown(utils.FormatDays(searchParams.PublishedWithinDays)),
+				utils.EscapeMarkdown(utils.FormatDays(response.PublishedWithinDays)),
 			)
 
 			if err := c.Send(infoMessage, tele.ModeMarkdownV2); err != nil {
@@ -100,7 +134,7 @@ func HandleVacancies(ctx *Context) tele.HandlerFunc {
 				return c.Reply("😔 Ошибка при отправке вакансий")
 			}
 
-			messageIDs, err := deliverVacancyCards(ctx, c, response.Items, userID)
+			messageIDs, sent, err := deliverVacancyCards(ctx, c, response.Items, userID, search.ID)
 			if err != nil {
 				ctx.Logger.Error("failed to send historical vacancies", zap.Error(err))
 				return c.Reply("😔 Ошибка при отправке вакансий")
@@ -108,14 +142,14 @@ func HandleVacancies(ctx *Context) tele.HandlerFunc {
 
 			rememberPaginationMessages(ctx, userID, messageIDs)
 
-			delivered = response.Items
+			delivered = sent
 		} else {
 			maxVacancies := ctx.Config.MaxVacanciesPerCheck
 			if maxVacancies > 0 && len(unseenVacancies) > maxVacancies {
 				unseenVacancies = unseenVacancies[:maxVacancies]
 			}
 
-			messageIDs, err := sendVacanciesToUser(ctx, c, unseenVacancies, userID)
+			messageIDs, sent, err := sendVacanciesToUser(ctx, c, unseenVacancies, userID, search.ID)
 			if err != nil {
 				ctx.Logger.Error("failed to send vacancies", zap.Error(err))
 				return c.Reply("😔 Ошибка при отправке вакансий")
@@ -123,106 +157,92 @@ func HandleVacancies(ctx *Context) tele.HandlerFunc {
 
 			rememberPaginationMessages(ctx, userID, messageIDs)
 
-			delivered = unseenVacancies
+			delivered = sent
 		}
 
 		if len(delivered) > 0 {
 			go markVacanciesAsSeen(ctx, userID, delivered)
 		}
 
-		sendPaginationControls(ctx, c, response.Page, response.Pages, searchParams.PublishedWithinDays)
+		sendPaginationControls(ctx, c, response.Page, response.Pages, response.PublishedWithinDays)
 
 		return nil
 	}
 }
 
-func buildSearchParams(filters map[string]string) headhunter.VacancySearchParams {
-	params := headhunter.VacancySearchParams{
-		Page:                0,
-		PerPage:             20,
-		PublishedWithinDays: models.DefaultPublishedWithinDays,
-	}
-
-	if text, ok := filters[models.FilterTypeText]; ok {
-		params.Text = text
-	}
-
-	if area, ok := filters[models.FilterTypeArea]; ok {
-		params.Area = area
-	}
-
-	if salary, ok := filters[models.FilterTypeSalary]; ok {
-		if s, err := strconv.Atoi(salary); err == nil {
-			params.Salary = s
-		}
-	}
-
-	if exp, ok := filters[models.FilterTypeExperience]; ok {
-		params.Experience = exp
+// searchSource returns hh.ru plus the RSS feeds attached to the search
+func searchSource(dbCtx context.Context, ctx *Context, searchID int64) source.VacancySource {
+	feedURLs, err := ctx.Store.GetSearchFeedURLs(dbCtx, searchID)
+	if err != nil {
+		ctx.Logger.Warn("failed to get search feeds, using hh.ru only",
+			zap.Int64("search_id", searchID),
+			zap.Error(err),
+		)
 	}
 
-	if schedule, ok := filters[models.FilterTypeSchedule]; ok {
-		params.Schedule = schedule
-	}
+	return ctx.Sources.ForSearch(feedURLs)
+}
 
-	days := params.PublishedWithinDays
-	if raw, ok := filters[models.FilterTypePublishedWithin]; ok {
-		if parsed, err := strconv.Atoi(raw); err == nil {
-			if parsed < models.MinPublishedWithinDays {
-				parsed = models.MinPublishedWithinDays
-			}
-			if parsed > models.MaxPublishedWithinDays {
-				parsed = models.MaxPublishedWithinDays
-			}
-			days = parsed
+// respondSearchError answers a callback whose HH search failed.
+// Invalid queries get a full message since the hint does not fit an alert.
+func respondSearchError(c tele.Context, err error) error {
+	if errors.Is(err, headhunter.ErrBadRequest) {
+		if sendErr := c.Send(utils.FormatInvalidQueryMessage(err)); sendErr != nil {
+			return sendErr
 		}
+		return c.Respond()
 	}
 
-	params.PublishedWithinDays = days
-
-	now := time.Now()
-	dateTo := now
-	from := now.Add(-time.Duration(days) * 24 * time.Hour)
-	params.DateTo = &dateTo
-	params.DateFrom = &from
-
-	return params
+	return c.Respond(&tele.CallbackResponse{Text: utils.FormatSearchError(err), ShowAlert: true})
 }
 
-func sendVacanciesToUser(ctx *Context, c tele.Context, vacancies []headhunter.VacancyItem, userID int64) ([]int, error) {
+// sendVacanciesToUser sends a summary and the cards; it returns the message ids and the vacancies actually delivered
+func sendVacanciesToUser(ctx *Context, c tele.Context, vacancies []source.Vacancy, userID, searchID int64) ([]int, []source.Vacancy, error) {
 	summaryMsg := fmt.Sprintf(
 		"📋 *Найдено новых вакансий:* %d\n\n",
 		len(vacancies),
 	)
 
-	sent, err := c.Bot().Send(
+	sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	sent, err := ctx.Sender.Send(
+		sendCtx,
+		sender.Interactive,
 		c.Chat(),
 		summaryMsg,
 		&tele.SendOptions{ParseMode: tele.ModeMarkdownV2},
 	)
+	cancel()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	messageIDs, err := deliverVacancyCards(ctx, c, vacancies, userID)
+	messageIDs, delivered, err := deliverVacancyCards(ctx, c, vacancies, userID, searchID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	messageIDs = append([]int{sent.ID}, messageIDs...)
 
-	return messageIDs, nil
+	return messageIDs, delivered, nil
 }
 
-func deliverVacancyCards(ctx *Context, c tele.Context, vacancies []headhunter.VacancyItem, userID int64) ([]int, error) {
+// deliverVacancyCards skips cards Telegram rejects, so only the returned vacancies may be marked seen
+func deliverVacancyCards(ctx *Context, c tele.Context, vacancies []source.Vacancy, userID, searchID int64) ([]int, []source.Vacancy, error) {
 	var messageIDs []int
+	var delivered []source.Vacancy
+
+	// the send queue paces the cards, the timeout only bounds a stuck queue
+	sendCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
 
 	for i, vacancy := range vacancies {
 		message := utils.FormatVacancy(&vacancy)
 
-		keyboard := utils.InlineVacancyKeyboard(vacancy.AlternateURL)
+		keyboard := utils.InlineVacancyKeyboard(&vacancy, searchID)
 
-		sent, err := c.Bot().Send(
+		sent, err := ctx.Sender.Send(
+			sendCtx,
+			sender.Interactive,
 			c.Chat(),
 			message,
 			&tele.SendOptions{ParseMode: tele.ModeMarkdownV2, ReplyMarkup: keyboard},
@@ -231,25 +251,22 @@ func deliverVacancyCards(ctx *Context, c tele.Context, vacancies []headhunter.Va
 			ctx.Logger.Error("failed to send vacancy",
 				zap.Int("index", i),
 				zap.Int64("user_id", userID),
-				zap.String("vacancy_id", vacancy.ID),
+				zap.String("vacancy_id", vacancy.CacheID()),
 				zap.Error(err),
 			)
 			continue
 		}
 
 		messageIDs = append(messageIDs, sent.ID)
-
-		if i < len(vacancies)-1 {
-			time.Sleep(300 * time.Millisecond)
-		}
+		delivered = append(delivered, vacancy)
 	}
 
-	return messageIDs, nil
+	return messageIDs, delivered, nil
 }
 
 func sendPaginationControls(ctx *Context, c tele.Context, page, totalPages, days int) {
-	if totalPages <= 1 {
-		return
+	if totalPages < 1 {
+		totalPages = 1
 	}
 
 	text := fmt.Sprintf("📄 Страница %d из %d", page+1, totalPages)
@@ -257,46 +274,17 @@ func sendPaginationControls(ctx *Context, c tele.Context, page, totalPages, days
 		text = fmt.Sprintf("%s • за %s", text, utils.FormatDays(days))
 	}
 
-	if err := c.Send(text, utils.InlinePaginationKeyboard(page, totalPages, "vacancy_page")); err != nil {
+	if err := c.Send(text, utils.VacancyNavigationKeyboard(page, totalPages)); err != nil {
 		ctx.Logger.Warn("failed to send pagination controls", zap.Error(err))
 	}
 }
 
-func cacheVacancies(ctx *Context, vacancies []headhunter.VacancyItem) {
+func cacheVacancies(ctx *Context, vacancies []source.Vacancy) {
 	dbCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	for _, item := range vacancies {
-		vacancy := &models.Vacancy{
-			ID:          item.ID,
-			Title:       item.Name,
-			Area:        item.Area.Name,
-			AreaID:      item.Area.ID,
-			URL:         item.AlternateURL,
-			PublishedAt: item.PublishedAt.Time,
-		}
-
-		if item.Employer.Name != "" {
-			vacancy.Company = &item.Employer.Name
-		}
-
-		if item.Salary != nil {
-			vacancy.SalaryFrom = item.Salary.From
-			vacancy.SalaryTo = item.Salary.To
-			vacancy.Currency = &item.Salary.Currency
-		}
-
-		if item.Experience != nil {
-			vacancy.Experience = &item.Experience.Name
-		}
-
-		if item.Schedule != nil {
-			vacancy.Schedule = &item.Schedule.Name
-		}
-
-		if item.Employment != nil {
-			vacancy.Employment = &item.Employment.Name
-		}
+		vacancy := item.CacheRecord()
 
 		if err := ctx.Store.CacheVacancy(dbCtx, vacancy); err != nil {
 			ctx.Logger.Error("failed to cache vacancy",
@@ -307,18 +295,73 @@ func cacheVacancies(ctx *Context, vacancies []headhunter.VacancyItem) {
 	}
 }
 
-func markVacanciesAsSeen(ctx *Context, userID int64, vacancies []headhunter.VacancyItem) {
+// errVacancyGone means a card refers to a vacancy that can no longer be cached again
+var errVacancyGone = errors.New("vacancy is no longer available")
+
+// ensureVacancyCached restores the cache row of an old card before a bookmark or an application references it.
+// HH vacancies are fetched again, feed entries can't be and report errVacancyGone.
+func ensureVacancyCached(dbCtx context.Context, ctx *Context, vacancyID string) error {
+	cached, err := ctx.Store.GetVacancy(dbCtx, vacancyID)
+	if err != nil {
+		return err
+	}
+	if cached != nil {
+		return nil
+	}
+
+	// only feed ids carry a "source:" prefix
+	if strings.Contains(vacancyID, ":") {
+		return errVacancyGone
+	}
+
+	if err := ctx.HHLimiter.Allow(middleware.HHBudgetInteractive); err != nil {
+		return err
+	}
+
+	detail, err := ctx.HHClient.GetVacancy(dbCtx, vacancyID)
+	if errors.Is(err, headhunter.ErrNotFound) {
+		return errVacancyGone
+	}
+	if err != nil {
+		return err
+	}
+
+	vacancy := hh.ConvertVacancy(&detail.VacancyItem)
+	return ctx.Store.CacheVacancy(dbCtx, vacancy.CacheRecord())
+}
+
+// respondVacancyNotCached explains why an action on a card failed in ensureVacancyCached
+func respondVacancyNotCached(ctx *Context, c tele.Context, vacancyID string, err error) error {
+	switch {
+	case errors.Is(err, errVacancyGone):
+		return c.Respond(&tele.CallbackResponse{Text: "⚠️ Вакансия больше недоступна — найдите её заново"})
+	case errors.Is(err, middleware.ErrHHAPIBudgetExhausted):
+		return c.Respond(&tele.CallbackResponse{Text: "⚠️ Слишком много запросов. Попробуйте через минуту."})
+	}
+
+	ctx.Logger.Error("failed to restore cached vacancy",
+		zap.String("vacancy_id", vacancyID),
+		zap.Error(err),
+	)
+	return c.Respond(&tele.CallbackResponse{Text: "😔 Ошибка при загрузке вакансии"})
+}
+
+func markVacanciesAsSeen(ctx *Context, userID int64, vacancies []source.Vacancy) {
 	dbCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	records := make([]*models.Vacancy, 0, len(vacancies))
 	for _, vacancy := range vacancies {
-		if err := ctx.Store.MarkVacancyAsSeen(dbCtx, userID, vacancy.ID); err != nil {
-			ctx.Logger.Error("failed to mark vacancy as seen",
-				zap.Int64("user_id", userID),
-				zap.String("vacancy_id", vacancy.ID),
-				zap.Error(err),
-			)
-		}
+		records = append(records, vacancy.CacheRecord())
+	}
+
+	if err := ctx.Store.MarkVacanciesSeen(dbCtx, userID, records); err != nil {
+		ctx.Logger.Error("failed to mark vacancies as seen",
+			zap.Int64("user_id", userID),
+			zap.Int("count", len(vacancies)),
+			zap.Error(err),
+		)
+		return
 	}
 
 	ctx.Logger.Info("marked vacancies as seen",
@@ -375,7 +418,7 @@ func cleanupPaginationMessages(ctx *Context, c tele.Context, userID int64) {
 
 	for _, id := range messageIDs {
 		msg := &tele.Message{ID: id, Chat: chat}
-		if err := c.Bot().Delete(msg); err != nil {
+		if err := queuedDelete(ctx, msg); err != nil {
 			ctx.Logger.Warn("failed to delete old vacancy message",
 				zap.Int("message_id", id),
 				zap.Int64("user_id", userID),
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -53,24 +54,98 @@ func RateLimit(cache *redis.Cache, logger *zap.Logger) tele.MiddlewareFunc {
 	}
 }
 
-func CheckHHAPIRateLimit(cache *redis.Cache, logger *zap.Logger) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
+// HHBudget separates HH API quotas so background checks cannot starve users
+type HHBudget string
+
+const (
+	HHBudgetInteractive HHBudget = "interactive"
+	HHBudgetBackground  HHBudget = "background"
+)
 
-	count, err := cache.GetHHAPIRateLimit(ctx)
+var ErrHHAPIBudgetExhausted = errors.New("HH API budget exhausted")
+
+// HHLimit is a token bucket: Rate tokens per minute, at most Burst at once
+type HHLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// HHAPILimiter shares HH API budgets across bot instances through Redis
+type HHAPILimiter struct {
+	cache  *redis.Cache
+	limits map[HHBudget]HHLimit
+	logger *zap.Logger
+}
+
+func NewHHAPILimiter(cache *redis.Cache, interactive, background HHLimit, logger *zap.Logger) *HHAPILimiter {
+	return &HHAPILimiter{
+		cache: cache,
+		limits: map[HHBudget]HHLimit{
+			HHBudgetInteractive: interactive,
+			HHBudgetBackground:  background,
+		},
+		logger: logger,
+	}
+}
+
+// Allow takes a token from the budget; the error wraps ErrHHAPIBudgetExhausted when none is left
+func (l *HHAPILimiter) Allow(budget HHBudget) error {
+	allowed, wait, err := l.take(context.Background(), budget)
 	if err != nil {
-		logger.Error("failed to check HH API rate limit", zap.Error(err))
-		return nil
+		return err
 	}
 
-	if count > 50 {
-		return fmt.Errorf("HH API rate limit exceeded: %d requests", count)
+	if !allowed {
+		return fmt.Errorf("%w: %s, retry in %s", ErrHHAPIBudgetExhausted, budget, wait)
 	}
 
-	_, err = cache.IncrementHHAPIRateLimit(ctx)
+	return nil
+}
+
+// Wait blocks until the budget has a token or ctx is done.
+// Background work uses it to pace itself instead of failing.
+func (l *HHAPILimiter) Wait(ctx context.Context, budget HHBudget) error {
+	for {
+		allowed, wait, err := l.take(ctx, budget)
+		if err != nil {
+			return err
+		}
+
+		if allowed {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%w: %s, gave up waiting: %v", ErrHHAPIBudgetExhausted, budget, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *HHAPILimiter) take(ctx context.Context, budget HHBudget) (bool, time.Duration, error) {
+	limit, ok := l.limits[budget]
+	if !ok {
+		return false, 0, fmt.Errorf("unknown HH API budget: %s", budget)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	allowed, wait, err := l.cache.TakeHHAPIToken(ctx, string(budget), limit.Rate/60, limit.Burst)
 	if err != nil {
-		logger.Error("failed to increment HH API rate limit", zap.Error(err))
+		// fail open: HH's own 429s still protect us
+		l.logger.Error("failed to check HH API rate limit",
+			zap.String("budget", string(budget)),
+			zap.Error(err),
+		)
+		return true, 0, nil
 	}
 
-	return nil
+	return allowed, wait, nil
 }
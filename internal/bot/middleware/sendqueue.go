@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/sender"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// interactiveSendTimeout bounds how long a handler waits for its reply to get through the queue
+const interactiveSendTimeout = 30 * time.Second
+
+// SendQueue routes the replies handlers make through the context into the send queue at interactive priority,
+// so they share its global and per-chat limits with background notifications
+func SendQueue(queue *sender.Queue) tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			return next(&queuedContext{Context: c, queue: queue})
+		}
+	}
+}
+
+type queuedContext struct {
+	tele.Context
+	queue *sender.Queue
+}
+
+func (c *queuedContext) do(call sender.Call) error {
+	ctx, cancel := context.WithTimeout(context.Background(), interactiveSendTimeout)
+	defer cancel()
+
+	_, err := c.queue.Do(ctx, sender.Interactive, c.Recipient(), call)
+	return err
+}
+
+func (c *queuedContext) Send(what interface{}, opts ...interface{}) error {
+	to := c.Recipient()
+	return c.do(func(bot *tele.Bot) (*tele.Message, error) {
+		return bot.Send(to, what, opts...)
+	})
+}
+
+func (c *queuedContext) Reply(what interface{}, opts ...interface{}) error {
+	msg := c.Message()
+	if msg == nil {
+		return tele.ErrBadContext
+	}
+	return c.do(func(bot *tele.Bot) (*tele.Message, error) {
+		return bot.Reply(msg, what, opts...)
+	})
+}
+
+func (c *queuedContext) Edit(what interface{}, opts ...interface{}) error {
+	var target tele.Editable
+	switch {
+	case c.InlineResult() != nil:
+		target = c.InlineResult()
+	case c.Callback() != nil:
+		target = c.Callback()
+	default:
+		return tele.ErrBadContext
+	}
+	return c.do(func(bot *tele.Bot) (*tele.Message, error) {
+		return bot.Edit(target, what, opts...)
+	})
+}
+
+func (c *queuedContext) EditOrSend(what interface{}, opts ...interface{}) error {
+	err := c.Edit(what, opts...)
+	if err == tele.ErrBadContext {
+		return c.Send(what, opts...)
+	}
+	return err
+}
+
+func (c *queuedContext) EditOrReply(what interface{}, opts ...interface{}) error {
+	err := c.Edit(what, opts...)
+	if err == tele.ErrBadContext {
+		return c.Reply(what, opts...)
+	}
+	return err
+}
+
+func (c *queuedContext) Delete() error {
+	msg := c.Message()
+	if msg == nil {
+		return tele.ErrBadContext
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), interactiveSendTimeout)
+	defer cancel()
+
+	return c.queue.Delete(ctx, sender.Interactive, msg)
+}
@@ -0,0 +1,100 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
+)
+
+var highlightReplacer = strings.NewReplacer("<highlighttext>", "", "</highlighttext>", "")
+
+// ExcludedText builds HH's excluded_text from single-word stop-words;
+// phrases are left to ApplyExclusions since HH matches them word by word
+func ExcludedText(filters map[string]string) string {
+	var words []string
+	for _, word := range models.SplitFilterValues(filters[models.FilterTypeExcludedWords]) {
+		if len(strings.Fields(word)) == 1 {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, ", ")
+}
+
+// ApplyExclusions drops vacancies from blacklisted employers or mentioning stop-words
+func ApplyExclusions(items []source.Vacancy, filters map[string]string) []source.Vacancy {
+	employers := models.SplitFilterValues(filters[models.FilterTypeExcludedEmployers])
+
+	var words []string
+	for _, word := range models.SplitFilterValues(filters[models.FilterTypeExcludedWords]) {
+		words = append(words, normalizeText(word))
+	}
+
+	if len(employers) == 0 && len(words) == 0 {
+		return items
+	}
+
+	out := make([]source.Vacancy, 0, len(items))
+	for _, item := range items {
+		if item.EmployerID != "" && models.ContainsFilterValue(employers, item.EmployerID) {
+			continue
+		}
+		if containsAny(vacancyText(&item), words) {
+			continue
+		}
+		out = append(out, item)
+	}
+
+	return out
+}
+
+// ApplyLocalFilters applies everything HH cannot filter server-side
+func ApplyLocalFilters(items []source.Vacancy, filters map[string]string) []source.Vacancy {
+	return ApplySalaryCap(ApplyExclusions(items, filters), filters)
+}
+
+// ApplySalaryCap drops vacancies whose salary starts above the salary_to filter.
+// Vacancies without a salary or in another currency are kept.
+func ApplySalaryCap(items []source.Vacancy, filters map[string]string) []source.Vacancy {
+	salaryTo, err := strconv.Atoi(filters[models.FilterTypeSalaryTo])
+	if err != nil || salaryTo <= 0 {
+		return items
+	}
+
+	currency := filters[models.FilterTypeCurrency]
+	if currency == "" {
+		currency = "RUR"
+	}
+
+	out := make([]source.Vacancy, 0, len(items))
+	for _, item := range items {
+		if item.Salary != nil && item.Salary.From != nil &&
+			item.Salary.Currency == currency && *item.Salary.From > salaryTo {
+			continue
+		}
+		out = append(out, item)
+	}
+
+	return out
+}
+
+func vacancyText(item *source.Vacancy) string {
+	text := strings.Join([]string{item.Title, item.Requirement, item.Responsibility}, " ")
+	return normalizeText(highlightReplacer.Replace(text))
+}
+
+func containsAny(text string, words []string) bool {
+	for _, word := range words {
+		if word != "" && strings.Contains(text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeText(s string) string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "ё", "е")
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"hh-vacancy-bot/internal/api/headhunter"
+	"hh-vacancy-bot/internal/models"
+)
+
+// clusterFilters maps HH cluster ids to the filter types they refine
+var clusterFilters = map[string]string{
+	"area":              models.FilterTypeArea,
+	"metro":             models.FilterTypeMetro,
+	"salary":            models.FilterTypeSalary,
+	"experience":        models.FilterTypeExperience,
+	"schedule":          models.FilterTypeSchedule,
+	"employment":        models.FilterTypeEmployment,
+	"professional_role": models.FilterTypeProfessionalRole,
+	"industry":          models.FilterTypeIndustry,
+	"label":             models.FilterTypeLabel,
+}
+
+// Facet is a one-tap refinement: adding Value to FilterType narrows the search to Count vacancies
+type Facet struct {
+	FilterType string
+	Value      string
+	Name       string
+	Count      int
+}
+
+type FacetGroup struct {
+	Name   string
+	Facets []Facet
+}
+
+// Facets turns HH clusters into refinements not yet applied to params
+func Facets(clusters []headhunter.Cluster, params headhunter.VacancySearchParams, perGroup int) []FacetGroup {
+	current := params.QueryValues()
+
+	var groups []FacetGroup
+	for _, cluster := range clusters {
+		filterType, ok := clusterFilters[cluster.ID]
+		if !ok {
+			continue
+		}
+
+		applied := current[cluster.ID]
+		group := FacetGroup{Name: cluster.Name}
+
+		for _, item := range cluster.Items {
+			value := newValue(item.ParamValues(cluster.ID), applied)
+			if value == "" {
+				continue
+			}
+
+			group.Facets = append(group.Facets, Facet{
+				FilterType: filterType,
+				Value:      value,
+				Name:       item.Name,
+				Count:      item.Count,
+			})
+
+			if len(group.Facets) == perGroup {
+				break
+			}
+		}
+
+		if len(group.Facets) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+// IsFacetFilter reports whether filterType can be refined from clusters
+func IsFacetFilter(filterType string) bool {
+	for _, t := range clusterFilters {
+		if t == filterType {
+			return true
+		}
+	}
+	return false
+}
+
+func newValue(values, applied []string) string {
+	for _, v := range values {
+		if !models.ContainsFilterValue(applied, v) {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"hh-vacancy-bot/internal/api/headhunter"
+	"hh-vacancy-bot/internal/models"
+)
+
+// BuildSearchParams maps stored filters of a search to HH search params
+func BuildSearchParams(filters map[string]string) headhunter.VacancySearchParams {
+	params := headhunter.VacancySearchParams{
+		Page:                0,
+		PerPage:             20,
+		PublishedWithinDays: models.DefaultPublishedWithinDays,
+	}
+
+	if text, ok := filters[models.FilterTypeText]; ok {
+		params.Text = text
+	}
+
+	params.ExcludedText = ExcludedText(filters)
+
+	if area, ok := filters[models.FilterTypeArea]; ok {
+		params.Area = models.SplitFilterValues(area)
+	}
+
+	if salary, ok := filters[models.FilterTypeSalary]; ok {
+		if s, err := strconv.Atoi(salary); err == nil {
+			params.Salary = s
+		}
+	}
+
+	if exp, ok := filters[models.FilterTypeExperience]; ok {
+		params.Experience = models.SplitFilterValues(exp)
+	}
+
+	if schedule, ok := filters[models.FilterTypeSchedule]; ok {
+		params.Schedule = models.SplitFilterValues(schedule)
+	}
+
+	if employment, ok := filters[models.FilterTypeEmployment]; ok {
+		params.Employment = models.SplitFilterValues(employment)
+	}
+
+	params.ProfessionalRole = models.SplitFilterValues(filters[models.FilterTypeProfessionalRole])
+	params.Industry = models.SplitFilterValues(filters[models.FilterTypeIndustry])
+	params.EmployerID = models.SplitFilterValues(filters[models.FilterTypeEmployer])
+	params.SearchField = models.SplitFilterValues(filters[models.FilterTypeSearchField])
+	params.Label = models.SplitFilterValues(filters[models.FilterTypeLabel])
+	params.Metro = models.SplitFilterValues(filters[models.FilterTypeMetro])
+	params.OrderBy = filters[models.FilterTypeOrderBy]
+	params.Currency = filters[models.FilterTypeCurrency]
+	params.OnlyWithSalary = filters[models.FilterTypeOnlyWithSalary] == "true"
+
+	if salaryTo, ok := filters[models.FilterTypeSalaryTo]; ok {
+		if s, err := strconv.Atoi(salaryTo); err == nil {
+			params.SalaryTo = s
+		}
+	}
+
+	days := params.PublishedWithinDays
+	if raw, ok := filters[models.FilterTypePublishedWithin]; ok {
+		if parsed, err := strconv.Atoi(raw); err == nil {
+			if parsed < models.MinPublishedWithinDays {
+				parsed = models.MinPublishedWithinDays
+			}
+			if parsed > models.MaxPublishedWithinDays {
+				parsed = models.MaxPublishedWithinDays
+			}
+			days = parsed
+		}
+	}
+
+	params.PublishedWithinDays = days
+
+	now := time.Now()
+	dateTo := now
+	from := now.Add(-time.Duration(days) * 24 * time.Hour)
+	params.DateTo = &dateTo
+	params.DateFrom = &from
+
+	return params
+}
+
+// FiltersFromParams is the reverse of BuildSearchParams, used to import hh.ru links.
+// Params with more values than a search may hold are cut to the limit and reported as dropped.
+func FiltersFromParams(params headhunter.VacancySearchParams) (map[string]string, []string) {
+	filters := make(map[string]string)
+	var dropped []string
+
+	if params.Text != "" {
+		filters[models.FilterTypeText] = params.Text
+	}
+
+	if words := models.SplitFilterValues(params.ExcludedText); len(words) > 0 {
+		filters[models.FilterTypeExcludedWords] = models.JoinFilterValues(words)
+	}
+
+	setValues := func(filterType string, values []string) {
+		if len(values) > 0 {
+			filters[filterType] = models.JoinFilterValues(values)
+		}
+	}
+
+	limit := func(param string, values []string, max int) []string {
+		if len(values) <= max {
+			return values
+		}
+		dropped = append(dropped, fmt.Sprintf("%s: %d сверх лимита в %d", param, len(values)-max, max))
+		return values[:max]
+	}
+
+	setValues(models.FilterTypeArea, limit("area", params.Area, models.MaxAreasPerSearch))
+	setValues(models.FilterTypeExperience, params.Experience)
+	setValues(models.FilterTypeSchedule, params.Schedule)
+	setValues(models.FilterTypeEmployment, params.Employment)
+	setValues(models.FilterTypeProfessionalRole, limit("professional_role", params.ProfessionalRole, models.MaxIDsPerFilter))
+	setValues(models.FilterTypeIndustry, limit("industry", params.Industry, models.MaxIDsPerFilter))
+	setValues(models.FilterTypeEmployer, limit("employer_id", params.EmployerID, models.MaxIDsPerFilter))
+	setValues(models.FilterTypeMetro, limit("metro", params.Metro, models.MaxIDsPerFilter))
+	setValues(models.FilterTypeSearchField, params.SearchField)
+	setValues(models.FilterTypeLabel, params.Label)
+
+	if params.OrderBy != "" {
+		filters[models.FilterTypeOrderBy] = params.OrderBy
+	}
+
+	if params.Salary > 0 {
+		filters[models.FilterTypeSalary] = strconv.Itoa(params.Salary)
+	}
+
+	if params.SalaryTo > 0 {
+		filters[models.FilterTypeSalaryTo] = strconv.Itoa(params.SalaryTo)
+	}
+
+	if params.Currency != "" {
+		filters[models.FilterTypeCurrency] = params.Currency
+	}
+
+	if params.OnlyWithSalary {
+		filters[models.FilterTypeOnlyWithSalary] = "true"
+	}
+
+	if days := params.PublishedWithinDays; days > 0 {
+		if days > models.MaxPublishedWithinDays {
+			days = models.MaxPublishedWithinDays
+		}
+		filters[models.FilterTypePublishedWithin] = strconv.Itoa(days)
+	}
+
+	return filters, dropped
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"hh-vacancy-bot/internal/api/headhunter"
+	"hh-vacancy-bot/internal/models"
+)
+
+func TestFiltersSearchPageURLRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[string]string
+	}{
+		{
+			name: "defaults",
+			filters: map[string]string{
+				models.FilterTypeText:            "golang",
+				models.FilterTypePublishedWithin: "7",
+			},
+		},
+		{
+			name: "every exportable filter",
+			filters: map[string]string{
+				models.FilterTypeText:             "go OR golang",
+				models.FilterTypeExcludedWords:    "1с,битрикс",
+				models.FilterTypeArea:             "1,2",
+				models.FilterTypeSalary:           "250000",
+				models.FilterTypeCurrency:         "USD",
+				models.FilterTypeOnlyWithSalary:   "true",
+				models.FilterTypeExperience:       "between1And3,between3And6",
+				models.FilterTypeSchedule:         "remote,flexible",
+				models.FilterTypeEmployment:       "full",
+				models.FilterTypeProfessionalRole: "96,104",
+				models.FilterTypeIndustry:         "7",
+				models.FilterTypeEmployer:         "1740",
+				models.FilterTypeSearchField:      "name",
+				models.FilterTypeLabel:            "not_from_agency",
+				models.FilterTypeMetro:            "1.1,1.2",
+				models.FilterTypeOrderBy:          "publication_time",
+				models.FilterTypePublishedWithin:  "30",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := headhunter.SearchPageURL(BuildSearchParams(tt.filters))
+
+			params, ignored, err := headhunter.ParseSearchPageURL(link)
+			if err != nil {
+				t.Fatalf("ParseSearchPageURL(%q): %v", link, err)
+			}
+			if len(ignored) > 0 {
+				t.Errorf("ParseSearchPageURL(%q) ignored %v", link, ignored)
+			}
+
+			got, dropped := FiltersFromParams(params)
+			if len(dropped) > 0 {
+				t.Errorf("FiltersFromParams dropped %v", dropped)
+			}
+			if !reflect.DeepEqual(got, tt.filters) {
+				t.Errorf("round trip of %q:\n got  %v\n want %v", link, got, tt.filters)
+			}
+		})
+	}
+}
+
+func TestFiltersFromParamsReportsDropped(t *testing.T) {
+	ids := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = strconv.Itoa(i + 1)
+		}
+		return out
+	}
+
+	params := headhunter.VacancySearchParams{
+		Area:             ids(models.MaxAreasPerSearch + 3),
+		ProfessionalRole: ids(models.MaxIDsPerFilter + 1),
+		Industry:         ids(models.MaxIDsPerFilter),
+	}
+
+	filters, dropped := FiltersFromParams(params)
+
+	if got := models.SplitFilterValues(filters[models.FilterTypeArea]); len(got) != models.MaxAreasPerSearch {
+		t.Errorf("kept %d areas, want %d", len(got), models.MaxAreasPerSearch)
+	}
+	if got := models.SplitFilterValues(filters[models.FilterTypeProfessionalRole]); len(got) != models.MaxIDsPerFilter {
+		t.Errorf("kept %d roles, want %d", len(got), models.MaxIDsPerFilter)
+	}
+	if got := models.SplitFilterValues(filters[models.FilterTypeIndustry]); len(got) != models.MaxIDsPerFilter {
+		t.Errorf("kept %d industries, want %d", len(got), models.MaxIDsPerFilter)
+	}
+
+	want := []string{
+		"area: 3 сверх лимита в " + strconv.Itoa(models.MaxAreasPerSearch),
+		"professional_role: 1 сверх лимита в " + strconv.Itoa(models.MaxIDsPerFilter),
+	}
+	if !reflect.DeepEqual(dropped, want) {
+		t.Errorf("dropped = %v, want %v", dropped, want)
+	}
+}
@@ -2,15 +2,22 @@ package scheduler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"hh-vacancy-bot/internal/api/headhunter"
 	"hh-vacancy-bot/internal/bot/middleware"
+	"hh-vacancy-bot/internal/bot/query"
+	"hh-vacancy-bot/internal/bot/sender"
 	"hh-vacancy-bot/internal/bot/utils"
 	"hh-vacancy-bot/internal/config"
+	"hh-vacancy-bot/internal/cron"
 	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
 	"hh-vacancy-bot/internal/storage/postgres"
 	"hh-vacancy-bot/internal/storage/redis"
 
@@ -19,61 +26,112 @@ import (
 )
 
 type VacancyChecker struct {
-	bot      *tele.Bot
-	store    *postgres.Store
-	cache    *redis.Cache
-	hhClient *headhunter.Client
-	config   *config.Config
-	logger   *zap.Logger
+	sender  *sender.Queue
+	store   *postgres.Store
+	cache   *redis.Cache
+	sources *source.Registry
+	limiter *middleware.HHAPILimiter
+	config  *config.Config
+	logger  *zap.Logger
+	lease   *leaderLease
 }
 
 func New(
-	bot *tele.Bot,
+	queue *sender.Queue,
 	store *postgres.Store,
 	cache *redis.Cache,
-	hhClient *headhunter.Client,
+	sources *source.Registry,
+	limiter *middleware.HHAPILimiter,
 	cfg *config.Config,
 	logger *zap.Logger,
 ) *VacancyChecker {
 	return &VacancyChecker{
-		bot:      bot,
-		store:    store,
-		cache:    cache,
-		hhClient: hhClient,
-		config:   cfg,
-		logger:   logger,
+		sender:  queue,
+		store:   store,
+		cache:   cache,
+		sources: sources,
+		limiter: limiter,
+		config:  cfg,
+		logger:  logger,
+		lease:   newLeaderLease(cache, leaderLockName, cfg.LeaderLeaseTTL, logger),
 	}
 }
 
+// Start runs check rounds while this replica holds the leader lease.
+// Other replicas wait and take over when the leader stops renewing it.
 func (vc *VacancyChecker) Start(ctx context.Context) {
-	ticker := time.NewTicker(vc.config.CheckInterval)
-	defer ticker.Stop()
-
 	vc.logger.Info("vacancy checker started",
 		zap.Duration("interval", vc.config.CheckInterval),
 	)
 
-	time.Sleep(30 * time.Second)
+	// let the bot finish starting before the first round
+	select {
+	case <-ctx.Done():
+		vc.logger.Info("vacancy checker stopped")
+		return
+	case <-time.After(30 * time.Second):
+	}
+
+	for {
+		leaderCtx, release, err := vc.lease.Acquire(ctx)
+		if err != nil {
+			vc.logger.Info("vacancy checker stopped")
+			return
+		}
+
+		vc.runRounds(leaderCtx)
+		release()
+	}
+}
+
+// runRounds checks users and sends due digests and reminders every CheckInterval until ctx is done
+func (vc *VacancyChecker) runRounds(ctx context.Context) {
+	ticker := time.NewTicker(vc.config.CheckInterval)
+	defer ticker.Stop()
+
 	vc.checkVacanciesForAllUsers(ctx)
+	vc.enqueueDueDigests(ctx)
+	vc.enqueueApplicationReminders(ctx)
 
 	for {
 		select {
 		case <-ctx.Done():
-			vc.logger.Info("vacancy checker stopped")
 			return
 		case <-ticker.C:
 			vc.checkVacanciesForAllUsers(ctx)
+			vc.enqueueDueDigests(ctx)
+			vc.enqueueApplicationReminders(ctx)
 		}
 	}
 }
 
-func (vc *VacancyChecker) checkVacanciesForAllUsers(ctx context.Context) {
-	vc.logger.Info("starting vacancy check for all users")
+const progressLogInterval = 30 * time.Second
 
-	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-	defer cancel()
+// roundStats counts outcomes of one check round across workers
+type roundStats struct {
+	processed atomic.Int64
+	failed    atomic.Int64
+	deferred  atomic.Int64
+	skipped   atomic.Int64
+}
+
+// checkRound is the state workers share during one round
+type checkRound struct {
+	ctx       context.Context
+	stop      context.CancelFunc
+	stats     roundStats
+	shared    *sharedSearches
+	lockToken string
+}
+
+// checkVacanciesForAllUsers fans due users out to a bounded pool of workers.
+// The round is capped at CheckInterval so it never overlaps the next tick.
+func (vc *VacancyChecker) checkVacanciesForAllUsers(ctx context.Context) {
+	started := time.Now()
 
-	users, err := vc.store.GetUsersToCheck(dbCtx)
+	listCtx, cancelList := context.WithTimeout(ctx, 30*time.Second)
+	users, err := vc.store.GetUsersToCheck(listCtx)
+	cancelList()
 	if err != nil {
 		vc.logger.Error("failed to get users to check", zap.Error(err))
 		return
@@ -84,249 +142,823 @@ func (vc *VacancyChecker) checkVacanciesForAllUsers(ctx context.Context) {
 		return
 	}
 
-	vc.logger.Info("checking vacancies for users", zap.Int("count", len(users)))
+	workers := vc.config.CheckConcurrency
+	if workers > len(users) {
+		workers = len(users)
+	}
+
+	vc.logger.Info("starting vacancy check round",
+		zap.Int("due", len(users)),
+		zap.Int("workers", workers),
+	)
+
+	lockToken, err := redis.NewLockToken()
+	if err != nil {
+		vc.logger.Error("failed to start check round", zap.Error(err))
+		return
+	}
+
+	roundCtx, stopRound := context.WithTimeout(ctx, vc.config.CheckInterval)
+	defer stopRound()
+
+	// results outlive the round only briefly so the next round fetches fresh ones
+	sharedTTL := vc.config.CheckInterval / 2
+	if sharedTTL < time.Minute {
+		sharedTTL = time.Minute
+	}
+	round := &checkRound{
+		ctx:       roundCtx,
+		stop:      stopRound,
+		shared:    newSharedSearches(vc.cache, sharedTTL, vc.logger),
+		lockToken: lockToken,
+	}
+	vc.planSharedSearches(roundCtx, round.shared, users)
+
+	jobs := make(chan *models.User)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for user := range jobs {
+				vc.runUserCheck(ctx, round, user)
+			}
+		}()
+	}
+
+	progressDone := make(chan struct{})
+	go vc.logRoundProgress(progressDone, started, len(users), &round.stats)
+
+feed:
+	for i := range users {
+		select {
+		case jobs <- &users[i]:
+		case <-roundCtx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(progressDone)
+
+	processed := round.stats.processed.Load()
+	fields := []zap.Field{
+		zap.Int("due", len(users)),
+		zap.Int64("processed", processed),
+		zap.Int64("failed", round.stats.failed.Load()),
+		zap.Int64("deferred", round.stats.deferred.Load()),
+		zap.Int64("skipped", round.stats.skipped.Load()),
+		zap.Int64("remaining", int64(len(users))-processed),
+		zap.Int64("queries_fetched", round.shared.fetched.Load()),
+		zap.Int64("queries_reused", round.shared.reused.Load()),
+		zap.Duration("duration", time.Since(started)),
+	}
+
+	// leftover users are picked up by the next round, their searches are still due
+	if processed < int64(len(users)) {
+		vc.logger.Warn("vacancy check round lagging behind", fields...)
+		return
+	}
+
+	vc.logger.Info("finished vacancy check round", fields...)
+}
 
-	for _, user := range users {
-		if err := vc.checkVacanciesForUser(dbCtx, &user); err != nil {
-			vc.logger.Error("failed to check vacancies for user",
+// userLockSlack covers the work that outlives the user timeout: queuing notifications
+// already found and bookkeeping
+const userLockSlack = time.Minute
+
+// runUserCheck checks one user under its own timeout and records the outcome.
+// A per-user lock keeps a replica that just lost the lease from checking the same user as the new leader.
+func (vc *VacancyChecker) runUserCheck(ctx context.Context, round *checkRound, user *models.User) {
+	stats := &round.stats
+
+	lockKey := redis.UserCheckLockKey(user.ID)
+	lockCtx, cancelLock := context.WithTimeout(ctx, 2*time.Second)
+	locked, err := vc.cache.AcquireLock(lockCtx, lockKey, round.lockToken, vc.config.CheckUserTimeout+userLockSlack)
+	cancelLock()
+	if err != nil || !locked {
+		// counted as processed so the round does not look lagging, the user stays due
+		stats.processed.Add(1)
+		stats.skipped.Add(1)
+		if err != nil {
+			vc.logger.Error("failed to lock user check",
+				zap.Int64("user_id", user.ID),
+				zap.Error(err),
+			)
+		} else {
+			vc.logger.Debug("user is being checked elsewhere, skipping", zap.Int64("user_id", user.ID))
+		}
+		return
+	}
+	defer vc.releaseUserLock(lockKey, round.lockToken)
+
+	userCtx, cancel := context.WithTimeout(round.ctx, vc.config.CheckUserTimeout)
+	err = vc.checkVacanciesForUser(userCtx, user, round.shared)
+	cancel()
+
+	stats.processed.Add(1)
+
+	// bookkeeping outlives the round deadline so a slow user still gets rescheduled
+	storeCtx, cancelStore := context.WithTimeout(ctx, 10*time.Second)
+	defer cancelStore()
+
+	if err != nil {
+		stats.failed.Add(1)
+
+		if isTransient(err) {
+			vc.deferCheck(storeCtx, user, err)
+			stats.deferred.Add(1)
+		} else if err := vc.store.RescheduleUserCheck(storeCtx, user.ID, vc.nextCheckAt(storeCtx, user)); err != nil {
+			vc.logger.Error("failed to reschedule user check",
 				zap.Int64("user_id", user.ID),
 				zap.Error(err),
 			)
-			continue
 		}
 
-		if err := vc.store.UpdateLastCheck(dbCtx, user.ID); err != nil {
-			vc.logger.Error("failed to update last check",
+		// the rest of the round would hit the same limit
+		if isRateLimited(err) {
+			vc.logger.Warn("HH API rate limited, stopping check round",
 				zap.Int64("user_id", user.ID),
 				zap.Error(err),
 			)
+			round.stop()
+			return
 		}
 
-		time.Sleep(2 * time.Second)
+		vc.logger.Error("failed to check vacancies for user",
+			zap.Int64("user_id", user.ID),
+			zap.Error(err),
+		)
+		return
 	}
 
-	vc.logger.Info("finished vacancy check for all users")
+	if err := vc.store.UpdateLastCheck(storeCtx, user.ID, vc.nextCheckAt(storeCtx, user)); err != nil {
+		vc.logger.Error("failed to update last check",
+			zap.Int64("user_id", user.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// nextCheckAt is the user's next scheduled run or, without a schedule, the moment their earliest search is due.
+// A search that failed keeps its old last_check and makes the user due right away, as before.
+func (vc *VacancyChecker) nextCheckAt(ctx context.Context, user *models.User) time.Time {
+	now := time.Now()
+
+	if user.CheckSchedule != nil {
+		schedule, err := cron.Parse(*user.CheckSchedule)
+		if err == nil {
+			var next time.Time
+			if next, err = schedule.Next(now.In(user.Location())); err == nil {
+				return next.In(now.Location())
+			}
+		}
+		vc.logger.Warn("invalid check schedule, falling back to search intervals",
+			zap.Int64("user_id", user.ID),
+			zap.String("schedule", *user.CheckSchedule),
+			zap.Error(err),
+		)
+	}
+
+	next := now.Add(time.Duration(user.NotifyInterval) * time.Minute)
+
+	searches, err := vc.store.GetUserSearches(ctx, user.ID)
+	if err != nil {
+		return next
+	}
+
+	for i := range searches {
+		due := now
+		if searches[i].LastCheck != nil {
+			due = searches[i].LastCheck.Add(time.Duration(searches[i].NotifyInterval) * time.Minute)
+		}
+		if due.Before(next) {
+			next = due
+		}
+	}
+
+	return next
+}
+
+func (vc *VacancyChecker) releaseUserLock(key, token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := vc.cache.ReleaseLock(ctx, key, token); err != nil {
+		vc.logger.Warn("failed to release user check lock",
+			zap.String("key", key),
+			zap.Error(err),
+		)
+	}
 }
 
-func (vc *VacancyChecker) checkVacanciesForUser(ctx context.Context, user *models.User) error {
+func (vc *VacancyChecker) logRoundProgress(done <-chan struct{}, started time.Time, due int, stats *roundStats) {
+	ticker := time.NewTicker(progressLogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			processed := stats.processed.Load()
+			vc.logger.Info("vacancy check round progress",
+				zap.Int("due", due),
+				zap.Int64("processed", processed),
+				zap.Int64("remaining", int64(due)-processed),
+				zap.Int64("failed", stats.failed.Load()),
+				zap.Duration("elapsed", time.Since(started)),
+			)
+		}
+	}
+}
+
+func (vc *VacancyChecker) checkVacanciesForUser(ctx context.Context, user *models.User, shared *sharedSearches) error {
 	vc.logger.Debug("checking vacancies for user", zap.Int64("user_id", user.ID))
 
-	filtersMap, err := vc.store.GetFiltersMap(ctx, user.ID)
+	searches, err := vc.searchesToCheck(ctx, user)
+	if err != nil {
+		return fmt.Errorf("get due searches: %w", err)
+	}
+
+	var failed int
+	var transientErr error
+	for i := range searches {
+		search := &searches[i]
+
+		err := vc.checkSearch(ctx, user, search, shared)
+		if isRateLimited(err) {
+			return fmt.Errorf("check search %d: %w", search.ID, err)
+		}
+		if errors.Is(err, headhunter.ErrBadRequest) {
+			// the query itself is broken, retrying on the next tick will not help
+			vc.notifyInvalidQuery(ctx, user, search, err)
+			err = nil
+		}
+		if err != nil {
+			vc.logger.Error("failed to check search",
+				zap.Int64("user_id", user.ID),
+				zap.Int64("search_id", search.ID),
+				zap.Error(err),
+			)
+			failed++
+			if transientErr == nil && isTransient(err) {
+				transientErr = err
+			}
+			continue
+		}
+
+		if err := vc.store.UpdateSearchLastCheck(ctx, search.ID); err != nil {
+			vc.logger.Error("failed to update search last check",
+				zap.Int64("search_id", search.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	if transientErr != nil {
+		return fmt.Errorf("%d of %d searches failed: %w", failed, len(searches), transientErr)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d searches failed", failed, len(searches))
+	}
+
+	return nil
+}
+
+// searchesToCheck returns the user's due searches; a schedule replaces per-search intervals, so then every search is due
+func (vc *VacancyChecker) searchesToCheck(ctx context.Context, user *models.User) ([]models.Search, error) {
+	if user.CheckSchedule != nil {
+		return vc.store.GetUserSearches(ctx, user.ID)
+	}
+	return vc.store.GetDueSearches(ctx, user.ID)
+}
+
+// planSharedSearches tells the round how far back each distinct query must be read for the due users.
+// A search it misses still works, it just fetches on its own if it reaches further back.
+func (vc *VacancyChecker) planSharedSearches(ctx context.Context, shared *sharedSearches, users []models.User) {
+	planCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	planned := 0
+	for i := range users {
+		searches, err := vc.searchesToCheck(planCtx, &users[i])
+		if err != nil {
+			vc.logger.Warn("failed to plan shared searches, the rest fetch on their own",
+				zap.Int64("user_id", users[i].ID),
+				zap.Int("planned", planned),
+				zap.Error(err),
+			)
+			return
+		}
+
+		for j := range searches {
+			search := &searches[j]
+
+			filtersMap, err := vc.store.GetSearchFiltersMap(planCtx, search.ID)
+			if err != nil || len(filtersMap) == 0 {
+				continue
+			}
+			feedURLs, err := vc.store.GetSearchFeedURLs(planCtx, search.ID)
+			if err != nil {
+				continue
+			}
+
+			shared.Plan(searchKey(filtersMap, checkPageSize, feedURLs), vc.checkSince(search, filtersMap))
+			planned++
+		}
+	}
+}
+
+const (
+	checkRetryBaseDelay = time.Minute
+	checkRetryMaxDelay  = 2 * time.Hour
+)
+
+// deferCheck reschedules a user whose check hit a transient failure.
+// Failed searches keep their last_check, so they are rechecked once next_check_at passes.
+func (vc *VacancyChecker) deferCheck(ctx context.Context, user *models.User, cause error) {
+	failures := user.ConsecutiveFailures + 1
+	delay := checkRetryDelay(failures)
+
+	if retryAfter, ok := headhunter.RetryAfter(cause); ok && retryAfter > delay {
+		delay = retryAfter
+	}
+
+	nextCheckAt := time.Now().Add(delay)
+
+	if err := vc.store.DeferUserCheck(ctx, user.ID, nextCheckAt); err != nil {
+		vc.logger.Error("failed to defer user check",
+			zap.Int64("user_id", user.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	vc.logger.Warn("user check deferred",
+		zap.Int64("user_id", user.ID),
+		zap.Int("consecutive_failures", failures),
+		zap.Duration("delay", delay),
+		zap.Error(cause),
+	)
+}
+
+// checkRetryDelay doubles from checkRetryBaseDelay with each consecutive failure
+func checkRetryDelay(failures int) time.Duration {
+	delay := checkRetryBaseDelay
+	for i := 1; i < failures && delay < checkRetryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > checkRetryMaxDelay {
+		delay = checkRetryMaxDelay
+	}
+
+	return delay
+}
+
+func isRateLimited(err error) bool {
+	return errors.Is(err, headhunter.ErrRateLimited) || errors.Is(err, middleware.ErrHHAPIBudgetExhausted)
+}
+
+// isTransient reports failures that deserve a retry with backoff rather than a skipped interval
+func isTransient(err error) bool {
+	return isRateLimited(err) || headhunter.IsTemporary(err)
+}
+
+func (vc *VacancyChecker) checkSearch(ctx context.Context, user *models.User, search *models.Search, shared *sharedSearches) error {
+	filtersMap, err := vc.store.GetSearchFiltersMap(ctx, search.ID)
 	if err != nil {
 		return fmt.Errorf("get filters: %w", err)
 	}
 
 	if len(filtersMap) == 0 {
-		vc.logger.Debug("user has no filters", zap.Int64("user_id", user.ID))
+		vc.logger.Debug("search has no filters",
+			zap.Int64("user_id", user.ID),
+			zap.Int64("search_id", search.ID),
+		)
 		return nil
 	}
 
-	if err := middleware.CheckHHAPIRateLimit(vc.cache, vc.logger); err != nil {
-		vc.logger.Warn("HH API rate limit, skipping user", zap.Int64("user_id", user.ID))
-		return nil
+	feedURLs, err := vc.store.GetSearchFeedURLs(ctx, search.ID)
+	if err != nil {
+		vc.logger.Warn("failed to get search feeds, using hh.ru only",
+			zap.Int64("search_id", search.ID),
+			zap.Error(err),
+		)
 	}
 
-	searchParams := buildSearchParams(filtersMap)
-
-	searchParams.PerPage = vc.config.MaxVacanciesPerCheck
-
-	response, err := vc.hhClient.SearchVacancies(ctx, searchParams)
+	since := vc.checkSince(search, filtersMap)
+	key := searchKey(filtersMap, checkPageSize, feedURLs)
+	response, err := shared.Do(ctx, key, since, func(from time.Time) (*source.Result, error) {
+		return vc.fetchNewest(ctx, filtersMap, feedURLs, from)
+	})
 	if err != nil {
 		return fmt.Errorf("search vacancies: %w", err)
 	}
 
-	if len(response.Items) == 0 {
-		vc.logger.Debug("no vacancies found", zap.Int64("user_id", user.ID))
+	// the items are shared with other searches, filter into a new slice
+	items := query.ApplyLocalFilters(response.Items, filtersMap)
+
+	if len(items) == 0 {
+		vc.logger.Debug("no vacancies found",
+			zap.Int64("user_id", user.ID),
+			zap.Int64("search_id", search.ID),
+		)
 		return nil
 	}
 
-	vacancyIDs := headhunter.ExtractVacancyIDs(response)
+	vacancyIDs := source.CacheIDs(items)
 	unseenIDs, err := vc.store.GetUnseenVacancies(ctx, user.ID, vacancyIDs)
 	if err != nil {
 		return fmt.Errorf("get unseen vacancies: %w", err)
 	}
 
 	if len(unseenIDs) == 0 {
-		vc.logger.Debug("no new vacancies", zap.Int64("user_id", user.ID))
+		vc.logger.Debug("no new vacancies",
+			zap.Int64("user_id", user.ID),
+			zap.Int64("search_id", search.ID),
+		)
 		return nil
 	}
 
-	var newVacancies []headhunter.VacancyItem
+	var newVacancies []source.Vacancy
 	unseenMap := make(map[string]bool)
 	for _, id := range unseenIDs {
 		unseenMap[id] = true
 	}
 
-	for _, vacancy := range response.Items {
-		if unseenMap[vacancy.ID] {
+	for _, vacancy := range items {
+		if unseenMap[vacancy.CacheID()] {
 			newVacancies = append(newVacancies, vacancy)
 		}
 	}
 
-	if err := vc.sendNotifications(ctx, user.ID, newVacancies); err != nil {
-		return fmt.Errorf("send notifications: %w", err)
+	// digests take everything that was read; cards are capped and the rest is linked
+	toSend := newVacancies
+	if !user.WantsDigest() && len(toSend) > vc.config.MaxVacanciesPerCheck {
+		toSend = toSend[:vc.config.MaxVacanciesPerCheck]
 	}
 
-	go vc.cacheVacancies(newVacancies)
+	var more *morePayload
+	unread := response.Unread()
+	if extra := len(newVacancies) - len(toSend) + unread; extra > 0 && !user.WantsDigest() {
+		more = &morePayload{
+			SearchName:  search.Name,
+			Count:       extra,
+			Approximate: unread > 0,
+			URL:         moreVacanciesURL(filtersMap, since),
+		}
+	}
 
-	go vc.markVacanciesAsSeen(user.ID, newVacancies)
+	if err := vc.enqueueNotifications(ctx, user, search, toSend, more); err != nil {
+		return fmt.Errorf("enqueue notifications: %w", err)
+	}
 
-	vc.logger.Info("sent new vacancies to user",
+	vc.logger.Info("queued new vacancies for user",
 		zap.Int64("user_id", user.ID),
-		zap.Int("count", len(newVacancies)),
+		zap.Int64("search_id", search.ID),
+		zap.Int("count", len(toSend)),
+		zap.Int("not_sent", len(newVacancies)-len(toSend)),
+		zap.Int("unread", unread),
 	)
 
 	return nil
 }
 
-func (vc *VacancyChecker) sendNotifications(ctx context.Context, userID int64, vacancies []headhunter.VacancyItem) error {
-	recipient := &tele.User{ID: userID}
+const (
+	checkPageSize = 50
+	// sinceGranularity rounds the window start down, so a query shared by many searches is asked the same way
+	sinceGranularity = 15 * time.Minute
+)
 
-	summaryMsg := fmt.Sprintf(
-		"🔔 *Новые вакансии\\!*\n\nНайдено новых вакансий: %d\n\n",
-		len(vacancies),
-	)
+// checkSince is where a background check starts reading: the last check minus the overlap,
+// never further back than the search period
+func (vc *VacancyChecker) checkSince(search *models.Search, filters map[string]string) time.Time {
+	days := query.BuildSearchParams(filters).PublishedWithinDays
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
 
-	if _, err := vc.bot.Send(recipient, summaryMsg, tele.ModeMarkdownV2); err != nil {
-		return fmt.Errorf("send summary: %w", err)
+	if search.LastCheck != nil {
+		if from := search.LastCheck.Add(-vc.config.CheckOverlap); from.After(since) {
+			since = from
+		}
 	}
 
-	for i, vacancy := range vacancies {
-		message := utils.FormatVacancy(&vacancy)
-		keyboard := utils.InlineVacancyKeyboard(vacancy.AlternateURL)
+	return since.Truncate(sinceGranularity)
+}
 
-		if _, err := vc.bot.Send(recipient, message, keyboard, tele.ModeMarkdownV2); err != nil {
-			vc.logger.Error("failed to send vacancy notification",
-				zap.Int64("user_id", userID),
-				zap.String("vacancy_id", vacancy.ID),
+// fetchNewest reads result pages newest first until they run out or the page budget is spent.
+// A failure after the first page keeps what was read; the unread rest is only counted.
+func (vc *VacancyChecker) fetchNewest(ctx context.Context, filters map[string]string, feedURLs []string, since time.Time) (*source.Result, error) {
+	src := vc.sources.ForSearch(feedURLs)
+
+	var result *source.Result
+	for page := 0; page < vc.config.CheckPageBudget; page++ {
+		err := vc.limiter.Wait(ctx, middleware.HHBudgetBackground)
+
+		var res *source.Result
+		if err == nil {
+			res, err = src.Search(ctx, source.Query{
+				Filters: filters,
+				Page:    page,
+				PerPage: checkPageSize,
+				Since:   since,
+			})
+		}
+		if err != nil {
+			if result == nil {
+				return nil, err
+			}
+			vc.logger.Warn("failed to read next result page, keeping earlier pages",
+				zap.Int("page", page),
 				zap.Error(err),
 			)
-			continue
+			break
+		}
+
+		if result == nil {
+			result = res
+		} else {
+			result.Items = append(result.Items, res.Items...)
+			result.Page = res.Page
 		}
 
-		if i < len(vacancies)-1 {
-			time.Sleep(500 * time.Millisecond)
+		if len(res.Items) == 0 || page >= res.Pages-1 {
+			break
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
-func (vc *VacancyChecker) cacheVacancies(vacancies []headhunter.VacancyItem) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	for _, item := range vacancies {
-		vacancy := convertToDBVacancy(&item)
+// moreVacanciesURL links the hh.ru results the check did not send, newest first
+func moreVacanciesURL(filters map[string]string, since time.Time) string {
+	params := query.BuildSearchParams(filters)
+	params.OrderBy = headhunter.OrderByPublicationTime
 
-		if err := vc.store.CacheVacancy(ctx, vacancy); err != nil {
-			vc.logger.Error("failed to cache vacancy",
-				zap.String("vacancy_id", vacancy.ID),
-				zap.Error(err),
-			)
-		}
+	days := int(time.Since(since).Hours()/24) + 1
+	if days < params.PublishedWithinDays {
+		params.PublishedWithinDays = days
 	}
+
+	return headhunter.SearchPageURL(params)
 }
 
-func (vc *VacancyChecker) markVacanciesAsSeen(userID int64, vacancies []headhunter.VacancyItem) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+const invalidQueryNoticeTTL = 24 * time.Hour
 
-	for _, vacancy := range vacancies {
-		if err := vc.store.MarkVacancyAsSeen(ctx, userID, vacancy.ID); err != nil {
-			vc.logger.Error("failed to mark vacancy as seen",
-				zap.Int64("user_id", userID),
-				zap.String("vacancy_id", vacancy.ID),
-				zap.Error(err),
-			)
-		}
+// notifyInvalidQuery tells the user HH rejected the search, at most once a day per search.
+// During quiet hours the notice waits for a check after them.
+func (vc *VacancyChecker) notifyInvalidQuery(ctx context.Context, user *models.User, search *models.Search, err error) {
+	userID := user.ID
+
+	vc.logger.Warn("HH rejected search query",
+		zap.Int64("user_id", userID),
+		zap.Int64("search_id", search.ID),
+		zap.Error(err),
+	)
+
+	if _, quiet := user.QuietUntil(time.Now()); quiet {
+		return
 	}
-}
 
-func buildSearchParams(filters map[string]string) headhunter.VacancySearchParams {
-	params := headhunter.VacancySearchParams{
-		Page:                0,
-		PerPage:             20,
-		PublishedWithinDays: models.DefaultPublishedWithinDays,
+	noticeKey := fmt.Sprintf("invalid_query_notice:%d", search.ID)
+
+	var notified bool
+	if cacheErr := vc.cache.GetTempData(ctx, userID, noticeKey, &notified); cacheErr == nil && notified {
+		return
 	}
 
-	if text, ok := filters[models.FilterTypeText]; ok {
-		params.Text = text
+	message := fmt.Sprintf("⚠️ Поиск «%s» не проверяется.\n\n%s", search.Name, utils.FormatInvalidQueryMessage(err))
+	if _, sendErr := vc.sender.Send(ctx, sender.Background, &tele.User{ID: userID}, message); sendErr != nil {
+		if reason, ok := sender.Unreachable(sendErr); ok {
+			deactivateUser(ctx, vc.store, vc.logger, userID, reason)
+			return
+		}
+		vc.logger.Error("failed to send invalid query notice",
+			zap.Int64("user_id", userID),
+			zap.Error(sendErr),
+		)
+		return
 	}
 
-	if area, ok := filters[models.FilterTypeArea]; ok {
-		params.Area = area
+	if cacheErr := vc.cache.SetTempData(ctx, userID, noticeKey, true, invalidQueryNoticeTTL); cacheErr != nil {
+		vc.logger.Warn("failed to remember invalid query notice",
+			zap.Int64("user_id", userID),
+			zap.Error(cacheErr),
+		)
 	}
+}
 
-	if salary, ok := filters[models.FilterTypeSalary]; ok {
-		if s, err := strconv.Atoi(salary); err == nil {
-			params.Salary = s
-		}
+// enqueueNotifications marks the vacancies seen and queues their messages in one transaction;
+// the Dispatcher delivers them. During quiet hours they are held until the window ends
+// and go out together under a single header. Digest users get them in their next digest.
+// more, when set, follows the cards with a link to the vacancies that were not sent.
+func (vc *VacancyChecker) enqueueNotifications(ctx context.Context, user *models.User, search *models.Search, vacancies []source.Vacancy, more *morePayload) error {
+	if user.WantsDigest() {
+		return vc.holdForDigest(ctx, user, search, vacancies)
 	}
 
-	if exp, ok := filters[models.FilterTypeExperience]; ok {
-		params.Experience = exp
+	searchID := search.ID
+	summary := &models.Notification{
+		SearchID: &searchID,
+		Kind:     models.NotificationKindSummary,
 	}
 
-	if schedule, ok := filters[models.FilterTypeSchedule]; ok {
-		params.Schedule = schedule
+	var sendAt time.Time
+	if until, quiet := user.QuietUntil(time.Now()); quiet {
+		sendAt = until
+		summary.Kind = models.NotificationKindHeldSummary
 	}
 
-	days := params.PublishedWithinDays
-	if raw, ok := filters[models.FilterTypePublishedWithin]; ok {
-		if parsed, err := strconv.Atoi(raw); err == nil {
-			if parsed < models.MinPublishedWithinDays {
-				parsed = models.MinPublishedWithinDays
-			}
-			if parsed > models.MaxPublishedWithinDays {
-				parsed = models.MaxPublishedWithinDays
-			}
-			days = parsed
-		}
+	payload, err := json.Marshal(summaryPayload{SearchName: search.Name, Count: len(vacancies)})
+	if err != nil {
+		return fmt.Errorf("encode summary: %w", err)
 	}
+	summary.Payload = payload
+	summary.NextAttemptAt = sendAt
 
-	params.PublishedWithinDays = days
+	records := make([]*models.Vacancy, 0, len(vacancies))
+	notifications := []*models.Notification{summary}
 
-	now := time.Now()
-	dateTo := now
-	from := now.Add(-time.Duration(days) * 24 * time.Hour)
-	params.DateTo = &dateTo
-	params.DateFrom = &from
+	for _, vacancy := range vacancies {
+		payload, err := json.Marshal(vacancyPayload{
+			SearchID:   search.ID,
+			SearchName: search.Name,
+			Vacancy:    vacancy,
+		})
+		if err != nil {
+			return fmt.Errorf("encode vacancy %s: %w", vacancy.CacheID(), err)
+		}
+
+		record := vacancy.CacheRecord()
+		records = append(records, record)
+		notifications = append(notifications, &models.Notification{
+			SearchID:      &searchID,
+			VacancyID:     &record.ID,
+			Kind:          models.NotificationKindVacancy,
+			Payload:       payload,
+			NextAttemptAt: sendAt,
+		})
+	}
+
+	if more != nil {
+		payload, err := json.Marshal(more)
+		if err != nil {
+			return fmt.Errorf("encode more: %w", err)
+		}
+		notifications = append(notifications, &models.Notification{
+			SearchID:      &searchID,
+			Kind:          models.NotificationKindMore,
+			Payload:       payload,
+			NextAttemptAt: sendAt,
+		})
+	}
 
-	return params
+	return vc.store.EnqueueVacancyNotifications(ctx, user.ID, records, notifications)
 }
 
-func convertToDBVacancy(item *headhunter.VacancyItem) *models.Vacancy {
-	vacancy := &models.Vacancy{
-		ID:          item.ID,
-		Title:       item.Name,
-		Area:        item.Area.Name,
-		AreaID:      item.Area.ID,
-		URL:         item.AlternateURL,
-		PublishedAt: item.PublishedAt.Time,
+// holdForDigest marks the vacancies seen and keeps them until enqueueDueDigests sends the digest
+func (vc *VacancyChecker) holdForDigest(ctx context.Context, user *models.User, search *models.Search, vacancies []source.Vacancy) error {
+	searchID := search.ID
+	records := make([]*models.Vacancy, 0, len(vacancies))
+	notifications := make([]*models.Notification, 0, len(vacancies))
+
+	for _, vacancy := range vacancies {
+		payload, err := json.Marshal(vacancyPayload{
+			SearchID:   search.ID,
+			SearchName: search.Name,
+			Vacancy:    vacancy,
+		})
+		if err != nil {
+			return fmt.Errorf("encode vacancy %s: %w", vacancy.CacheID(), err)
+		}
+
+		record := vacancy.CacheRecord()
+		records = append(records, record)
+		notifications = append(notifications, &models.Notification{
+			SearchID:  &searchID,
+			VacancyID: &record.ID,
+			Kind:      models.NotificationKindDigestItem,
+			Payload:   payload,
+			Status:    models.NotificationHeld,
+		})
 	}
 
-	if item.Employer.Name != "" {
-		vacancy.Company = &item.Employer.Name
+	return vc.store.EnqueueVacancyNotifications(ctx, user.ID, records, notifications)
+}
+
+// enqueueDueDigests queues a digest for every user whose digest time has come
+func (vc *VacancyChecker) enqueueDueDigests(ctx context.Context) {
+	dbCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	users, err := vc.store.GetUsersWithHeldVacancies(dbCtx)
+	if err != nil {
+		vc.logger.Error("failed to get users with held vacancies, digests wait for the next round", zap.Error(err))
+		return
 	}
 
-	if item.Salary != nil {
-		vacancy.SalaryFrom = item.Salary.From
-		vacancy.SalaryTo = item.Salary.To
-		vacancy.Currency = &item.Salary.Currency
+	now := time.Now()
+	due, queued, failed := 0, 0, 0
+	for i := range users {
+		user := &users[i]
+		if !user.DigestDue(now) {
+			continue
+		}
+		due++
+
+		ok, err := vc.store.EnqueueDigest(dbCtx, user.ID)
+		if err != nil {
+			vc.logger.Error("failed to queue digest",
+				zap.Int64("user_id", user.ID),
+				zap.Error(err),
+			)
+			failed++
+			continue
+		}
+		if ok {
+			queued++
+		}
 	}
 
-	if item.Experience != nil {
-		vacancy.Experience = &item.Experience.Name
+	if failed > 0 {
+		vc.logger.Warn("some digests were not queued, retrying next round",
+			zap.Int("due", due),
+			zap.Int("queued", queued),
+			zap.Int("failed", failed),
+		)
+	} else if queued > 0 {
+		vc.logger.Info("queued digests", zap.Int("count", queued))
 	}
+}
+
+// applicationReminderBatch caps the reminders queued per round, the rest wait for the next tick
+const applicationReminderBatch = 500
+
+// enqueueApplicationReminders queues follow-up reminders for applications left without a reply.
+// Reminders that fall into quiet hours are sent when they end.
+func (vc *VacancyChecker) enqueueApplicationReminders(ctx context.Context) {
+	dbCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
 
-	if item.Schedule != nil {
-		vacancy.Schedule = &item.Schedule.Name
+	applications, err := vc.store.GetDueApplicationReminders(dbCtx, applicationReminderBatch)
+	if err != nil {
+		vc.logger.Error("failed to get due application reminders, reminders wait for the next round", zap.Error(err))
+		return
 	}
 
-	if item.Employment != nil {
-		vacancy.Employment = &item.Employment.Name
+	now := time.Now()
+	users := make(map[int64]*models.User)
+	queued, failed := 0, 0
+	for i := range applications {
+		application := &applications[i]
+
+		user, ok := users[application.UserID]
+		if !ok {
+			user, err = vc.store.GetUser(dbCtx, application.UserID)
+			if err != nil {
+				vc.logger.Error("failed to get user for application reminder",
+					zap.Int64("user_id", application.UserID),
+					zap.Int64("application_id", application.ID),
+					zap.Error(err),
+				)
+				failed++
+				continue
+			}
+			users[application.UserID] = user
+		}
+
+		sendAt := now
+		if user != nil {
+			if until, quiet := user.QuietUntil(now); quiet {
+				sendAt = until
+			}
+		}
+
+		enqueued, err := vc.store.EnqueueApplicationReminder(dbCtx, application, sendAt)
+		if err != nil {
+			vc.logger.Error("failed to queue application reminder",
+				zap.Int64("user_id", application.UserID),
+				zap.Int64("application_id", application.ID),
+				zap.Error(err),
+			)
+			failed++
+			continue
+		}
+		if enqueued {
+			queued++
+		}
 	}
 
-	return vacancy
+	if failed > 0 {
+		vc.logger.Warn("some application reminders were not queued, retrying next round",
+			zap.Int("due", len(applications)),
+			zap.Int("queued", queued),
+			zap.Int("failed", failed),
+		)
+	} else if queued > 0 {
+		vc.logger.Info("queued application reminders", zap.Int("count", queued))
+	}
 }
@@ -0,0 +1,340 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/sender"
+	"hh-vacancy-bot/internal/bot/utils"
+	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
+	"hh-vacancy-bot/internal/storage/postgres"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	dispatchInterval    = 5 * time.Second
+	dispatchBatchSize   = 50
+	dispatchClaimLease  = 2 * time.Minute
+	dispatchMaxAttempts = 6
+	dispatchBaseDelay   = 30 * time.Second
+	dispatchMaxDelay    = time.Hour
+)
+
+// summaryPayload is the header sent before the vacancy cards of one search
+type summaryPayload struct {
+	SearchName string `json:"search_name"`
+	Count      int    `json:"count"`
+}
+
+// morePayload follows the cards when a check found more than it may send
+type morePayload struct {
+	SearchName  string `json:"search_name"`
+	Count       int    `json:"count"`
+	Approximate bool   `json:"approximate"` // includes pages the check did not read
+	URL         string `json:"url"`
+}
+
+type vacancyPayload struct {
+	SearchID   int64          `json:"search_id"`
+	SearchName string         `json:"search_name"`
+	Vacancy    source.Vacancy `json:"vacancy"`
+}
+
+// Dispatcher delivers queued notifications from notification_outbox.
+// Every replica may run one: rows are claimed with SKIP LOCKED, so each is sent by a single dispatcher.
+type Dispatcher struct {
+	sender *sender.Queue
+	store  *postgres.Store
+	logger *zap.Logger
+}
+
+func NewDispatcher(queue *sender.Queue, store *postgres.Store, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		sender: queue,
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+
+	d.logger.Info("notification dispatcher started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info("notification dispatcher stopped")
+			return
+		case <-ticker.C:
+			d.dispatchDue(ctx)
+		}
+	}
+}
+
+// dispatchDue sends claimed batches until nothing is due
+func (d *Dispatcher) dispatchDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		claimCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		notifications, err := d.store.ClaimDueNotifications(claimCtx, dispatchBatchSize, dispatchClaimLease)
+		cancel()
+		if err != nil {
+			return
+		}
+
+		// users are served in parallel, each one's messages in order; the send queue does the pacing
+		var wg sync.WaitGroup
+		for _, batch := range groupByUser(notifications) {
+			wg.Add(1)
+			go func(batch []*models.Notification) {
+				defer wg.Done()
+				for _, n := range batch {
+					d.deliver(ctx, n)
+				}
+			}(batch)
+		}
+		wg.Wait()
+
+		if len(notifications) < dispatchBatchSize {
+			return
+		}
+	}
+}
+
+func groupByUser(notifications []models.Notification) [][]*models.Notification {
+	var batches [][]*models.Notification
+	index := make(map[int64]int)
+
+	for i := range notifications {
+		n := &notifications[i]
+		pos, ok := index[n.UserID]
+		if !ok {
+			pos = len(batches)
+			index[n.UserID] = pos
+			batches = append(batches, nil)
+		}
+		batches[pos] = append(batches[pos], n)
+	}
+
+	return batches
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, n *models.Notification) {
+	// shorter than the claim lease, so a row is never sent while another dispatcher may retry it
+	sendCtx, cancelSend := context.WithTimeout(ctx, dispatchClaimLease/2)
+	sent, err := d.send(sendCtx, n)
+	cancelSend()
+
+	storeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err == nil {
+		if err := d.store.MarkNotificationSent(storeCtx, n.ID, sent.ID); err != nil {
+			// the row is retried after the claim lease, the user may get this message twice
+			d.logger.Error("failed to record sent notification",
+				zap.Int64("notification_id", n.ID),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+
+	if reason, ok := sender.Unreachable(err); ok {
+		deactivateUser(storeCtx, d.store, d.logger, n.UserID, reason)
+		return
+	}
+
+	attempts := n.Attempts + 1
+	if errors.Is(err, errBadNotification) || sender.IsPermanent(err) || attempts >= dispatchMaxAttempts {
+		d.logger.Error("giving up on notification",
+			zap.Int64("notification_id", n.ID),
+			zap.Int64("user_id", n.UserID),
+			zap.Int("attempts", attempts),
+			zap.Error(err),
+		)
+		if dlErr := d.store.DeadLetterNotification(storeCtx, n.ID, err.Error()); dlErr != nil {
+			// the row keeps its claim and is sent again after the lease
+			d.logger.Error("failed to dead-letter notification",
+				zap.Int64("notification_id", n.ID),
+				zap.Error(dlErr),
+			)
+		}
+		return
+	}
+
+	delay := dispatchRetryDelay(attempts)
+	var floodErr tele.FloodError
+	if errors.As(err, &floodErr) {
+		if retryAfter := time.Duration(floodErr.RetryAfter) * time.Second; retryAfter > delay {
+			delay = retryAfter
+		}
+	}
+
+	d.logger.Warn("failed to send notification, will retry",
+		zap.Int64("notification_id", n.ID),
+		zap.Int64("user_id", n.UserID),
+		zap.Int("attempts", attempts),
+		zap.Duration("retry_in", delay),
+		zap.Error(err),
+	)
+	if retryErr := d.store.RetryNotification(storeCtx, n.ID, err.Error(), time.Now().Add(delay)); retryErr != nil {
+		// the row keeps its claim and is retried after the lease instead
+		d.logger.Error("failed to schedule notification retry",
+			zap.Int64("notification_id", n.ID),
+			zap.Error(retryErr),
+		)
+	}
+}
+
+// send renders the notification by kind; malformed payloads are reported as permanent
+func (d *Dispatcher) send(ctx context.Context, n *models.Notification) (*tele.Message, error) {
+	recipient := &tele.User{ID: n.UserID}
+
+	switch n.Kind {
+	case models.NotificationKindSummary:
+		var payload summaryPayload
+		if err := json.Unmarshal(n.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("%w: decode summary: %v", errBadNotification, err)
+		}
+
+		message := fmt.Sprintf(
+			"🔔 *Новые вакансии\\!*\n\nПоиск: *%s*\nНайдено новых вакансий: %d\n\n",
+			utils.EscapeMarkdown(payload.SearchName),
+			payload.Count,
+		)
+		return d.sender.Send(ctx, sender.Background, recipient, message, tele.ModeMarkdownV2)
+	case models.NotificationKindHeldSummary:
+		message := "🌅 *Тихие часы закончились*\n\nВот вакансии, найденные за это время:"
+		return d.sender.Send(ctx, sender.Background, recipient, message, tele.ModeMarkdownV2)
+	case models.NotificationKindMore:
+		var payload morePayload
+		if err := json.Unmarshal(n.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("%w: decode more: %v", errBadNotification, err)
+		}
+
+		count := fmt.Sprintf("ещё %d", payload.Count)
+		if payload.Approximate {
+			count = fmt.Sprintf("ещё около %d", payload.Count)
+		}
+		message := fmt.Sprintf("…и %s новых вакансий по поиску «%s» не поместились в уведомления.", count, payload.SearchName)
+
+		menu := &tele.ReplyMarkup{}
+		menu.Inline(menu.Row(menu.URL("🔗 Смотреть на hh.ru", payload.URL)))
+
+		return d.sender.Send(ctx, sender.Background, recipient, message, menu)
+	case models.NotificationKindDigest:
+		return d.sendDigest(ctx, n)
+	case models.NotificationKindApplicationReminder:
+		return d.sendApplicationReminder(ctx, n)
+	case models.NotificationKindVacancy:
+		var payload vacancyPayload
+		if err := json.Unmarshal(n.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("%w: decode vacancy: %v", errBadNotification, err)
+		}
+
+		message := utils.FormatVacancyNotification(&payload.Vacancy, payload.SearchName)
+		keyboard := utils.InlineVacancyKeyboard(&payload.Vacancy, payload.SearchID)
+		return d.sender.Send(ctx, sender.Background, recipient, message, keyboard, tele.ModeMarkdownV2)
+	}
+
+	return nil, fmt.Errorf("%w: unknown kind %q", errBadNotification, n.Kind)
+}
+
+// sendDigest sends the first page of a digest, the pagination buttons load the rest
+func (d *Dispatcher) sendDigest(ctx context.Context, n *models.Notification) (*tele.Message, error) {
+	user, err := d.store.GetUser(ctx, n.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%w: user %d not found", errBadNotification, n.UserID)
+	}
+
+	vacancies, err := d.store.GetDigestVacancies(ctx, n.UserID, n.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(vacancies) == 0 {
+		return nil, fmt.Errorf("%w: digest has no vacancies left", errBadNotification)
+	}
+
+	pages := utils.FormatDigestPages(vacancies, user.DigestGroup)
+	keyboard := utils.DigestPaginationKeyboard(n.ID, 0, len(pages))
+
+	return d.sender.Send(ctx, sender.Background, &tele.User{ID: n.UserID}, pages[0], keyboard, tele.ModeMarkdownV2, tele.NoPreview)
+}
+
+// sendApplicationReminder asks about an application that has gone without a reply
+func (d *Dispatcher) sendApplicationReminder(ctx context.Context, n *models.Notification) (*tele.Message, error) {
+	var payload models.ApplicationReminderPayload
+	if err := json.Unmarshal(n.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("%w: decode application reminder: %v", errBadNotification, err)
+	}
+
+	application, err := d.store.GetApplication(ctx, n.UserID, payload.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application == nil || !application.AwaitingReply() {
+		return nil, fmt.Errorf("%w: application %d no longer awaits a reply", errBadNotification, payload.ApplicationID)
+	}
+
+	user, err := d.store.GetUser(ctx, n.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%w: user %d not found", errBadNotification, n.UserID)
+	}
+
+	vacancy, err := d.store.GetVacancy(ctx, application.VacancyID)
+	if err != nil {
+		return nil, err
+	}
+
+	days := int(time.Since(application.StatusChangedAt).Hours() / 24)
+	message := fmt.Sprintf(
+		"⏰ *Нет ответа %s — напомнить о себе?*\n\n%s\nЕсли ответ уже пришёл, обновите статус\\.",
+		utils.EscapeMarkdown(utils.FormatDays(days)),
+		utils.FormatApplication(application, vacancy, user.Location()),
+	)
+	keyboard := utils.ApplicationKeyboard(application, 0)
+
+	return d.sender.Send(ctx, sender.Background, &tele.User{ID: n.UserID}, message, keyboard, tele.ModeMarkdownV2, tele.NoPreview)
+}
+
+var errBadNotification = errors.New("bad notification")
+
+func dispatchRetryDelay(attempts int) time.Duration {
+	delay := dispatchBaseDelay
+	for i := 1; i < attempts && delay < dispatchMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > dispatchMaxDelay {
+		delay = dispatchMaxDelay
+	}
+	return delay
+}
+
+// deactivateUser stops checks for a user who blocked the bot; their pending notifications are dropped with it
+func deactivateUser(ctx context.Context, store *postgres.Store, logger *zap.Logger, userID int64, reason string) {
+	logger.Info("user is unreachable, deactivating",
+		zap.Int64("user_id", userID),
+		zap.String("reason", reason),
+	)
+
+	if err := store.DeactivateUser(ctx, userID, reason); err != nil {
+		logger.Error("failed to deactivate unreachable user",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+	}
+}
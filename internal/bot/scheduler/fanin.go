@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/query"
+	"hh-vacancy-bot/internal/source"
+	"hh-vacancy-bot/internal/storage/redis"
+
+	"go.uber.org/zap"
+)
+
+// sharedSearches lets every search with the same query reuse one fetch per round.
+// The fetch starts at the oldest since planned for the query, each search then keeps its own window.
+// Local filters (stop-words, hidden employers, salary cap) run per search afterwards.
+type sharedSearches struct {
+	cache  *redis.Cache
+	ttl    time.Duration
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	calls   map[string]*sharedCall
+	planned map[string]time.Time // oldest since wanted per key
+
+	fetched atomic.Int64
+	reused  atomic.Int64
+}
+
+type sharedCall struct {
+	done   chan struct{}
+	since  time.Time // where the fetch started reading
+	result *source.Result
+	err    error
+}
+
+// sharedEntry is a fetch kept in Redis for other instances, with the window it covers
+type sharedEntry struct {
+	Since  time.Time     `json:"since"`
+	Result source.Result `json:"result"`
+}
+
+func newSharedSearches(cache *redis.Cache, ttl time.Duration, logger *zap.Logger) *sharedSearches {
+	return &sharedSearches{
+		cache:   cache,
+		ttl:     ttl,
+		logger:  logger,
+		calls:   make(map[string]*sharedCall),
+		planned: make(map[string]time.Time),
+	}
+}
+
+// Plan records that a search of the round will read key from since, so the shared fetch reaches back far enough
+func (s *sharedSearches) Plan(key string, since time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if oldest, ok := s.planned[key]; !ok || since.Before(oldest) {
+		s.planned[key] = since
+	}
+}
+
+// searchKey is the canonical HH query plus the feeds aggregated into it.
+// The moving date window is replaced by the period, so equal searches share a key whenever they were last checked.
+func searchKey(filters map[string]string, perPage int, feedURLs []string) string {
+	params := query.BuildSearchParams(filters)
+	params.PerPage = perPage
+	params.DateFrom, params.DateTo = nil, nil
+
+	key := params.CanonicalKey() + ":" + strconv.Itoa(params.PublishedWithinDays)
+
+	if len(feedURLs) == 0 {
+		return key
+	}
+
+	feeds := append([]string(nil), feedURLs...)
+	sort.Strings(feeds)
+	sum := sha1.Sum([]byte(strings.Join(feeds, "\n")))
+
+	return key + ":" + hex.EncodeToString(sum[:6])
+}
+
+// Do returns the result for key from since, calling fetch at most once per round.
+// Other instances reuse it through Redis until ttl passes. The result must be treated as read-only.
+func (s *sharedSearches) Do(ctx context.Context, key string, since time.Time, fetch func(since time.Time) (*source.Result, error)) (*source.Result, error) {
+	s.mu.Lock()
+	call, ok := s.calls[key]
+	if !ok {
+		call = &sharedCall{done: make(chan struct{}), since: since}
+		if oldest, planned := s.planned[key]; planned && oldest.Before(since) {
+			call.since = oldest
+		}
+		s.calls[key] = call
+	}
+	s.mu.Unlock()
+
+	if ok {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if call.err != nil {
+			return nil, call.err
+		}
+		if !call.since.After(since) {
+			s.reused.Add(1)
+			return resultSince(call.result, since), nil
+		}
+
+		// the search was not planned and reaches further back than the shared fetch
+		result, err := fetch(since)
+		if err != nil {
+			return nil, err
+		}
+		s.fetched.Add(1)
+		return result, nil
+	}
+
+	defer close(call.done)
+
+	var cached sharedEntry
+	if err := s.cache.GetSharedSearch(ctx, key, &cached); err == nil && !cached.Since.IsZero() && !cached.Since.After(call.since) {
+		s.reused.Add(1)
+		call.result = &cached.Result
+		return resultSince(call.result, since), nil
+	}
+
+	call.result, call.err = fetch(call.since)
+	if call.err != nil {
+		// let a later search with the same key try again instead of inheriting the failure
+		s.mu.Lock()
+		delete(s.calls, key)
+		s.mu.Unlock()
+		return nil, call.err
+	}
+
+	s.fetched.Add(1)
+
+	entry := sharedEntry{Since: call.since, Result: *call.result}
+	if err := s.cache.SetSharedSearch(ctx, key, entry, s.ttl); err != nil {
+		s.logger.Warn("failed to cache shared search",
+			zap.String("key", key),
+			zap.Error(err),
+		)
+	}
+
+	return resultSince(call.result, since), nil
+}
+
+// resultSince narrows a shared result to the window of one search, into a new slice.
+// Found keeps the hh.ru results the shared fetch left unread only when its pages stopped before reaching since.
+func resultSince(result *source.Result, since time.Time) *source.Result {
+	narrowed := *result
+	narrowed.Items = make([]source.Vacancy, 0, len(result.Items))
+
+	var oldest time.Time
+	read := 0
+	for _, item := range result.Items {
+		if item.Source == source.SourceHH && (oldest.IsZero() || item.PublishedAt.Before(oldest)) {
+			oldest = item.PublishedAt
+		}
+		// undated feed entries are kept, seen tracking stops repeats
+		if item.PublishedAt.IsZero() || !item.PublishedAt.Before(since) {
+			narrowed.Items = append(narrowed.Items, item)
+			if item.Source == source.SourceHH {
+				read++
+			}
+		}
+	}
+
+	narrowed.Found = read
+	if unread := result.Unread(); unread > 0 && oldest.After(since) {
+		narrowed.Found += unread
+	}
+
+	return &narrowed
+}
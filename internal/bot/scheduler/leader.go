@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"hh-vacancy-bot/internal/storage/redis"
+
+	"go.uber.org/zap"
+)
+
+const leaderLockName = "vacancy-checker"
+
+// leaderLease makes exactly one replica run check rounds.
+// The leader renews the lease every ttl/3; when it dies the lease expires and another replica takes over.
+type leaderLease struct {
+	cache  *redis.Cache
+	key    string
+	ttl    time.Duration
+	logger *zap.Logger
+}
+
+func newLeaderLease(cache *redis.Cache, name string, ttl time.Duration, logger *zap.Logger) *leaderLease {
+	return &leaderLease{
+		cache:  cache,
+		key:    redis.LeaderLockKey(name),
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// Acquire blocks until this replica holds the lease or ctx is done.
+// The returned context is cancelled as soon as the lease is lost; release gives the lease up.
+func (l *leaderLease) Acquire(ctx context.Context) (context.Context, func(), error) {
+	token, err := redis.NewLockToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		acquireCtx, cancel := context.WithTimeout(ctx, l.ttl/3)
+		ok, err := l.cache.AcquireLock(acquireCtx, l.key, token, l.ttl)
+		cancel()
+		if err != nil {
+			l.logger.Warn("failed to acquire leader lease", zap.Error(err))
+		}
+		if ok {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	l.logger.Info("acquired leader lease", zap.String("key", l.key))
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go l.keepAlive(leaderCtx, cancel, token, done)
+
+	release := func() {
+		cancel()
+		<-done
+
+		releaseCtx, cancelRelease := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancelRelease()
+
+		if err := l.cache.ReleaseLock(releaseCtx, l.key, token); err != nil {
+			l.logger.Warn("failed to release leader lease", zap.Error(err))
+		}
+	}
+
+	return leaderCtx, release, nil
+}
+
+// keepAlive renews the lease and cancels the leader context once it can no longer be sure it holds it
+func (l *leaderLease) keepAlive(ctx context.Context, cancel context.CancelFunc, token string, done chan<- struct{}) {
+	defer close(done)
+	defer cancel()
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		refreshCtx, cancelRefresh := context.WithTimeout(ctx, l.ttl/3)
+		ok, err := l.cache.RefreshLock(refreshCtx, l.key, token, l.ttl)
+		cancelRefresh()
+
+		switch {
+		case err != nil:
+			// a Redis hiccup is not a lost lease yet, but stop before it may have expired
+			if time.Since(renewed) < l.ttl*2/3 {
+				l.logger.Warn("failed to renew leader lease", zap.Error(err))
+				continue
+			}
+			l.logger.Error("giving up leader lease, renewal keeps failing", zap.Error(err))
+			return
+		case !ok:
+			l.logger.Warn("lost leader lease", zap.String("key", l.key))
+			return
+		}
+
+		renewed = time.Now()
+	}
+}
@@ -0,0 +1,164 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/config"
+	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/storage/postgres"
+	"hh-vacancy-bot/internal/storage/redis"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maintenanceLockName = "maintenance"
+	// maintenanceRunTimeout bounds one run; a step cut short is finished by the next run
+	maintenanceRunTimeout = 15 * time.Minute
+	// maintenanceRetryDelay is how soon to look again when the last run could not be read
+	maintenanceRetryDelay = 5 * time.Minute
+)
+
+// Maintenance prunes old data every MaintenanceInterval on the replica holding its lease.
+// Runs are recorded in maintenance_runs, so a new leader picks up the schedule where the old one left it.
+type Maintenance struct {
+	store  *postgres.Store
+	cache  *redis.Cache
+	config *config.Config
+	logger *zap.Logger
+	lease  *leaderLease
+}
+
+func NewMaintenance(store *postgres.Store, cache *redis.Cache, cfg *config.Config, logger *zap.Logger) *Maintenance {
+	return &Maintenance{
+		store:  store,
+		cache:  cache,
+		config: cfg,
+		logger: logger,
+		lease:  newLeaderLease(cache, maintenanceLockName, cfg.LeaderLeaseTTL, logger),
+	}
+}
+
+func (m *Maintenance) Start(ctx context.Context) {
+	m.logger.Info("maintenance started",
+		zap.Duration("interval", m.config.MaintenanceInterval),
+		zap.Int("vacancy_cache_retention_days", m.config.VacancyCacheRetentionDays),
+		zap.Int("seen_retention_days", m.config.SeenRetentionDays),
+		zap.Int("outbox_retention_days", m.config.OutboxRetentionDays),
+	)
+
+	for {
+		leaderCtx, release, err := m.lease.Acquire(ctx)
+		if err != nil {
+			m.logger.Info("maintenance stopped")
+			return
+		}
+
+		m.runScheduled(leaderCtx)
+		release()
+	}
+}
+
+// runScheduled runs maintenance whenever the interval since the last recorded run has passed
+func (m *Maintenance) runScheduled(ctx context.Context) {
+	for {
+		timer := time.NewTimer(m.untilNextRun(ctx))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		m.run(ctx)
+	}
+}
+
+func (m *Maintenance) untilNextRun(ctx context.Context) time.Duration {
+	dbCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	last, err := m.store.GetLastMaintenanceRun(dbCtx)
+	if err != nil {
+		return maintenanceRetryDelay
+	}
+	if last == nil {
+		return 0
+	}
+
+	wait := time.Until(last.StartedAt.Add(m.config.MaintenanceInterval))
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// run prunes notifications first and seen-marks second, since both keep cached vacancies alive.
+// A failed step is logged and recorded; the others still run.
+func (m *Maintenance) run(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, maintenanceRunTimeout)
+	defer cancel()
+
+	run := &models.MaintenanceRun{StartedAt: time.Now()}
+	var failures []string
+
+	step := func(name string, prune func() (int64, error), deleted *int64) {
+		n, err := prune()
+		*deleted = n
+		if err != nil {
+			m.logger.Error("maintenance step failed",
+				zap.String("step", name),
+				zap.Error(err),
+			)
+			failures = append(failures, name+": "+err.Error())
+		}
+	}
+
+	step("notifications", func() (int64, error) {
+		return m.store.CleanOldNotifications(runCtx, m.config.OutboxRetentionDays)
+	}, &run.NotificationsDeleted)
+
+	step("seen", func() (int64, error) {
+		return m.store.CleanOldSeenVacancies(runCtx, m.config.SeenRetentionDays)
+	}, &run.SeenDeleted)
+
+	step("vacancies", func() (int64, error) {
+		return m.store.CleanOldVacanciesCache(runCtx, m.config.VacancyCacheRetentionDays)
+	}, &run.VacanciesDeleted)
+
+	step("redis", func() (int64, error) {
+		return m.cache.VacuumTempKeys(runCtx)
+	}, &run.RedisKeysDeleted)
+
+	run.FinishedAt = time.Now()
+	if len(failures) > 0 {
+		errText := strings.Join(failures, "; ")
+		run.Error = &errText
+	}
+
+	fields := []zap.Field{
+		zap.Int64("notifications_deleted", run.NotificationsDeleted),
+		zap.Int64("seen_deleted", run.SeenDeleted),
+		zap.Int64("vacancies_deleted", run.VacanciesDeleted),
+		zap.Int64("redis_keys_deleted", run.RedisKeysDeleted),
+		zap.Duration("duration", run.FinishedAt.Sub(run.StartedAt)),
+	}
+
+	// recorded even after the run timed out, otherwise the next leader would start over at once
+	recordCtx, cancelRecord := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelRecord()
+
+	if err := m.store.RecordMaintenanceRun(recordCtx, run); err != nil {
+		m.logger.Error("failed to record maintenance run", zap.Error(err))
+	}
+
+	if run.Error != nil {
+		m.logger.Warn("maintenance run finished with errors", append(fields, zap.String("error", *run.Error))...)
+		return
+	}
+
+	m.logger.Info("maintenance run finished", fields...)
+}
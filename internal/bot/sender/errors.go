@@ -0,0 +1,38 @@
+package sender
+
+import (
+	"errors"
+	"strings"
+
+	"hh-vacancy-bot/internal/models"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// IsPermanent reports failures a retry cannot fix: the user blocked the bot,
+// the chat is gone or Telegram rejected the message itself
+func IsPermanent(err error) bool {
+	var tgErr *tele.Error
+	if errors.As(err, &tgErr) {
+		return tgErr.Code == 400 || tgErr.Code == 403
+	}
+
+	// telebot reports errors it has no constant for as plain "telegram: ... (code)"
+	msg := err.Error()
+	return strings.HasPrefix(msg, "telegram: ") &&
+		(strings.HasSuffix(msg, "(400)") || strings.HasSuffix(msg, "(403)"))
+}
+
+// Unreachable tells whether Telegram will never deliver to the recipient again
+// and returns the models.Deactivated* reason
+func Unreachable(err error) (string, bool) {
+	switch {
+	case errors.Is(err, tele.ErrBlockedByUser):
+		return models.DeactivatedBlocked, true
+	case errors.Is(err, tele.ErrChatNotFound):
+		return models.DeactivatedChatNotFound, true
+	case errors.Is(err, tele.ErrUserIsDeactivated):
+		return models.DeactivatedUserDeleted, true
+	}
+	return "", false
+}
@@ -0,0 +1,342 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"hh-vacancy-bot/internal/storage/redis"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// Priority orders queued messages: interactive replies go before background notifications
+type Priority int
+
+const (
+	Interactive Priority = iota
+	Background
+)
+
+const (
+	workers         = 4
+	maxFloodRetries = 3
+	idleChatsToKeep = 1000
+)
+
+// Limits follow the Bot API guidance: about 30 messages per second overall
+// and about one per second to the same chat
+type Limits struct {
+	PerSecond       float64
+	PerChatInterval time.Duration
+}
+
+type result struct {
+	msg *tele.Message
+	err error
+}
+
+// Call is one Bot API request made on behalf of a queued message
+type Call func(bot *tele.Bot) (*tele.Message, error)
+
+type request struct {
+	ctx      context.Context
+	priority Priority
+	chat     string
+	call     Call
+	unpaced  bool // deletes do not count towards the per-chat interval
+	floods   int
+	taken    bool // a worker dequeued it; guarded by Queue.mu
+	resultCh chan result
+}
+
+type chatState struct {
+	nextAt time.Time
+	busy   bool // a message to the chat is in flight, later ones wait to keep the order
+}
+
+// Queue is the way out to Telegram for everything the bot sends, replies and background messages alike.
+// Workers pick the oldest message of the highest priority whose chat is ready,
+// so one user's long list does not hold up everybody else.
+// The global limit is a Redis token bucket shared by all bot instances.
+type Queue struct {
+	bot    *tele.Bot
+	cache  *redis.Cache
+	limits Limits
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	queues [2][]*request
+	chats  map[string]*chatState
+	wake   chan struct{}
+}
+
+func New(bot *tele.Bot, cache *redis.Cache, limits Limits, logger *zap.Logger) *Queue {
+	return &Queue{
+		bot:    bot,
+		cache:  cache,
+		limits: limits,
+		logger: logger,
+		chats:  make(map[string]*chatState),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Start runs the workers until ctx is done
+func (q *Queue) Start(ctx context.Context) {
+	q.logger.Info("telegram send queue started",
+		zap.Float64("per_second", q.limits.PerSecond),
+		zap.Duration("per_chat_interval", q.limits.PerChatInterval),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// Send queues the message and waits until it is sent, Telegram rejects it or ctx is done.
+// 429s are waited out here; a tele.FloodError is returned only if they keep coming.
+func (q *Queue) Send(ctx context.Context, priority Priority, to tele.Recipient, what interface{}, opts ...interface{}) (*tele.Message, error) {
+	return q.Do(ctx, priority, to, func(bot *tele.Bot) (*tele.Message, error) {
+		return bot.Send(to, what, opts...)
+	})
+}
+
+// Do queues any Bot API call that posts to or changes the chat, such as an edit,
+// so it shares the limits and the per-chat order with sent messages.
+// When ctx is done before a worker picks the call up, it is dropped unsent.
+// Once it is on its way to Telegram, Do waits for the real outcome, so a delivered message is never reported as failed.
+func (q *Queue) Do(ctx context.Context, priority Priority, chat tele.Recipient, call Call) (*tele.Message, error) {
+	return q.enqueue(&request{
+		ctx:      ctx,
+		priority: priority,
+		chat:     chat.Recipient(),
+		call:     call,
+		resultCh: make(chan result, 1),
+	})
+}
+
+// Delete removes a message in order with the chat's other calls, without pausing the chat afterwards
+func (q *Queue) Delete(ctx context.Context, priority Priority, msg *tele.Message) error {
+	_, err := q.enqueue(&request{
+		ctx:      ctx,
+		priority: priority,
+		chat:     msg.Chat.Recipient(),
+		call: func(bot *tele.Bot) (*tele.Message, error) {
+			return nil, bot.Delete(msg)
+		},
+		unpaced:  true,
+		resultCh: make(chan result, 1),
+	})
+	return err
+}
+
+func (q *Queue) enqueue(req *request) (*tele.Message, error) {
+	ctx := req.ctx
+
+	q.mu.Lock()
+	q.queues[req.priority] = append(q.queues[req.priority], req)
+	q.mu.Unlock()
+	q.signal()
+
+	select {
+	case res := <-req.resultCh:
+		return res.msg, res.err
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	taken := req.taken
+	q.mu.Unlock()
+
+	if !taken {
+		// next drops it, its ctx is done
+		return nil, ctx.Err()
+	}
+
+	res := <-req.resultCh
+	return res.msg, res.err
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		req, wait := q.next(time.Now())
+		if req == nil {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-q.wake:
+				timer.Stop()
+			case <-timer.C:
+			}
+			continue
+		}
+
+		if err := q.waitGlobal(ctx, req.ctx); err != nil {
+			q.finish(req, result{err: err}, 0)
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		// the caller gave up while this waited for a token: sending now would deliver what it reports as failed
+		if err := req.ctx.Err(); err != nil {
+			q.finish(req, result{err: err}, 0)
+			continue
+		}
+
+		msg, err := req.call(q.bot)
+
+		var floodErr tele.FloodError
+		if errors.As(err, &floodErr) && req.floods < maxFloodRetries {
+			q.requeue(req, time.Duration(floodErr.RetryAfter)*time.Second)
+			continue
+		}
+
+		pause := q.limits.PerChatInterval
+		if req.unpaced {
+			pause = 0
+		}
+		q.finish(req, result{msg: msg, err: err}, pause)
+	}
+}
+
+// next takes the first ready request, interactive ones first.
+// With nothing ready it says how long to sleep before looking again.
+func (q *Queue) next(now time.Time) (*request, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	wait := time.Second
+	for priority := range q.queues {
+		q.dropAbandoned(priority)
+
+		queue := q.queues[priority]
+		for i, req := range queue {
+			chat := q.chats[req.chat]
+			if chat == nil {
+				chat = &chatState{}
+				q.chats[req.chat] = chat
+			}
+
+			if chat.busy {
+				continue
+			}
+			if chat.nextAt.After(now) {
+				if d := chat.nextAt.Sub(now); d < wait {
+					wait = d
+				}
+				continue
+			}
+
+			chat.busy = true
+			req.taken = true
+			q.queues[priority] = append(queue[:i:i], queue[i+1:]...)
+			return req, 0
+		}
+	}
+
+	if len(q.chats) > idleChatsToKeep {
+		for id, chat := range q.chats {
+			if !chat.busy && !chat.nextAt.After(now) {
+				delete(q.chats, id)
+			}
+		}
+	}
+
+	return nil, wait
+}
+
+// dropAbandoned removes requests whose caller stopped waiting; q.mu is held
+func (q *Queue) dropAbandoned(priority int) {
+	kept := q.queues[priority][:0]
+	for _, req := range q.queues[priority] {
+		if req.ctx.Err() == nil {
+			kept = append(kept, req)
+		}
+	}
+	q.queues[priority] = kept
+}
+
+// requeue puts a flooded request back at the head of its queue and pauses its chat
+func (q *Queue) requeue(req *request, retryAfter time.Duration) {
+	req.floods++
+
+	q.logger.Warn("telegram flood limit hit, pausing chat",
+		zap.String("chat", req.chat),
+		zap.Duration("retry_after", retryAfter),
+	)
+
+	q.mu.Lock()
+	req.taken = false
+	if chat := q.chats[req.chat]; chat != nil {
+		chat.busy = false
+		chat.nextAt = time.Now().Add(retryAfter)
+	}
+	q.queues[req.priority] = append([]*request{req}, q.queues[req.priority]...)
+	q.mu.Unlock()
+}
+
+func (q *Queue) finish(req *request, res result, pause time.Duration) {
+	q.mu.Lock()
+	if chat := q.chats[req.chat]; chat != nil {
+		chat.busy = false
+		chat.nextAt = time.Now().Add(pause)
+	}
+	q.mu.Unlock()
+
+	req.resultCh <- res
+	q.signal()
+}
+
+// waitGlobal takes a token from the shared bucket, failing open when Redis is unavailable.
+// It stops waiting when either the queue or the caller is done.
+func (q *Queue) waitGlobal(ctx, reqCtx context.Context) error {
+	burst := int(q.limits.PerSecond)
+	if burst < 1 {
+		burst = 1
+	}
+
+	for {
+		takeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		allowed, wait, err := q.cache.TakeTelegramToken(takeCtx, q.limits.PerSecond, burst)
+		cancel()
+		if err != nil {
+			q.logger.Error("failed to check telegram rate limit", zap.Error(err))
+			return nil
+		}
+
+		if allowed {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-reqCtx.Done():
+			timer.Stop()
+			return reqCtx.Err()
+		case <-timer.C:
+		}
+	}
+}
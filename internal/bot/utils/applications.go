@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/models"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// ApplicationsPageSize is how many applications one /applications page shows
+const ApplicationsPageSize = 10
+
+var applicationStatusLabels = map[string]string{
+	models.ApplicationApplied:   "📨 Отклик отправлен",
+	models.ApplicationHRScreen:  "💬 HR-скрининг",
+	models.ApplicationInterview: "🗣 Интервью",
+	models.ApplicationOffer:     "🎉 Оффер",
+	models.ApplicationRejected:  "❌ Отказ",
+	models.ApplicationWithdrawn: "↩️ Отозван",
+}
+
+func ApplicationStatusLabel(status string) string {
+	if label, ok := applicationStatusLabels[status]; ok {
+		return label
+	}
+	return status
+}
+
+// SortApplications orders applications by status along the pipeline, keeping the given order within a status
+func SortApplications(applications []models.Application) {
+	rank := make(map[string]int, len(models.ApplicationStatuses))
+	for i, status := range models.ApplicationStatuses {
+		rank[status] = i
+	}
+
+	sort.SliceStable(applications, func(i, j int) bool {
+		return rank[applications[i].Status] < rank[applications[j].Status]
+	})
+}
+
+// FormatApplicationsBoard renders one page of the board, numbered from offset+1 and grouped by status.
+// applications is the whole sorted board; vacancies maps cache ids to the cached vacancies.
+func FormatApplicationsBoard(applications []models.Application, vacancies map[string]models.Vacancy, offset int, loc *time.Location) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("📨 *Отклики:* %d\n", len(applications)))
+
+	counts := make(map[string]int)
+	for _, a := range applications {
+		counts[a.Status]++
+	}
+	var summary []string
+	for _, status := range models.ApplicationStatuses {
+		if counts[status] > 0 {
+			summary = append(summary, fmt.Sprintf("%s: %d", ApplicationStatusLabel(status), counts[status]))
+		}
+	}
+	sb.WriteString(EscapeMarkdown(strings.Join(summary, " · ")) + "\n")
+
+	end := offset + ApplicationsPageSize
+	if end > len(applications) {
+		end = len(applications)
+	}
+
+	status := ""
+	for i := offset; i < end; i++ {
+		a := &applications[i]
+
+		if a.Status != status {
+			status = a.Status
+			sb.WriteString("\n*" + EscapeMarkdown(ApplicationStatusLabel(status)) + "*\n")
+		}
+
+		sb.WriteString(fmt.Sprintf("*%d\\.* %s", i+1, formatApplicationVacancy(vacancies, a.VacancyID)))
+		sb.WriteString(" — _с " + EscapeMarkdown(a.StatusChangedAt.In(loc).Format("02.01")) + "_\n")
+
+		if a.Note != nil {
+			sb.WriteString("   📝 _" + EscapeMarkdown(TruncateString(*a.Note, 60)) + "_\n")
+		}
+	}
+
+	sb.WriteString("\nНажмите номер отклика, чтобы сменить статус или добавить заметку\\.")
+
+	return sb.String()
+}
+
+// formatApplicationVacancy is the vacancy link with its company, or a placeholder when it left the cache
+func formatApplicationVacancy(vacancies map[string]models.Vacancy, vacancyID string) string {
+	v, ok := vacancies[vacancyID]
+	if !ok {
+		return "_вакансия недоступна_"
+	}
+
+	text := fmt.Sprintf("[%s](%s)", EscapeMarkdown(TruncateString(v.Title, 60)), escapeMarkdownURL(v.URL))
+	if v.Company != nil && *v.Company != "" {
+		text += " · " + EscapeMarkdown(TruncateString(*v.Company, 40))
+	}
+	return text
+}
+
+// ApplicationsBoardKeyboard has a numbered button per application on the page plus pagination
+func ApplicationsBoardKeyboard(applications []models.Application, offset, page, totalPages int) *tele.ReplyMarkup {
+	menu := InlinePaginationKeyboard(page, totalPages, "apps_page")
+
+	end := offset + ApplicationsPageSize
+	if end > len(applications) {
+		end = len(applications)
+	}
+
+	var rows [][]tele.InlineButton
+	var row []tele.InlineButton
+	for i := offset; i < end; i++ {
+		data := "app:" + strconv.FormatInt(applications[i].ID, 10) + ":" + strconv.Itoa(page)
+		row = append(row, *menu.Data(strconv.Itoa(i+1), data).Inline())
+		if len(row) == 5 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	menu.InlineKeyboard = append(rows, menu.InlineKeyboard...)
+
+	return menu
+}
+
+// FormatApplication renders one application with its history and reminder
+func FormatApplication(a *models.Application, vacancy *models.Vacancy, loc *time.Location) string {
+	var sb strings.Builder
+
+	vacancies := map[string]models.Vacancy{}
+	if vacancy != nil {
+		vacancies[vacancy.ID] = *vacancy
+	}
+
+	sb.WriteString("📨 " + formatApplicationVacancy(vacancies, a.VacancyID) + "\n\n")
+	sb.WriteString("*Статус:* " + EscapeMarkdown(ApplicationStatusLabel(a.Status)) + "\n")
+	sb.WriteString("*Отклик:* " + EscapeMarkdown(a.AppliedAt.In(loc).Format("02.01.2006")) + "\n")
+	if a.StatusChangedAt.Sub(a.AppliedAt) >= time.Minute {
+		sb.WriteString("*Статус изменён:* " + EscapeMarkdown(a.StatusChangedAt.In(loc).Format("02.01.2006")) + "\n")
+	}
+
+	switch {
+	case a.RemindAt != nil:
+		sb.WriteString("*Напоминание:* " + EscapeMarkdown(a.RemindAt.In(loc).Format("02.01 15:04")) + "\n")
+	case a.AwaitingReply():
+		sb.WriteString("*Напоминание:* выключено\n")
+	}
+
+	if a.Note != nil {
+		sb.WriteString("\n📝 _" + EscapeMarkdown(*a.Note) + "_\n")
+	}
+
+	return sb.String()
+}
+
+// ApplicationKeyboard moves the application to another status and manages its note and reminder.
+// page is the board page to return to.
+func ApplicationKeyboard(a *models.Application, page int) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+	id := strconv.FormatInt(a.ID, 10)
+	back := strconv.Itoa(page)
+
+	var rows []tele.Row
+	var statusButtons []tele.Btn
+	for _, status := range models.ApplicationStatuses {
+		if status == a.Status {
+			continue
+		}
+		statusButtons = append(statusButtons, menu.Data(ApplicationStatusLabel(status), "app_status:"+id+":"+status+":"+back))
+		if len(statusButtons) == 2 {
+			rows = append(rows, menu.Row(statusButtons...))
+			statusButtons = nil
+		}
+	}
+	if len(statusButtons) > 0 {
+		rows = append(rows, menu.Row(statusButtons...))
+	}
+
+	actions := []tele.Btn{menu.Data("📝 Заметка", "app_note:"+id)}
+	if a.AwaitingReply() {
+		if a.RemindAt != nil {
+			actions = append(actions, menu.Data("🔕 Не напоминать", "app_remind:"+id+":off:"+back))
+		} else {
+			actions = append(actions, menu.Data("⏰ Напомнить", "app_remind:"+id+":on:"+back))
+		}
+	}
+	rows = append(rows, menu.Row(actions...))
+
+	rows = append(rows, menu.Row(
+		menu.Data("🗑 Удалить", "app_del:"+id+":"+back),
+		menu.Data("⬅️ К откликам", "apps_page", "goto:"+back),
+	))
+
+	menu.Inline(rows...)
+
+	return menu
+}
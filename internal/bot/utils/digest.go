@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
+)
+
+// digestPageLimit is Telegram's message limit. Text is measured before MarkdownV2
+// is parsed, which only makes it shorter, so a page never gets rejected.
+const digestPageLimit = 4096
+
+type digestGroup struct {
+	name  string
+	lines []string
+}
+
+// FormatDigestPages renders a digest grouped by employer or city and cut into pages
+// that fit one message each
+func FormatDigestPages(vacancies []models.Vacancy, groupBy string) []string {
+	header := fmt.Sprintf("📬 *Дайджест вакансий*\nНовых вакансий: %d\n", len(vacancies))
+
+	var pages []string
+	page := header
+	fits := func(text string) bool {
+		return textLength(page)+textLength(text) <= digestPageLimit
+	}
+
+	for _, group := range groupDigest(vacancies, groupBy) {
+		title := fmt.Sprintf("\n*%s* \\(%d\\)\n", EscapeMarkdown(group.name), len(group.lines))
+
+		// a group cut by the page end continues on the next page under the same title
+		if !fits(title+group.lines[0]) && page != header {
+			pages = append(pages, page)
+			page = header
+		}
+		page += title
+
+		for _, line := range group.lines {
+			if !fits(line) {
+				pages = append(pages, page)
+				page = header + title
+			}
+			page += line
+		}
+	}
+
+	return append(pages, page)
+}
+
+// groupDigest groups vacancies, biggest groups first, keeping the found order within a group
+func groupDigest(vacancies []models.Vacancy, groupBy string) []digestGroup {
+	var groups []digestGroup
+	index := make(map[string]int)
+
+	for i := range vacancies {
+		v := &vacancies[i]
+
+		name, line := digestCompany(v), formatDigestLine(v, v.Area)
+		if groupBy == models.DigestGroupCity {
+			name, line = v.Area, formatDigestLine(v, digestCompany(v))
+			if name == "" {
+				name = "Город не указан"
+			}
+		}
+
+		pos, ok := index[name]
+		if !ok {
+			pos = len(groups)
+			index[name] = pos
+			groups = append(groups, digestGroup{name: name})
+		}
+		groups[pos].lines = append(groups[pos].lines, line)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].lines) > len(groups[j].lines)
+	})
+
+	return groups
+}
+
+func digestCompany(v *models.Vacancy) string {
+	if v.Company == nil || *v.Company == "" {
+		return "Компания не указана"
+	}
+	return *v.Company
+}
+
+// formatDigestLine renders one vacancy as a link with its salary and the detail the group title lacks
+func formatDigestLine(v *models.Vacancy, detail string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("• [%s](%s)", EscapeMarkdown(TruncateString(v.Title, 80)), escapeMarkdownURL(v.URL)))
+
+	if v.SalaryFrom != nil || v.SalaryTo != nil {
+		salary := &source.Salary{From: v.SalaryFrom, To: v.SalaryTo}
+		if v.Currency != nil {
+			salary.Currency = *v.Currency
+		}
+		sb.WriteString(" — " + EscapeMarkdown(FormatSalary(salary)))
+	}
+
+	if detail != "" {
+		sb.WriteString(" · " + EscapeMarkdown(TruncateString(detail, 40)))
+	}
+
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// textLength counts UTF-16 code units, the way Telegram measures messages
+func textLength(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+var weekdayNames = [...]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"}
+
+// FormatDeliveryMode describes how the user gets new vacancies
+func FormatDeliveryMode(user *models.User) string {
+	grouping := "по компаниям"
+	if user.DigestGroup == models.DigestGroupCity {
+		grouping = "по городам"
+	}
+
+	switch user.DeliveryMode {
+	case models.DeliveryDaily:
+		return fmt.Sprintf("дайджест каждый день в %02d:00, %s", user.DigestHour, grouping)
+	case models.DeliveryWeekly:
+		return fmt.Sprintf("дайджест раз в неделю (%s, %02d:00), %s", weekdayNames[user.DigestWeekday], user.DigestHour, grouping)
+	default:
+		return "сразу, по карточке на вакансию"
+	}
+}
@@ -1,98 +1,102 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"hh-vacancy-bot/internal/api/headhunter"
+	"hh-vacancy-bot/internal/bot/query"
 	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
 )
 
 var snippetHighlightReplacer = strings.NewReplacer("<highlighttext>", "", "</highlighttext>", "")
 
 // Format vacancy for Telegram
-func FormatVacancy(vacancy *headhunter.VacancyItem) string {
+func FormatVacancy(vacancy *source.Vacancy) string {
 	var sb strings.Builder
 
 	// Vacancy name in bold
-	sb.WriteString(fmt.Sprintf("*%s*\n\n", EscapeMarkdown(vacancy.Name)))
+	sb.WriteString(fmt.Sprintf("*%s*\n\n", EscapeMarkdown(vacancy.Title)))
+
+	// Feed the vacancy came from, hh.ru is implied
+	if vacancy.Source != source.SourceHH && vacancy.SourceTitle != "" {
+		sb.WriteString(fmt.Sprintf("📡 *Источник:* %s\n", EscapeMarkdown(vacancy.SourceTitle)))
+	}
 
 	// Company
-	if vacancy.Employer.Name != "" {
-		sb.WriteString(fmt.Sprintf("🏢 *Компания:* %s\n", EscapeMarkdown(vacancy.Employer.Name)))
+	if vacancy.Company != "" {
+		sb.WriteString(fmt.Sprintf("🏢 *Компания:* %s\n", EscapeMarkdown(vacancy.Company)))
 	}
 
-	// Paycheck
+	// Paycheck, feeds rarely carry structured salaries
 	if vacancy.Salary != nil {
 		salaryStr := EscapeMarkdown(FormatSalary(vacancy.Salary))
 		sb.WriteString(fmt.Sprintf("💰 *Зарплата:* %s\n", salaryStr))
-	} else {
+	} else if vacancy.Source == source.SourceHH {
 		sb.WriteString("💰 *Зарплата:* не указана\n")
 	}
 
 	// City
-	sb.WriteString(fmt.Sprintf("📍 *Город:* %s\n", EscapeMarkdown(vacancy.Area.Name)))
+	if vacancy.Area != "" {
+		sb.WriteString(fmt.Sprintf("📍 *Город:* %s\n", EscapeMarkdown(vacancy.Area)))
+	}
 
 	// Experience
-	if vacancy.Experience != nil {
-		sb.WriteString(fmt.Sprintf("💼 *Опыт:* %s\n", EscapeMarkdown(vacancy.Experience.Name)))
+	if vacancy.Experience != "" {
+		sb.WriteString(fmt.Sprintf("💼 *Опыт:* %s\n", EscapeMarkdown(vacancy.Experience)))
 	}
 
 	// Hours
-	if vacancy.Schedule != nil {
-		sb.WriteString(fmt.Sprintf("⏰ *График:* %s\n", EscapeMarkdown(vacancy.Schedule.Name)))
+	if vacancy.Schedule != "" {
+		sb.WriteString(fmt.Sprintf("⏰ *График:* %s\n", EscapeMarkdown(vacancy.Schedule)))
 	}
 
 	// Employment type
-	if vacancy.Employment != nil {
-		sb.WriteString(fmt.Sprintf("📋 *Занятость:* %s\n", EscapeMarkdown(vacancy.Employment.Name)))
+	if vacancy.Employment != "" {
+		sb.WriteString(fmt.Sprintf("📋 *Занятость:* %s\n", EscapeMarkdown(vacancy.Employment)))
 	}
 
-	if len(vacancy.ProfessionalRoles) > 0 {
-		roles := make([]string, 0, len(vacancy.ProfessionalRoles))
-		for _, role := range vacancy.ProfessionalRoles {
-			if role.Name != "" {
-				roles = append(roles, role.Name)
-			}
-		}
-		if len(roles) > 0 {
-			sb.WriteString(fmt.Sprintf("🧭 *Профиль:* %s\n", EscapeMarkdown(strings.Join(roles, ", "))))
-		}
+	if len(vacancy.Roles) > 0 {
+		sb.WriteString(fmt.Sprintf("🧭 *Профиль:* %s\n", EscapeMarkdown(strings.Join(vacancy.Roles, ", "))))
 	}
 
-	if vacancy.Snippet != nil {
-		if vacancy.Snippet.Requirement != nil {
-			if requirement := formatSnippetField(*vacancy.Snippet.Requirement); requirement != "" {
-				sb.WriteString(fmt.Sprintf("🗣️ *Требования:* %s\n", EscapeMarkdown(requirement)))
-			}
-		}
-		if vacancy.Snippet.Responsibility != nil {
-			if responsibility := formatSnippetField(*vacancy.Snippet.Responsibility); responsibility != "" {
-				sb.WriteString(fmt.Sprintf("✍️ *Задачи:* %s\n", EscapeMarkdown(responsibility)))
-			}
-		}
+	if requirement := formatSnippetField(vacancy.Requirement); requirement != "" {
+		sb.WriteString(fmt.Sprintf("🗣️ *Требования:* %s\n", EscapeMarkdown(requirement)))
+	}
+	if responsibility := formatSnippetField(vacancy.Responsibility); responsibility != "" {
+		sb.WriteString(fmt.Sprintf("✍️ *Задачи:* %s\n", EscapeMarkdown(responsibility)))
 	}
 
 	// Published date
-	publishedDate := vacancy.PublishedAt.Format("02.01.2006")
-	sb.WriteString(fmt.Sprintf("📅 *Опубликовано:* %s\n", EscapeMarkdown(publishedDate)))
+	if !vacancy.PublishedAt.IsZero() {
+		publishedDate := vacancy.PublishedAt.Format("02.01.2006")
+		sb.WriteString(fmt.Sprintf("📅 *Опубликовано:* %s\n", EscapeMarkdown(publishedDate)))
+	}
 
 	// Link
-	sb.WriteString(fmt.Sprintf("\n🔗 [Открыть вакансию](%s)", escapeMarkdownURL(vacancy.AlternateURL)))
+	if vacancy.URL != "" {
+		sb.WriteString(fmt.Sprintf("\n🔗 [Открыть вакансию](%s)", escapeMarkdownURL(vacancy.URL)))
+	}
 
 	return sb.String()
 }
 
-func FormatSalary(salary *headhunter.Salary) string {
+// FormatVacancyNotification formats a vacancy card labelled with the search it matched
+func FormatVacancyNotification(vacancy *source.Vacancy, searchName string) string {
+	return fmt.Sprintf("🔎 _Поиск: %s_\n\n%s", EscapeMarkdown(searchName), FormatVacancy(vacancy))
+}
+
+func FormatSalary(salary *source.Salary) string {
 	currency := salary.Currency
-	if currency == "RUR" || currency == "RUB" {
-		currency = "₽"
-	} else if currency == "USD" {
-		currency = "$"
-	} else if currency == "EUR" {
-		currency = "€"
+	if currency == "RUB" {
+		currency = "RUR"
 	}
+	currency = models.GetCurrencyAbbr(currency)
 
 	gross := ""
 	if salary.Gross {
@@ -111,24 +115,26 @@ func FormatSalary(salary *headhunter.Salary) string {
 	return "не указана"
 }
 
-func FormatVacancyList(vacancies []headhunter.VacancyItem, total int) string {
+func FormatVacancyList(vacancies []source.Vacancy, total int) string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("📋 *Найдено вакансий:* %d\n", total))
 	sb.WriteString(fmt.Sprintf("*Показано:* %d\n\n", len(vacancies)))
 
 	for i, vacancy := range vacancies {
-		sb.WriteString(fmt.Sprintf("*%d\\. %s*\n", i+1, EscapeMarkdown(vacancy.Name)))
+		sb.WriteString(fmt.Sprintf("*%d\\. %s*\n", i+1, EscapeMarkdown(vacancy.Title)))
 
-		if vacancy.Employer.Name != "" {
-			sb.WriteString(fmt.Sprintf("   🏢 %s\n", EscapeMarkdown(vacancy.Employer.Name)))
+		if vacancy.Company != "" {
+			sb.WriteString(fmt.Sprintf("   🏢 %s\n", EscapeMarkdown(vacancy.Company)))
 		}
 
 		if vacancy.Salary != nil {
 			sb.WriteString(fmt.Sprintf("   💰 %s\n", EscapeMarkdown(FormatSalary(vacancy.Salary))))
 		}
 
-		sb.WriteString(fmt.Sprintf("   📍 %s\n", EscapeMarkdown(vacancy.Area.Name)))
+		if vacancy.Area != "" {
+			sb.WriteString(fmt.Sprintf("   📍 %s\n", EscapeMarkdown(vacancy.Area)))
+		}
 		sb.WriteString("\n")
 	}
 
@@ -149,15 +155,19 @@ func FormatUserFilters(filters map[string]string, cities []headhunter.City) stri
 		sb.WriteString(fmt.Sprintf("🔍 *Текст:* %s\n", EscapeMarkdown(text)))
 	}
 
-	if areaID, ok := filters[models.FilterTypeArea]; ok && areaID != "" {
-		cityName := areaID
-		for _, city := range cities {
-			if city.ID == areaID {
-				cityName = city.Name
-				break
+	if areaValue, ok := filters[models.FilterTypeArea]; ok && areaValue != "" {
+		var names []string
+		for _, areaID := range models.SplitFilterValues(areaValue) {
+			cityName := areaID
+			for _, city := range cities {
+				if city.ID == areaID {
+					cityName = city.Name
+					break
+				}
 			}
+			names = append(names, cityName)
 		}
-		sb.WriteString(fmt.Sprintf("📍 *Город:* %s\n", EscapeMarkdown(cityName)))
+		sb.WriteString(fmt.Sprintf("📍 *Город:* %s\n", EscapeMarkdown(strings.Join(names, ", "))))
 	}
 
 	if salary, ok := filters[models.FilterTypeSalary]; ok && salary != "" {
@@ -165,24 +175,59 @@ func FormatUserFilters(filters map[string]string, cities []headhunter.City) stri
 	}
 
 	if exp, ok := filters[models.FilterTypeExperience]; ok && exp != "" {
-		expName := models.ExperienceDisplayNames[exp]
-		if expName == "" {
-			expName = exp
-		}
+		expName := FormatFilterValue(models.FilterTypeExperience, exp)
 		sb.WriteString(fmt.Sprintf("💼 *Опыт:* %s\n", EscapeMarkdown(expName)))
 	}
 
 	if schedule, ok := filters[models.FilterTypeSchedule]; ok && schedule != "" {
-		scheduleName := models.ScheduleDisplayNames[schedule]
-		if scheduleName == "" {
-			scheduleName = schedule
-		}
+		scheduleName := FormatFilterValue(models.FilterTypeSchedule, schedule)
 		sb.WriteString(fmt.Sprintf("⏰ *График:* %s\n", EscapeMarkdown(scheduleName)))
 	}
 
 	return sb.String()
 }
 
+func FormatExclusionsMessage(searchName string, filters map[string]string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("*🚫 Исключения поиска «%s»*\n\n", EscapeMarkdown(searchName)))
+
+	words := models.SplitFilterValues(filters[models.FilterTypeExcludedWords])
+	if len(words) > 0 {
+		sb.WriteString(fmt.Sprintf("*Стоп\\-слова:* %s\n", EscapeMarkdown(strings.Join(words, ", "))))
+	} else {
+		sb.WriteString("*Стоп\\-слова:* не заданы\n")
+	}
+
+	employers := models.SplitFilterValues(filters[models.FilterTypeExcludedEmployers])
+	sb.WriteString(fmt.Sprintf("*Скрытых работодателей:* %d\n\n", len(employers)))
+
+	sb.WriteString(EscapeMarkdown("Работодателя можно скрыть кнопкой «🚫 Скрыть работодателя» под карточкой вакансии."))
+
+	return sb.String()
+}
+
+func FormatRefineMessage(searchName string, groups []query.FacetGroup) string {
+	if len(groups) == 0 {
+		return "🧩 Уточнять нечего: HH не вернул разбивку для этого поиска\\."
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*🧩 Уточнение поиска «%s»*\n\n", EscapeMarkdown(searchName)))
+
+	for _, group := range groups {
+		parts := make([]string, 0, len(group.Facets))
+		for _, facet := range group.Facets {
+			parts = append(parts, fmt.Sprintf("%s (%d)", facet.Name, facet.Count))
+		}
+		sb.WriteString(fmt.Sprintf("*%s:* %s\n", EscapeMarkdown(group.Name), EscapeMarkdown(strings.Join(parts, ", "))))
+	}
+
+	sb.WriteString("\nНажмите на значение, чтобы добавить его в фильтры\\.")
+
+	return sb.String()
+}
+
 func FormatWelcomeMessage(firstName string) string {
 	name := firstName
 	if name == "" {
@@ -216,6 +261,8 @@ func FormatHelpMessage() string {
 /start \- начать работу с ботом
 /filters \- настроить фильтры поиска
 /vacancies \- получить вакансии по фильтрам
+/saved \- сохранённые вакансии
+/applications \- отклики и их статусы
 /settings \- настройки уведомлений
 /help \- справка
 
@@ -227,8 +274,15 @@ func FormatHelpMessage() string {
    \- Опыт работы
    \- График работы
    \- Ключевые слова
+   \- Несколько поисков можно завести в «🗂 Поиски»
+   \- Стоп\-слова и скрытые работодатели — в «🚫 Исключения»
+   \- Занятость, сортировка, метро и другое — в «➕ Ещё фильтры»
+   \- Поиск с сайта hh\.ru можно перенести, прислав ссылку на него
+   \- RSS/Atom\-ленты с вакансиями подключаются в «➕ Ещё фильтры» → «📡 RSS\-ленты»
 
 2️⃣ Получите вакансии командой /vacancies
+   \- Кнопка «⭐ Сохранить» под вакансией добавляет её в /saved, там же можно оставить заметку
+   \- Кнопка «📨 Откликнулся» добавляет вакансию в /applications: меняйте статус по мере ответов, а если ответа долго нет — бот напомнит
 
 3️⃣ Включите автоматические уведомления в /settings
 
@@ -242,13 +296,53 @@ func FormatNoFiltersMessage() string {
 Добавьте язык, город и условия через /filters, чтобы получать более точные вакансии\.`
 }
 
+// FormatSearchError explains a failed HH search in terms the user can act on (plain text)
+func FormatSearchError(err error) string {
+	switch {
+	case errors.Is(err, headhunter.ErrBadRequest):
+		return FormatInvalidQueryMessage(err)
+	case errors.Is(err, headhunter.ErrRateLimited):
+		if retryAfter, ok := headhunter.RetryAfter(err); ok && retryAfter > 0 {
+			return fmt.Sprintf("⚠️ hh.ru ограничил частоту запросов. Попробуйте через %s.", FormatWait(retryAfter))
+		}
+		return "⚠️ hh.ru ограничил частоту запросов. Попробуйте через минуту."
+	case errors.Is(err, headhunter.ErrUnavailable):
+		return "😔 hh.ru временно недоступен. Попробуйте позже."
+	default:
+		return "😔 Ошибка при поиске вакансий"
+	}
+}
+
+// FormatInvalidQueryMessage tells the user HH rejected the search query (plain text)
+func FormatInvalidQueryMessage(err error) string {
+	message := "❌ hh.ru не понял поисковый запрос. Проверьте ключевые слова: " +
+		"парные кавычки и скобки, операторы AND, OR, NOT."
+
+	var apiErr *headhunter.APIError
+	if errors.As(err, &apiErr) {
+		if values := apiErr.Values(); len(values) > 0 {
+			message += "\n\nНекорректные параметры: " + strings.Join(values, ", ")
+		}
+	}
+
+	return message + "\n\nИзменить фильтры: /filters"
+}
+
+// FormatWait renders a short wait as "N сек." or "N мин."
+func FormatWait(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%d сек.", int(d.Round(time.Second).Seconds()))
+	}
+	return fmt.Sprintf("%d мин.", int(d.Round(time.Minute).Minutes()))
+}
+
 func FormatNoVacanciesMessage() string {
 	return `😔 *Вакансии не найдены*
 
 Попробуйте изменить фильтры командой /filters`
 }
 
-func FormatSettingsMessage(user *models.User) string {
+func FormatSettingsMessage(user *models.User, searches []models.Search) string {
 	var sb strings.Builder
 
 	sb.WriteString("*⚙️ Настройки уведомлений*\n\n")
@@ -259,22 +353,126 @@ func FormatSettingsMessage(user *models.User) string {
 	}
 	sb.WriteString(fmt.Sprintf("*Статус:* %s\n", status))
 
-	sb.WriteString(fmt.Sprintf("*Интервал:* каждые %d минут\n", user.NotifyInterval))
+	if user.LastCheck != nil {
+		sb.WriteString(fmt.Sprintf("*Последняя проверка:* %s\n", EscapeMarkdown(user.LastCheck.In(user.Location()).Format("02.01 15:04"))))
+	}
+
+	sb.WriteString(fmt.Sprintf("*Часовой пояс:* %s\n", EscapeMarkdown(TimezoneLabel(user.Location().String()))))
+	if user.QuietStart != nil && user.QuietEnd != nil && *user.QuietStart != *user.QuietEnd {
+		sb.WriteString(fmt.Sprintf("*Тихие часы:* %s\n", EscapeMarkdown(FormatQuietHours(*user.QuietStart, *user.QuietEnd))))
+	} else {
+		sb.WriteString("*Тихие часы:* выключены\n")
+	}
+	sb.WriteString(fmt.Sprintf("*Доставка:* %s\n", EscapeMarkdown(FormatDeliveryMode(user))))
+	if user.CheckSchedule != nil {
+		sb.WriteString(fmt.Sprintf("*Расписание проверок:* %s\n", EscapeMarkdown(FormatCheckSchedule(*user.CheckSchedule))))
+		if user.ConsecutiveFailures == 0 && user.NextCheckAt != nil && user.NextCheckAt.After(time.Now()) {
+			sb.WriteString(fmt.Sprintf("*Следующая проверка:* %s\n", EscapeMarkdown(user.NextCheckAt.In(user.Location()).Format("02.01 15:04"))))
+		}
+	}
+
+	if user.ConsecutiveFailures > 0 {
+		sb.WriteString(fmt.Sprintf("⚠️ *Неудачных проверок подряд:* %d\n", user.ConsecutiveFailures))
+		if user.NextCheckAt != nil && user.NextCheckAt.After(time.Now()) {
+			sb.WriteString(fmt.Sprintf(
+				"*Повторная попытка:* %s _\\(hh\\.ru недоступен или ограничил запросы\\)_\n",
+				EscapeMarkdown(user.NextCheckAt.In(user.Location()).Format("02.01 15:04")),
+			))
+		}
+	}
+
+	if len(searches) == 0 {
+		sb.WriteString(fmt.Sprintf("*Интервал:* каждые %d минут\n", user.NotifyInterval))
+		return sb.String()
+	}
+
+	if user.CheckSchedule != nil {
+		sb.WriteString("\n*Интервалы поисков:* _\\(не действуют, пока задано расписание\\)_\n")
+	} else {
+		sb.WriteString("\n*Интервалы поисков:*\n")
+	}
+	for _, search := range searches {
+		marker := "•"
+		if user.ActiveSearchID != nil && *user.ActiveSearchID == search.ID {
+			marker = "▶️"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s — каждые %d минут\n",
+			marker,
+			EscapeMarkdown(search.Name),
+			search.NotifyInterval,
+		))
+	}
 
 	return sb.String()
 }
 
-func FormatFiltersMessage(filters []models.UserFilter) string {
+var deactivationLabels = map[string]string{
+	models.DeactivatedBlocked:      "заблокировали бота",
+	models.DeactivatedChatNotFound: "чат не найден",
+	models.DeactivatedUserDeleted:  "аккаунт удалён",
+}
+
+func FormatBotStats(stats *models.BotStats) string {
+	var sb strings.Builder
+
+	sb.WriteString("*📊 Статистика бота*\n\n")
+	sb.WriteString(fmt.Sprintf("Пользователей: %d\n", stats.TotalUsers))
+	sb.WriteString(fmt.Sprintf("С включёнными уведомлениями: %d\n", stats.CheckEnabled))
+
+	deactivated := 0
+	for _, count := range stats.Deactivated {
+		deactivated += count
+	}
+	sb.WriteString(fmt.Sprintf("\n*Отключены автоматически:* %d\n", deactivated))
+
+	reasons := make([]string, 0, len(stats.Deactivated))
+	for reason := range stats.Deactivated {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		label, ok := deactivationLabels[reason]
+		if !ok {
+			label = reason
+		}
+		sb.WriteString(fmt.Sprintf("• %s: %d\n", EscapeMarkdown(label), stats.Deactivated[reason]))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n*Очередь уведомлений:* %d\n", stats.PendingNotifications))
+	sb.WriteString(fmt.Sprintf("*Не доставлено:* %d", stats.DeadNotifications))
+
+	return sb.String()
+}
+
+func FormatSearchesMessage(searches []models.Search, activeID int64) string {
+	var sb strings.Builder
+
+	sb.WriteString("*🗂 Ваши поиски*\n\n")
+
+	for _, search := range searches {
+		if search.ID == activeID {
+			sb.WriteString(fmt.Sprintf("▶️ *%s* \\(текущий\\)\n", EscapeMarkdown(search.Name)))
+		} else {
+			sb.WriteString(fmt.Sprintf("• %s\n", EscapeMarkdown(search.Name)))
+		}
+	}
+
+	sb.WriteString("\nФильтры в /filters меняются у текущего поиска\\.")
+
+	return sb.String()
+}
+
+func FormatFiltersMessage(searchName string, filters []models.UserFilter) string {
 	if len(filters) == 0 {
 		return "ℹ️ У вас нет установленных фильтров"
 	}
 
 	var sb strings.Builder
-	sb.WriteString("*📋 Ваши фильтры:*\n\n")
+	sb.WriteString(fmt.Sprintf("*📋 Фильтры поиска «%s»:*\n\n", EscapeMarkdown(searchName)))
 
 	for _, filter := range filters {
 		filterName := getFilterDisplayName(filter.FilterType)
-		filterValue := formatFilterValue(filter.FilterType, filter.FilterValue)
+		filterValue := FormatFilterValue(filter.FilterType, filter.FilterValue)
 
 		sb.WriteString(fmt.Sprintf("• *%s:* %s\n",
 			EscapeMarkdown(filterName),
@@ -299,19 +497,60 @@ func getFilterDisplayName(filterType string) string {
 		return "График"
 	case models.FilterTypePublishedWithin:
 		return "Период публикации"
+	case models.FilterTypeEmployment:
+		return "Тип занятости"
+	case models.FilterTypeProfessionalRole:
+		return "Профессиональные роли"
+	case models.FilterTypeIndustry:
+		return "Отрасли"
+	case models.FilterTypeEmployer:
+		return "Работодатели"
+	case models.FilterTypeSearchField:
+		return "Где искать"
+	case models.FilterTypeOrderBy:
+		return "Сортировка"
+	case models.FilterTypeLabel:
+		return "Метки"
+	case models.FilterTypeMetro:
+		return "Метро"
+	case models.FilterTypeCurrency:
+		return "Валюта"
+	case models.FilterTypeOnlyWithSalary:
+		return "Только с зарплатой"
+	case models.FilterTypeSalaryTo:
+		return "Максимальная зарплата"
+	case models.FilterTypeExcludedEmployers:
+		return "Скрытые работодатели"
+	case models.FilterTypeExcludedWords:
+		return "Стоп-слова"
 	default:
 		return filterType
 	}
 }
 
-func formatFilterValue(filterType, value string) string {
+func FormatFilterValue(filterType, value string) string {
 	switch filterType {
 	case models.FilterTypeSalary:
 		return value + " ₽"
 	case models.FilterTypeExperience:
-		return models.GetExperienceDisplayName(value)
+		return joinDisplayNames(value, models.GetExperienceDisplayName)
 	case models.FilterTypeSchedule:
-		return models.GetScheduleDisplayName(value)
+		return joinDisplayNames(value, models.GetScheduleDisplayName)
+	case models.FilterTypeSalaryTo:
+		return "до " + value + " ₽"
+	case models.FilterTypeEmployment:
+		return joinDisplayNames(value, models.GetEmploymentDisplayName)
+	case models.FilterTypeSearchField, models.FilterTypeLabel, models.FilterTypeOrderBy, models.FilterTypeCurrency:
+		return joinDisplayNames(value, func(id string) string {
+			return models.GetOptionDisplayName(filterType, id)
+		})
+	case models.FilterTypeOnlyWithSalary:
+		return "да"
+	case models.FilterTypeArea, models.FilterTypeProfessionalRole, models.FilterTypeIndustry,
+		models.FilterTypeEmployer, models.FilterTypeMetro, models.FilterTypeExcludedWords:
+		return strings.Join(models.SplitFilterValues(value), ", ")
+	case models.FilterTypeExcludedEmployers:
+		return fmt.Sprintf("%d шт.", len(models.SplitFilterValues(value)))
 	case models.FilterTypePublishedWithin:
 		if days, err := strconv.Atoi(value); err == nil {
 			return "за " + FormatDays(days)
@@ -322,6 +561,15 @@ func formatFilterValue(filterType, value string) string {
 	}
 }
 
+func joinDisplayNames(value string, displayName func(string) string) string {
+	ids := models.SplitFilterValues(value)
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, displayName(id))
+	}
+	return strings.Join(names, ", ")
+}
+
 func FormatDays(days int) string {
 	if days <= 0 {
 		return "0 дней"
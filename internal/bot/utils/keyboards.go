@@ -1,8 +1,14 @@
 package utils
 
 import (
-	"hh-vacancy-bot/internal/models"
+	"fmt"
 	"strconv"
+	"strings"
+
+	"hh-vacancy-bot/internal/api/headhunter"
+	"hh-vacancy-bot/internal/bot/query"
+	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
 
 	tele "gopkg.in/telebot.v3"
 )
@@ -12,11 +18,14 @@ func MainMenuKeyboard() *tele.ReplyMarkup {
 
 	btnFilters := menu.Text("🔧 Фильтры")
 	btnVacancies := menu.Text("📋 Вакансии")
+	btnSaved := menu.Text("⭐ Сохранённые")
+	btnApplications := menu.Text("📨 Отклики")
 	btnSettings := menu.Text("⚙️ Настройки")
 	btnHelp := menu.Text("❓ Справка")
 
 	menu.Reply(
 		menu.Row(btnFilters, btnVacancies),
+		menu.Row(btnSaved, btnApplications),
 		menu.Row(btnSettings, btnHelp),
 	)
 
@@ -32,6 +41,10 @@ func FiltersMenuKeyboard() *tele.ReplyMarkup {
 	btnExperience := menu.Text("💼 Опыт")
 	btnSchedule := menu.Text("⏰ График")
 	btnPeriod := menu.Text("🗓 Период")
+	btnSearches := menu.Text("🗂 Поиски")
+	btnExclusions := menu.Text("🚫 Исключения")
+	btnLink := menu.Text("🌐 Ссылка hh.ru")
+	btnMore := menu.Text("➕ Ещё фильтры")
 	btnShow := menu.Text("📊 Показать фильтры")
 	btnClear := menu.Text("🗑 Очистить фильтры")
 	btnBack := menu.Text("◀️ Назад")
@@ -40,6 +53,8 @@ func FiltersMenuKeyboard() *tele.ReplyMarkup {
 		menu.Row(btnText, btnCity),
 		menu.Row(btnSalary, btnExperience),
 		menu.Row(btnSchedule, btnPeriod),
+		menu.Row(btnMore, btnExclusions),
+		menu.Row(btnSearches, btnLink),
 		menu.Row(btnShow, btnClear),
 		menu.Row(btnBack),
 	)
@@ -47,44 +62,155 @@ func FiltersMenuKeyboard() *tele.ReplyMarkup {
 	return menu
 }
 
-func ExperienceKeyboard() *tele.ReplyMarkup {
-	menu := &tele.ReplyMarkup{ResizeKeyboard: true}
+func InlineSearchesKeyboard(searches []models.Search, activeID int64) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+	var rows []tele.Row
+
+	for _, search := range searches {
+		label := search.Name
+		if search.ID == activeID {
+			label = "▶️ " + label
+		}
+
+		id := strconv.FormatInt(search.ID, 10)
+		rows = append(rows, menu.Row(
+			menu.Data(label, "search_select:"+id),
+			menu.Data("🗑", "search_delete:"+id),
+		))
+	}
+
+	rows = append(rows, menu.Row(
+		menu.Data("➕ Новый поиск", "search_new"),
+		menu.Data("✏️ Переименовать", "search_rename"),
+	))
+
+	menu.Inline(rows...)
 
-	options := models.ExperienceOptions()
+	return menu
+}
+
+func ExperienceKeyboard(selected []string) *tele.ReplyMarkup {
+	return ChecklistKeyboard(models.FilterTypeExperience, selected)
+}
+
+func ScheduleKeyboard(selected []string) *tele.ReplyMarkup {
+	return ChecklistKeyboard(models.FilterTypeSchedule, selected)
+}
+
+// ChecklistKeyboard renders a multi-choice filter backed by an HH dictionary
+func ChecklistKeyboard(filterType string, selected []string) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
 	var rows []tele.Row
 
-	for _, option := range options {
-		btn := menu.Text(option)
+	for _, option := range models.GetDictionaries().Options(filterType) {
+		btn := menu.Data(checkboxLabel(option.Name, models.ContainsFilterValue(selected, option.ID)), "toggle_"+filterType+":"+option.ID)
 		rows = append(rows, menu.Row(btn))
 	}
 
-	btnCancel := menu.Text("❌ Отмена")
-	rows = append(rows, menu.Row(btnCancel))
+	rows = append(rows, menu.Row(menu.Data("✅ Готово", "filter_done:"+filterType)))
 
-	menu.Reply(rows...)
+	menu.Inline(rows...)
 
 	return menu
 }
 
-func ScheduleKeyboard() *tele.ReplyMarkup {
-	menu := &tele.ReplyMarkup{ResizeKeyboard: true}
+// ChoiceKeyboard renders a single-choice filter; the reset button clears it
+func ChoiceKeyboard(filterType, selected string) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+	var rows []tele.Row
+
+	for _, option := range models.GetDictionaries().Options(filterType) {
+		label := "⚪ " + option.Name
+		if option.ID == selected {
+			label = "🔘 " + option.Name
+		}
+		rows = append(rows, menu.Row(menu.Data(label, "set_filter:"+filterType+":"+option.ID)))
+	}
+
+	rows = append(rows, menu.Row(
+		menu.Data("♻️ Сбросить", "set_filter:"+filterType+":"),
+		menu.Data("✅ Готово", "filter_done:"+filterType),
+	))
+
+	menu.Inline(rows...)
+
+	return menu
+}
+
+// MoreFiltersKeyboard lists filters that do not fit the reply keyboard
+func MoreFiltersKeyboard(filters map[string]string) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+
+	btn := func(text, filterType string) tele.Btn {
+		if filters[filterType] != "" {
+			text += " ✏️"
+		}
+		return menu.Data(text, "more_filter:"+filterType)
+	}
+
+	menu.Inline(
+		menu.Row(btn("👔 Занятость", models.FilterTypeEmployment), btn("🔎 Где искать", models.FilterTypeSearchField)),
+		menu.Row(btn("🏷 Метки", models.FilterTypeLabel), btn("↕️ Сортировка", models.FilterTypeOrderBy)),
+		menu.Row(btn("💱 Валюта", models.FilterTypeCurrency), btn("💰 Зарплата до", models.FilterTypeSalaryTo)),
+		menu.Row(btn("🧑‍💻 Роли", models.FilterTypeProfessionalRole), btn("🏭 Отрасли", models.FilterTypeIndustry)),
+		menu.Row(btn("🏢 Работодатели", models.FilterTypeEmployer), btn("🚇 Метро", models.FilterTypeMetro)),
+		menu.Row(menu.Data(
+			checkboxLabel("Только с указанной зарплатой", filters[models.FilterTypeOnlyWithSalary] == "true"),
+			"toggle_only_with_salary",
+		)),
+		menu.Row(menu.Data("📡 RSS-ленты", "feeds")),
+	)
 
-	options := models.ScheduleOptions()
+	return menu
+}
+
+// FeedsKeyboard lists feeds of a search; tapping one removes it
+func FeedsKeyboard(feeds []models.SearchFeed) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
 	var rows []tele.Row
 
-	for _, option := range options {
-		btn := menu.Text(option)
-		rows = append(rows, menu.Row(btn))
+	for _, feed := range feeds {
+		rows = append(rows, menu.Row(menu.Data(
+			"🗑 "+TruncateString(feed.URL, 40),
+			"feed_delete:"+strconv.FormatInt(feed.ID, 10),
+		)))
 	}
 
-	btnCancel := menu.Text("❌ Отмена")
-	rows = append(rows, menu.Row(btnCancel))
+	if len(feeds) < models.MaxFeedsPerSearch {
+		rows = append(rows, menu.Row(menu.Data("➕ Добавить ленту", "feed_add")))
+	}
+
+	menu.Inline(rows...)
+
+	return menu
+}
+
+// AreaKeyboard lists selected areas; tapping one removes it from the set
+func AreaKeyboard(areas []headhunter.IDName) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+	var rows []tele.Row
 
-	menu.Reply(rows...)
+	for _, area := range areas {
+		rows = append(rows, menu.Row(menu.Data(checkboxLabel(area.Name, true), "toggle_area:"+area.ID)))
+	}
+
+	rows = append(rows, menu.Row(
+		menu.Data("➕ Добавить город", "area_add"),
+		menu.Data("✅ Готово", "filter_done:"+models.FilterTypeArea),
+	))
+
+	menu.Inline(rows...)
 
 	return menu
 }
 
+func checkboxLabel(text string, checked bool) string {
+	if checked {
+		return "✅ " + text
+	}
+	return "⬜ " + text
+}
+
 func CancelKeyboard() *tele.ReplyMarkup {
 	menu := &tele.ReplyMarkup{ResizeKeyboard: true}
 
@@ -105,17 +231,103 @@ func SettingsKeyboard(checkEnabled bool) *tele.ReplyMarkup {
 	}
 
 	btnInterval := menu.Text("⏰ Изменить интервал")
+	btnTimezone := menu.Text("🌍 Часовой пояс")
+	btnQuiet := menu.Text("🌙 Тихие часы")
+	btnDelivery := menu.Text("📬 Доставка")
+	btnSchedule := menu.Text("🗓 Расписание")
 	btnBack := menu.Text("◀️ Назад")
 
 	menu.Reply(
 		menu.Row(btnToggle),
-		menu.Row(btnInterval),
+		menu.Row(btnInterval, btnSchedule),
+		menu.Row(btnTimezone, btnQuiet),
+		menu.Row(btnDelivery),
 		menu.Row(btnBack),
 	)
 
 	return menu
 }
 
+// TimezoneKeyboard lists the preset time zones, marking the current one
+func TimezoneKeyboard(current string) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+	var rows []tele.Row
+
+	for i := 0; i < len(TimezonePresets); i += 2 {
+		var row tele.Row
+		for j := i; j < i+2 && j < len(TimezonePresets); j++ {
+			preset := TimezonePresets[j]
+			label := preset.Label
+			if preset.Name == current {
+				label = "✅ " + label
+			}
+			row = append(row, menu.Data(label, "tz:"+strconv.Itoa(j)))
+		}
+		rows = append(rows, row)
+	}
+
+	menu.Inline(rows...)
+
+	return menu
+}
+
+// LocationKeyboard asks Telegram for the user's location to detect the time zone
+func LocationKeyboard() *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{ResizeKeyboard: true}
+
+	btnLocation := menu.Location("📍 Отправить геопозицию")
+	btnBack := menu.Text("⚙️ Настройки")
+
+	menu.Reply(
+		menu.Row(btnLocation),
+		menu.Row(btnBack),
+	)
+
+	return menu
+}
+
+// QuietHoursKeyboard offers common quiet-hours windows
+func QuietHoursKeyboard() *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+
+	menu.Inline(
+		menu.Row(
+			menu.Data(FormatQuietHours(22, 8), "quiet:22:8"),
+			menu.Data(FormatQuietHours(23, 7), "quiet:23:7"),
+		),
+		menu.Row(
+			menu.Data(FormatQuietHours(0, 9), "quiet:0:9"),
+			menu.Data("✏️ Свои часы", "quiet_custom"),
+		),
+		menu.Row(menu.Data("🔔 Без тихих часов", "quiet_off")),
+	)
+
+	return menu
+}
+
+// ScheduleKeyboard offers the preset check schedules, marking the current one
+func CheckScheduleKeyboard(current *string) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+	var rows []tele.Row
+
+	for i, preset := range CheckSchedulePresets {
+		label := preset.Label
+		if current != nil && *current == preset.Expr {
+			label = "✅ " + label
+		}
+		rows = append(rows, menu.Row(menu.Data(label, "check_schedule:"+strconv.Itoa(i))))
+	}
+
+	rows = append(rows,
+		menu.Row(menu.Data("✏️ Своё выражение", "check_schedule_custom")),
+		menu.Row(menu.Data("⏰ По интервалам поисков", "check_schedule_off")),
+	)
+
+	menu.Inline(rows...)
+
+	return menu
+}
+
 func IntervalKeyboard() *tele.ReplyMarkup {
 	menu := &tele.ReplyMarkup{ResizeKeyboard: true}
 
@@ -141,13 +353,58 @@ func RemoveKeyboard() *tele.ReplyMarkup {
 	return &tele.ReplyMarkup{RemoveKeyboard: true}
 }
 
-func InlineVacancyKeyboard(vacancyURL string) *tele.ReplyMarkup {
+func InlineVacancyKeyboard(vacancy *source.Vacancy, searchID int64) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+
+	var rows []tele.Row
+
+	// Telegram rejects a url button without a url
+	if vacancy.URL != "" {
+		rows = append(rows, menu.Row(menu.URL("🔗 Открыть вакансию", vacancy.URL)))
+	}
+
+	rows = append(rows, menu.Row(
+		menu.Data("⭐ Сохранить", "save_vacancy:"+vacancy.CacheID()),
+		menu.Data("📨 Откликнулся", "apply_vacancy:"+vacancy.CacheID()),
+	))
+
+	// anonymous and feed vacancies have no hh employer id to blacklist
+	if vacancy.Source == source.SourceHH && vacancy.EmployerID != "" && searchID > 0 {
+		btnExclude := menu.Data(
+			"🚫 Скрыть работодателя",
+			"exclude_employer:"+strconv.FormatInt(searchID, 10)+":"+vacancy.EmployerID,
+		)
+		rows = append(rows, menu.Row(btnExclude))
+	}
+
+	menu.Inline(rows...)
+
+	return menu
+}
+
+func InlineExclusionsKeyboard(hiddenEmployers int) *tele.ReplyMarkup {
 	menu := &tele.ReplyMarkup{}
 
-	btnOpen := menu.URL("🔗 Открыть вакансию", vacancyURL)
+	rows := []tele.Row{menu.Row(menu.Data("✏️ Стоп-слова", "exclusions_words"))}
+
+	if hiddenEmployers > 0 {
+		rows = append(rows, menu.Row(menu.Data(
+			"♻️ Вернуть скрытых работодателей ("+strconv.Itoa(hiddenEmployers)+")",
+			"exclusions_reset_employers",
+		)))
+	}
+
+	menu.Inline(rows...)
+
+	return menu
+}
+
+func InlineSearchLinkKeyboard(link string) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
 
 	menu.Inline(
-		menu.Row(btnOpen),
+		menu.Row(menu.URL("🌐 Открыть на hh.ru", link)),
+		menu.Row(menu.Data("📥 Импортировать ссылку", "search_import")),
 	)
 
 	return menu
@@ -181,6 +438,116 @@ func InlinePaginationKeyboard(page, totalPages int, callbackPrefix string) *tele
 	return menu
 }
 
+// DeliveryKeyboard switches between instant cards and digests and picks how a digest is grouped
+func DeliveryKeyboard(user *models.User) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+
+	mark := func(text string, selected bool) string {
+		if selected {
+			return "✅ " + text
+		}
+		return text
+	}
+
+	menu.Inline(
+		menu.Row(menu.Data(mark("⚡ Сразу", user.DeliveryMode == models.DeliveryInstant), "delivery:"+models.DeliveryInstant)),
+		menu.Row(
+			menu.Data(mark("📅 Раз в день", user.DeliveryMode == models.DeliveryDaily), "delivery:"+models.DeliveryDaily),
+			menu.Data(mark("🗓 Раз в неделю", user.DeliveryMode == models.DeliveryWeekly), "delivery:"+models.DeliveryWeekly),
+		),
+		menu.Row(
+			menu.Data(mark("🏢 По компаниям", user.DigestGroup != models.DigestGroupCity), "digest_group:"+models.DigestGroupEmployer),
+			menu.Data(mark("📍 По городам", user.DigestGroup == models.DigestGroupCity), "digest_group:"+models.DigestGroupCity),
+		),
+	)
+
+	return menu
+}
+
+// WeekdayKeyboard picks the day of the weekly digest
+func WeekdayKeyboard() *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+	var rows []tele.Row
+
+	// Monday first, time.Weekday numbers Sunday as 0
+	for i := 1; i <= 7; i += 2 {
+		var row tele.Row
+		for j := i; j < i+2 && j <= 7; j++ {
+			weekday := j % 7
+			name := []rune(weekdayNames[weekday])
+			label := strings.ToUpper(string(name[:1])) + string(name[1:])
+			row = append(row, menu.Data(label, "digest_day:"+strconv.Itoa(weekday)))
+		}
+		rows = append(rows, row)
+	}
+
+	menu.Inline(rows...)
+
+	return menu
+}
+
+// DigestHourKeyboard picks the local hour the digest goes out
+func DigestHourKeyboard(mode string, weekday int) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+	var rows []tele.Row
+
+	for hour := 0; hour < 24; hour += 6 {
+		var row tele.Row
+		for h := hour; h < hour+6; h++ {
+			row = append(row, menu.Data(
+				fmt.Sprintf("%02d:00", h),
+				fmt.Sprintf("digest_at:%s:%d:%d", mode, weekday, h),
+			))
+		}
+		rows = append(rows, row)
+	}
+
+	menu.Inline(rows...)
+
+	return menu
+}
+
+// DigestPaginationKeyboard pages through a digest that did not fit one message
+func DigestPaginationKeyboard(digestID int64, page, totalPages int) *tele.ReplyMarkup {
+	return InlinePaginationKeyboard(page, totalPages, "digest_page:"+strconv.FormatInt(digestID, 10))
+}
+
+// VacancyNavigationKeyboard is pagination plus the refine button under /vacancies results
+func VacancyNavigationKeyboard(page, totalPages int) *tele.ReplyMarkup {
+	menu := InlinePaginationKeyboard(page, totalPages, "vacancy_page")
+
+	rows := menu.InlineKeyboard
+	rows = append(rows, []tele.InlineButton{*menu.Data("🧩 Уточнить поиск", "refine").Inline()})
+	menu.InlineKeyboard = rows
+
+	return menu
+}
+
+// RefineKeyboard has one button per facet; tapping adds it to the active search
+func RefineKeyboard(groups []query.FacetGroup) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+	var rows []tele.Row
+
+	for _, group := range groups {
+		var row tele.Row
+		for _, facet := range group.Facets {
+			label := fmt.Sprintf("%s (%d)", facet.Name, facet.Count)
+			row = append(row, menu.Data(label, "refine_add:"+facet.FilterType+":"+facet.Value))
+			if len(row) == 2 {
+				rows = append(rows, row)
+				row = nil
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+
+	menu.Inline(rows...)
+
+	return menu
+}
+
 func PeriodKeyboard() *tele.ReplyMarkup {
 	menu := &tele.ReplyMarkup{ResizeKeyboard: true}
 
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/source"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// SavedPageSize is how many bookmarks one /saved page shows
+const SavedPageSize = 5
+
+// FormatSavedVacancies renders one page of bookmarks, numbered from offset+1.
+// vacancies maps cache ids to the cached vacancies.
+func FormatSavedVacancies(saved []models.SavedVacancy, vacancies map[string]models.Vacancy, offset, total int) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("⭐ *Сохранённые вакансии:* %d\n", total))
+
+	for i, item := range saved {
+		sb.WriteString(fmt.Sprintf("\n*%d\\.* ", offset+i+1))
+
+		v, ok := vacancies[item.VacancyID]
+		if !ok {
+			sb.WriteString("_вакансия недоступна_\n")
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("[%s](%s)", EscapeMarkdown(TruncateString(v.Title, 80)), escapeMarkdownURL(v.URL)))
+		if item.ArchivedAt != nil {
+			sb.WriteString(" — 🗄 _в архиве_")
+		}
+		sb.WriteString("\n")
+
+		var details []string
+		if v.Company != nil && *v.Company != "" {
+			details = append(details, "🏢 "+EscapeMarkdown(TruncateString(*v.Company, 40)))
+		}
+		if v.SalaryFrom != nil || v.SalaryTo != nil {
+			salary := &source.Salary{From: v.SalaryFrom, To: v.SalaryTo}
+			if v.Currency != nil {
+				salary.Currency = *v.Currency
+			}
+			details = append(details, "💰 "+EscapeMarkdown(FormatSalary(salary)))
+		}
+		if v.Area != "" {
+			details = append(details, "📍 "+EscapeMarkdown(v.Area))
+		}
+		if len(details) > 0 {
+			sb.WriteString(strings.Join(details, " · ") + "\n")
+		}
+
+		if item.Note != nil {
+			sb.WriteString("📝 _" + EscapeMarkdown(*item.Note) + "_\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// SavedVacanciesKeyboard has note and remove buttons for each bookmark on the page plus pagination
+func SavedVacanciesKeyboard(saved []models.SavedVacancy, offset, page, totalPages int) *tele.ReplyMarkup {
+	menu := InlinePaginationKeyboard(page, totalPages, "saved_page")
+
+	var rows [][]tele.InlineButton
+	for i, item := range saved {
+		n := strconv.Itoa(offset + i + 1)
+		id := strconv.FormatInt(item.ID, 10)
+		rows = append(rows, []tele.InlineButton{
+			*menu.Data("📝 Заметка "+n, "saved_note:"+id).Inline(),
+			*menu.Data("🗑 Удалить "+n, "saved_del:"+id+":"+strconv.Itoa(page)).Inline(),
+		})
+	}
+
+	menu.InlineKeyboard = append(rows, menu.InlineKeyboard...)
+
+	return menu
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// CheckSchedulePreset is a check schedule offered in settings
+type CheckSchedulePreset struct {
+	Expr  string
+	Label string
+}
+
+var CheckSchedulePresets = []CheckSchedulePreset{
+	{Expr: "0 9,18 * * 1-5", Label: "По будням в 09:00 и 18:00"},
+	{Expr: "*/30 10-19 * * *", Label: "Каждые 30 минут с 10:00 до 19:30"},
+	{Expr: "0 9 * * *", Label: "Каждый день в 09:00"},
+	{Expr: "0 */2 * * *", Label: "Каждые 2 часа"},
+	{Expr: "0 10 * * 1", Label: "По понедельникам в 10:00"},
+}
+
+// FormatCheckSchedule names a preset schedule, other expressions are shown as is
+func FormatCheckSchedule(expr string) string {
+	for _, preset := range CheckSchedulePresets {
+		if preset.Expr == expr {
+			return preset.Label
+		}
+	}
+	return expr
+}
+
+var weekdayShort = [...]string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"}
+
+// FormatScheduleRuns lists upcoming checks, one per line
+func FormatScheduleRuns(runs []time.Time) string {
+	var sb strings.Builder
+	for _, run := range runs {
+		sb.WriteString("• " + weekdayShort[run.Weekday()] + " " + run.Format("02.01 15:04") + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+)
+
+// TimezonePreset is a time zone offered in settings, with a reference city for location lookup
+type TimezonePreset struct {
+	Name  string // IANA name
+	Label string
+	Lat   float64
+	Lon   float64
+}
+
+var TimezonePresets = []TimezonePreset{
+	{Name: "Europe/Kaliningrad", Label: "Калининград (UTC+2)", Lat: 54.71, Lon: 20.51},
+	{Name: "Europe/Moscow", Label: "Москва (UTC+3)", Lat: 55.76, Lon: 37.62},
+	{Name: "Europe/Minsk", Label: "Минск (UTC+3)", Lat: 53.90, Lon: 27.56},
+	{Name: "Europe/Samara", Label: "Самара (UTC+4)", Lat: 53.20, Lon: 50.15},
+	{Name: "Asia/Yekaterinburg", Label: "Екатеринбург (UTC+5)", Lat: 56.84, Lon: 60.61},
+	{Name: "Asia/Almaty", Label: "Алматы (UTC+5)", Lat: 43.24, Lon: 76.95},
+	{Name: "Asia/Omsk", Label: "Омск (UTC+6)", Lat: 54.99, Lon: 73.37},
+	{Name: "Asia/Novosibirsk", Label: "Новосибирск (UTC+7)", Lat: 55.03, Lon: 82.92},
+	{Name: "Asia/Krasnoyarsk", Label: "Красноярск (UTC+7)", Lat: 56.01, Lon: 92.89},
+	{Name: "Asia/Irkutsk", Label: "Иркутск (UTC+8)", Lat: 52.29, Lon: 104.28},
+	{Name: "Asia/Yakutsk", Label: "Якутск (UTC+9)", Lat: 62.03, Lon: 129.73},
+	{Name: "Asia/Vladivostok", Label: "Владивосток (UTC+10)", Lat: 43.12, Lon: 131.89},
+	{Name: "Asia/Magadan", Label: "Магадан (UTC+11)", Lat: 59.57, Lon: 150.80},
+	{Name: "Asia/Kamchatka", Label: "Камчатка (UTC+12)", Lat: 53.02, Lon: 158.65},
+}
+
+// timezoneReference is another city telling which zone its surroundings are in.
+// An empty Zone marks a city outside the presets, so locations near it are not snapped to a preset across the border.
+type timezoneReference struct {
+	Zone     string
+	Lat, Lon float64
+}
+
+var timezoneReferences = []timezoneReference{
+	{Zone: "Europe/Moscow", Lat: 59.94, Lon: 30.31},      // Saint Petersburg
+	{Zone: "Europe/Moscow", Lat: 68.97, Lon: 33.07},      // Murmansk
+	{Zone: "Europe/Moscow", Lat: 64.54, Lon: 40.54},      // Arkhangelsk
+	{Zone: "Europe/Moscow", Lat: 47.24, Lon: 39.71},      // Rostov-on-Don
+	{Zone: "Europe/Moscow", Lat: 55.79, Lon: 49.12},      // Kazan
+	{Zone: "Asia/Yekaterinburg", Lat: 61.25, Lon: 73.40}, // Surgut
+	{Zone: "Asia/Krasnoyarsk", Lat: 69.35, Lon: 88.20},   // Norilsk
+	{Zone: "Asia/Yakutsk", Lat: 52.03, Lon: 113.50},      // Chita
+	{Zone: "Asia/Yakutsk", Lat: 50.26, Lon: 127.53},      // Blagoveshchensk
+	{Zone: "Asia/Vladivostok", Lat: 48.48, Lon: 135.07},  // Khabarovsk
+	{Zone: "Asia/Kamchatka", Lat: 64.73, Lon: 177.51},    // Anadyr
+
+	{Lat: 52.52, Lon: 13.40},  // Berlin
+	{Lat: 52.23, Lon: 21.01},  // Warsaw
+	{Lat: 54.69, Lon: 25.28},  // Vilnius
+	{Lat: 56.95, Lon: 24.11},  // Riga
+	{Lat: 59.44, Lon: 24.75},  // Tallinn
+	{Lat: 60.17, Lon: 24.94},  // Helsinki
+	{Lat: 50.45, Lon: 30.52},  // Kyiv
+	{Lat: 46.48, Lon: 30.72},  // Odesa
+	{Lat: 41.72, Lon: 44.79},  // Tbilisi
+	{Lat: 40.18, Lon: 44.51},  // Yerevan
+	{Lat: 40.41, Lon: 49.87},  // Baku
+	{Lat: 42.87, Lon: 74.59},  // Bishkek
+	{Lat: 41.30, Lon: 69.24},  // Tashkent
+	{Lat: 45.80, Lon: 126.53}, // Harbin
+	{Lat: 39.90, Lon: 116.41}, // Beijing
+	{Lat: 37.57, Lon: 126.98}, // Seoul
+	{Lat: 43.06, Lon: 141.35}, // Sapporo
+}
+
+// maxTimezoneDistance is how far from the nearest reference city a location still gets its zone, in km
+const maxTimezoneDistance = 1000
+
+// NearestTimezone picks the preset of the reference city closest to the point.
+// It reports false when that city is outside the presets or too far away to trust.
+func NearestTimezone(lat, lon float64) (TimezonePreset, bool) {
+	zone := ""
+	bestDist := math.Inf(1)
+
+	for _, preset := range TimezonePresets {
+		if d := haversine(lat, lon, preset.Lat, preset.Lon); d < bestDist {
+			zone, bestDist = preset.Name, d
+		}
+	}
+	for _, ref := range timezoneReferences {
+		if d := haversine(lat, lon, ref.Lat, ref.Lon); d < bestDist {
+			zone, bestDist = ref.Zone, d
+		}
+	}
+
+	if bestDist > maxTimezoneDistance {
+		return TimezonePreset{}, false
+	}
+
+	for _, preset := range TimezonePresets {
+		if preset.Name == zone {
+			return preset, true
+		}
+	}
+
+	return TimezonePreset{}, false
+}
+
+// TimezoneLabel returns the preset label, or the IANA name for zones outside the list
+func TimezoneLabel(name string) string {
+	for _, preset := range TimezonePresets {
+		if preset.Name == name {
+			return preset.Label
+		}
+	}
+	return name
+}
+
+// FormatQuietHours renders a window like 22:00–08:00
+func FormatQuietHours(start, end int) string {
+	return fmt.Sprintf("%02d:00–%02d:00", start, end)
+}
+
+// haversine returns the great-circle distance in kilometres
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371.0
+
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,6 +12,10 @@ type Config struct {
 	// Telegram
 	TelegramToken string
 
+	// Telegram send limits
+	TelegramMessagesPerSecond float64
+	TelegramChatInterval      time.Duration
+
 	// Database
 	PostgresDSN   string
 	RedisAddr     string
@@ -21,9 +26,37 @@ type Config struct {
 	HHAPIBaseURL string
 	HHAPITimeout time.Duration
 
+	// HeadHunter API retries
+	HHAPIMaxAttempts    int
+	HHAPIRetryBaseDelay time.Duration
+	HHAPIRetryMaxDelay  time.Duration
+
+	// HeadHunter API budgets, requests per minute
+	HHAPIInteractiveRPM   float64
+	HHAPIInteractiveBurst int
+	HHAPIBackgroundRPM    float64
+	HHAPIBackgroundBurst  int
+
 	// Bot settings
 	CheckInterval        time.Duration
 	MaxVacanciesPerCheck int
+	CheckPageBudget      int           // result pages one background search may read
+	CheckOverlap         time.Duration // searched before the last check to catch late-indexed vacancies
+	CheckConcurrency     int
+	CheckUserTimeout     time.Duration
+	LeaderLeaseTTL       time.Duration
+
+	// days an application may go without a reply before the bot suggests following up
+	ApplicationReminderDays int
+
+	// Retention
+	MaintenanceInterval       time.Duration
+	VacancyCacheRetentionDays int // unreferenced cached vacancies
+	SeenRetentionDays         int // seen-marks; shorter than a search period lets old vacancies come again
+	OutboxRetentionDays       int // sent and dead-lettered notifications
+
+	// Telegram ids allowed to use admin commands
+	AdminIDs []int64
 
 	// Logging
 	LogLevel string
@@ -32,12 +65,31 @@ type Config struct {
 func Load() (*Config, error) {
 	cfg := &Config{
 		// Defaults
-		HHAPIBaseURL:         "https://api.hh.ru",
-		HHAPITimeout:         30 * time.Second,
-		CheckInterval:        5 * time.Minute,
-		MaxVacanciesPerCheck: 10,
-		LogLevel:             "info",
-		RedisDB:              0,
+		HHAPIBaseURL:              "https://api.hh.ru",
+		HHAPITimeout:              30 * time.Second,
+		HHAPIMaxAttempts:          3,
+		HHAPIRetryBaseDelay:       time.Second,
+		HHAPIRetryMaxDelay:        30 * time.Second,
+		HHAPIInteractiveRPM:       30,
+		HHAPIInteractiveBurst:     10,
+		HHAPIBackgroundRPM:        20,
+		HHAPIBackgroundBurst:      5,
+		CheckInterval:             5 * time.Minute,
+		MaxVacanciesPerCheck:      10,
+		CheckPageBudget:           3,
+		CheckOverlap:              10 * time.Minute,
+		CheckConcurrency:          4,
+		CheckUserTimeout:          2 * time.Minute,
+		LeaderLeaseTTL:            30 * time.Second,
+		ApplicationReminderDays:   7,
+		MaintenanceInterval:       6 * time.Hour,
+		VacancyCacheRetentionDays: 30,
+		SeenRetentionDays:         180,
+		OutboxRetentionDays:       30,
+		TelegramMessagesPerSecond: 25,
+		TelegramChatInterval:      time.Second,
+		LogLevel:                  "info",
+		RedisDB:                   0,
 	}
 
 	cfg.TelegramToken = os.Getenv("TELEGRAM_TOKEN")
@@ -78,6 +130,62 @@ func Load() (*Config, error) {
 		cfg.HHAPITimeout = d
 	}
 
+	if attempts := os.Getenv("HHAPI_MAX_ATTEMPTS"); attempts != "" {
+		n, err := strconv.Atoi(attempts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HHAPI_MAX_ATTEMPTS: %w", err)
+		}
+		cfg.HHAPIMaxAttempts = n
+	}
+
+	if delay := os.Getenv("HHAPI_RETRY_BASE_DELAY"); delay != "" {
+		d, err := time.ParseDuration(delay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HHAPI_RETRY_BASE_DELAY: %w", err)
+		}
+		cfg.HHAPIRetryBaseDelay = d
+	}
+
+	if delay := os.Getenv("HHAPI_RETRY_MAX_DELAY"); delay != "" {
+		d, err := time.ParseDuration(delay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HHAPI_RETRY_MAX_DELAY: %w", err)
+		}
+		cfg.HHAPIRetryMaxDelay = d
+	}
+
+	if rpm := os.Getenv("HHAPI_INTERACTIVE_RPM"); rpm != "" {
+		n, err := strconv.ParseFloat(rpm, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HHAPI_INTERACTIVE_RPM: %w", err)
+		}
+		cfg.HHAPIInteractiveRPM = n
+	}
+
+	if burst := os.Getenv("HHAPI_INTERACTIVE_BURST"); burst != "" {
+		n, err := strconv.Atoi(burst)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HHAPI_INTERACTIVE_BURST: %w", err)
+		}
+		cfg.HHAPIInteractiveBurst = n
+	}
+
+	if rpm := os.Getenv("HHAPI_BACKGROUND_RPM"); rpm != "" {
+		n, err := strconv.ParseFloat(rpm, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HHAPI_BACKGROUND_RPM: %w", err)
+		}
+		cfg.HHAPIBackgroundRPM = n
+	}
+
+	if burst := os.Getenv("HHAPI_BACKGROUND_BURST"); burst != "" {
+		n, err := strconv.Atoi(burst)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HHAPI_BACKGROUND_BURST: %w", err)
+		}
+		cfg.HHAPIBackgroundBurst = n
+	}
+
 	if interval := os.Getenv("CHECK_INTERVAL"); interval != "" {
 		d, err := time.ParseDuration(interval)
 		if err != nil {
@@ -94,6 +202,112 @@ func Load() (*Config, error) {
 		cfg.MaxVacanciesPerCheck = n
 	}
 
+	if budget := os.Getenv("CHECK_PAGE_BUDGET"); budget != "" {
+		n, err := strconv.Atoi(budget)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHECK_PAGE_BUDGET: %w", err)
+		}
+		cfg.CheckPageBudget = n
+	}
+
+	if overlap := os.Getenv("CHECK_OVERLAP"); overlap != "" {
+		d, err := time.ParseDuration(overlap)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHECK_OVERLAP: %w", err)
+		}
+		cfg.CheckOverlap = d
+	}
+
+	if concurrency := os.Getenv("CHECK_CONCURRENCY"); concurrency != "" {
+		n, err := strconv.Atoi(concurrency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHECK_CONCURRENCY: %w", err)
+		}
+		cfg.CheckConcurrency = n
+	}
+
+	if timeout := os.Getenv("CHECK_USER_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHECK_USER_TIMEOUT: %w", err)
+		}
+		cfg.CheckUserTimeout = d
+	}
+
+	if ttl := os.Getenv("LEADER_LEASE_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LEADER_LEASE_TTL: %w", err)
+		}
+		cfg.LeaderLeaseTTL = d
+	}
+
+	if days := os.Getenv("APPLICATION_REMINDER_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return nil, fmt.Errorf("invalid APPLICATION_REMINDER_DAYS: %w", err)
+		}
+		cfg.ApplicationReminderDays = n
+	}
+
+	if interval := os.Getenv("MAINTENANCE_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAINTENANCE_INTERVAL: %w", err)
+		}
+		cfg.MaintenanceInterval = d
+	}
+
+	if days := os.Getenv("VACANCY_CACHE_RETENTION_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return nil, fmt.Errorf("invalid VACANCY_CACHE_RETENTION_DAYS: %w", err)
+		}
+		cfg.VacancyCacheRetentionDays = n
+	}
+
+	if days := os.Getenv("SEEN_RETENTION_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SEEN_RETENTION_DAYS: %w", err)
+		}
+		cfg.SeenRetentionDays = n
+	}
+
+	if days := os.Getenv("OUTBOX_RETENTION_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OUTBOX_RETENTION_DAYS: %w", err)
+		}
+		cfg.OutboxRetentionDays = n
+	}
+
+	if rate := os.Getenv("TELEGRAM_MESSAGES_PER_SECOND"); rate != "" {
+		n, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TELEGRAM_MESSAGES_PER_SECOND: %w", err)
+		}
+		cfg.TelegramMessagesPerSecond = n
+	}
+
+	if interval := os.Getenv("TELEGRAM_CHAT_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TELEGRAM_CHAT_INTERVAL: %w", err)
+		}
+		cfg.TelegramChatInterval = d
+	}
+
+	if admins := os.Getenv("ADMIN_IDS"); admins != "" {
+		for _, field := range strings.Split(admins, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ADMIN_IDS: %w", err)
+			}
+			cfg.AdminIDs = append(cfg.AdminIDs, id)
+		}
+	}
+
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		cfg.LogLevel = logLevel
 	}
@@ -118,6 +332,62 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("max vacancies per check must be between 1 and 100")
 	}
 
+	if c.CheckPageBudget < 1 || c.CheckPageBudget > 20 {
+		return fmt.Errorf("check page budget must be between 1 and 20")
+	}
+
+	if c.CheckOverlap < 0 {
+		return fmt.Errorf("invalid check overlap: %v", c.CheckOverlap)
+	}
+
+	if c.CheckConcurrency < 1 || c.CheckConcurrency > 64 {
+		return fmt.Errorf("check concurrency must be between 1 and 64")
+	}
+
+	if c.CheckUserTimeout < 10*time.Second {
+		return fmt.Errorf("check user timeout too small: %v", c.CheckUserTimeout)
+	}
+
+	if c.TelegramMessagesPerSecond <= 0 || c.TelegramMessagesPerSecond > 30 {
+		return fmt.Errorf("telegram messages per second must be in (0, 30]")
+	}
+
+	if c.TelegramChatInterval < 0 {
+		return fmt.Errorf("invalid telegram chat interval: %v", c.TelegramChatInterval)
+	}
+
+	if c.LeaderLeaseTTL < 5*time.Second {
+		return fmt.Errorf("leader lease ttl too small: %v", c.LeaderLeaseTTL)
+	}
+
+	if c.ApplicationReminderDays < 1 || c.ApplicationReminderDays > 90 {
+		return fmt.Errorf("application reminder days must be between 1 and 90")
+	}
+
+	if c.MaintenanceInterval < time.Minute {
+		return fmt.Errorf("maintenance interval too small: %v", c.MaintenanceInterval)
+	}
+
+	if c.VacancyCacheRetentionDays < 1 || c.SeenRetentionDays < 1 || c.OutboxRetentionDays < 1 {
+		return fmt.Errorf("retention periods must be at least one day")
+	}
+
+	if c.HHAPIMaxAttempts < 1 || c.HHAPIMaxAttempts > 10 {
+		return fmt.Errorf("hh api max attempts must be between 1 and 10")
+	}
+
+	if c.HHAPIRetryBaseDelay < 0 || c.HHAPIRetryMaxDelay < c.HHAPIRetryBaseDelay {
+		return fmt.Errorf("invalid hh api retry delays: base %v, max %v", c.HHAPIRetryBaseDelay, c.HHAPIRetryMaxDelay)
+	}
+
+	if c.HHAPIInteractiveRPM <= 0 || c.HHAPIInteractiveBurst < 1 {
+		return fmt.Errorf("hh api interactive rate and burst must be positive")
+	}
+
+	if c.HHAPIBackgroundRPM <= 0 || c.HHAPIBackgroundBurst < 1 {
+		return fmt.Errorf("hh api background rate and burst must be positive")
+	}
+
 	validLogLevels := map[string]bool{
 		"debug": true,
 		"info":  true,
@@ -129,4 +399,13 @@ func (c *Config) Validate() error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (c *Config) IsAdmin(userID int64) bool {
+	for _, id := range c.AdminIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
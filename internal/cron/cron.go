@@ -0,0 +1,218 @@
+// Package cron parses the five-field schedules users set for their checks:
+// minute, hour, day of month, month and day of week, evaluated in the user's time zone.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNoRuns = errors.New("schedule never runs")
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{"минуты", 0, 59},
+	{"часы", 0, 23},
+	{"день месяца", 1, 31},
+	{"месяц", 1, 12},
+	{"день недели", 0, 7}, // 0 and 7 are both Sunday
+}
+
+// Schedule is a parsed expression
+type Schedule struct {
+	expr    string
+	minutes []int
+	hours   []int
+	days    [32]bool
+	months  [13]bool
+	weekday [7]bool
+	// as in classic cron, day of month and day of week both have to match when either starts with *,
+	// and either one is enough when both are restricted
+	anyDay, anyWeekday bool
+}
+
+// Parse reads an expression like "0 9,18 * * 1-5".
+// Each field takes *, numbers, ranges a-b, steps */n or a-b/n and comma-separated lists of them.
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("нужно 5 полей через пробел, а не %d", len(parts))
+	}
+
+	var sets [5][]int
+	for i, part := range parts {
+		values, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fields[i].name, err)
+		}
+		sets[i] = values
+	}
+
+	s := &Schedule{
+		expr:       strings.Join(parts, " "),
+		minutes:    sets[0],
+		hours:      sets[1],
+		anyDay:     strings.HasPrefix(parts[2], "*"),
+		anyWeekday: strings.HasPrefix(parts[4], "*"),
+	}
+	for _, d := range sets[2] {
+		s.days[d] = true
+	}
+	for _, m := range sets[3] {
+		s.months[m] = true
+	}
+	for _, wd := range sets[4] {
+		s.weekday[wd%7] = true
+	}
+
+	return s, nil
+}
+
+func parseField(raw string, f field) ([]int, error) {
+	var seen [61]bool
+
+	for _, item := range strings.Split(raw, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("неверный шаг %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			a, errA := strconv.Atoi(from)
+			b, errB := strconv.Atoi(to)
+			if errA != nil || errB != nil || a > b {
+				return nil, fmt.Errorf("неверный диапазон %q", rangePart)
+			}
+			lo, hi = a, b
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return nil, fmt.Errorf("неверное значение %q", rangePart)
+			}
+			lo = n
+			if !hasStep {
+				hi = n
+			}
+		}
+
+		if lo < f.min || hi > f.max {
+			return nil, fmt.Errorf("значения должны быть от %d до %d", f.min, f.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			seen[v] = true
+		}
+	}
+
+	var values []int
+	for v := f.min; v <= f.max; v++ {
+		if seen[v] {
+			values = append(values, v)
+		}
+	}
+
+	return values, nil
+}
+
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// searchDays bounds Next; four years cover every calendar combination, Feb 29 included
+const searchDays = 4 * 366
+
+// Next returns the first run strictly after t, in t's location
+func (s *Schedule) Next(t time.Time) (time.Time, error) {
+	loc := t.Location()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+
+	for i := 0; i < searchDays; i++ {
+		if s.matchesDay(day) {
+			for _, h := range s.hours {
+				for _, m := range s.minutes {
+					run := wallTime(day, h, m)
+					if run.After(t) {
+						return run, nil
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return time.Time{}, ErrNoRuns
+}
+
+// wallTime is h:m on day in day's location.
+// A time the clock skips when moving forward runs as much later as the clock jumped, like classic cron.
+func wallTime(day time.Time, h, m int) time.Time {
+	run := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, day.Location())
+
+	wanted := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, time.UTC)
+	got := time.Date(run.Year(), run.Month(), run.Day(), run.Hour(), run.Minute(), 0, 0, time.UTC)
+	if shift := wanted.Sub(got); shift > 0 {
+		run = run.Add(shift)
+	}
+	return run
+}
+
+// NextRuns returns the next n runs after t
+func (s *Schedule) NextRuns(t time.Time, n int) ([]time.Time, error) {
+	runs := make([]time.Time, 0, n)
+	for len(runs) < n {
+		next, err := s.Next(t)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, next)
+		t = next
+	}
+	return runs, nil
+}
+
+// MinGap is the shortest time between two runs within the next count runs after t
+func (s *Schedule) MinGap(t time.Time, count int) (time.Duration, error) {
+	runs, err := s.NextRuns(t, count+1)
+	if err != nil {
+		return 0, err
+	}
+
+	gap := runs[1].Sub(runs[0])
+	for i := 2; i < len(runs); i++ {
+		if d := runs[i].Sub(runs[i-1]); d < gap {
+			gap = d
+		}
+	}
+	return gap, nil
+}
+
+func (s *Schedule) matchesDay(day time.Time) bool {
+	if !s.months[day.Month()] {
+		return false
+	}
+
+	dom := s.days[day.Day()]
+	dow := s.weekday[day.Weekday()]
+
+	if s.anyDay || s.anyWeekday {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,261 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s: %v", name, err)
+	}
+	return loc
+}
+
+func mustTime(t *testing.T, loc *time.Location, value string) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "0 9,18 * * 1-5"},
+		{expr: "*/30 10-19 * * *"},
+		{expr: "5/20 * * * *"},
+		{expr: "10-20/5 0 1 1 0"},
+		{expr: "0 9 * * 7"},
+		{expr: "  0   9 * *  * "},
+		{expr: "* * * *", wantErr: true},
+		{expr: "* * * * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "* 24 * * *", wantErr: true},
+		{expr: "* * 0 * *", wantErr: true},
+		{expr: "* * * 13 *", wantErr: true},
+		{expr: "* * * * 8", wantErr: true},
+		{expr: "5-1 * * * *", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "*/x * * * *", wantErr: true},
+		{expr: "a * * * *", wantErr: true},
+		{expr: "1,,2 * * * *", wantErr: true},
+		{expr: "1-x * * * *", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string
+	}{
+		{name: "weekdays skip the weekend", expr: "0 9,18 * * 1-5", from: "2026-03-06 18:00", want: "2026-03-09 09:00"},
+		{name: "strictly after from", expr: "0 9,18 * * 1-5", from: "2026-03-09 09:00", want: "2026-03-09 18:00"},
+		{name: "hour range ends at its last step", expr: "*/30 10-19 * * *", from: "2026-03-06 19:29", want: "2026-03-06 19:30"},
+		{name: "hour range wraps to next day", expr: "*/30 10-19 * * *", from: "2026-03-06 19:30", want: "2026-03-07 10:00"},
+		{name: "step from a value", expr: "5/20 * * * *", from: "2026-03-06 10:06", want: "2026-03-06 10:25"},
+		{name: "step within a range", expr: "10-20/5 * * * *", from: "2026-03-06 10:21", want: "2026-03-06 11:10"},
+		{name: "list of days", expr: "0 12 1,15 * *", from: "2026-03-01 12:00", want: "2026-03-15 12:00"},
+		{name: "31st skips short months", expr: "0 0 31 * *", from: "2026-01-31 00:00", want: "2026-03-31 00:00"},
+		{name: "Feb 29 waits for a leap year", expr: "0 0 29 2 *", from: "2026-01-01 00:00", want: "2028-02-29 00:00"},
+		{name: "month restriction", expr: "0 0 * 2 *", from: "2026-02-28 01:00", want: "2027-02-01 00:00"},
+		{name: "7 is Sunday", expr: "0 9 * * 7", from: "2026-03-06 00:00", want: "2026-03-08 09:00"},
+		{name: "0 is Sunday", expr: "0 9 * * 0", from: "2026-03-06 00:00", want: "2026-03-08 09:00"},
+		{name: "weekday step is honoured", expr: "0 9 * * */2", from: "2026-03-10 10:00", want: "2026-03-12 09:00"},
+		{name: "day of month or day of week: Friday", expr: "0 9 13 * 5", from: "2026-03-07 00:00", want: "2026-03-13 09:00"},
+		{name: "day of month or day of week: next Friday", expr: "0 9 13 * 5", from: "2026-03-13 09:00", want: "2026-03-20 09:00"},
+		{name: "day of month or day of week: the 13th", expr: "0 9 13 * 5", from: "2026-04-10 09:00", want: "2026-04-13 09:00"},
+		{name: "day of month with any weekday", expr: "0 9 13 * *", from: "2026-03-14 00:00", want: "2026-04-13 09:00"},
+		{name: "day of week with any day of month", expr: "0 9 * * 1", from: "2026-03-10 00:00", want: "2026-03-16 09:00"},
+		{name: "stepped day of month must match the weekday too", expr: "0 9 */10 * 1", from: "2026-03-01 00:00", want: "2026-05-11 09:00"},
+	}
+
+	loc := mustLocation(t, "Europe/Moscow")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+
+			got, err := schedule.Next(mustTime(t, loc, tt.from))
+			if err != nil {
+				t.Fatalf("Next(%s): %v", tt.from, err)
+			}
+			if want := mustTime(t, loc, tt.want); !got.Equal(want) {
+				t.Errorf("%q Next(%s) = %s, want %s", tt.expr, tt.from, got, want)
+			}
+		})
+	}
+}
+
+func TestNextNeverRuns(t *testing.T) {
+	for _, expr := range []string{"0 0 30 2 *", "0 0 31 4 *", "0 0 31 2,4,6,9,11 *"} {
+		t.Run(expr, func(t *testing.T) {
+			schedule, err := Parse(expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", expr, err)
+			}
+
+			if _, err := schedule.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrNoRuns) {
+				t.Errorf("Next() error = %v, want ErrNoRuns", err)
+			}
+			if _, err := schedule.MinGap(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 3); !errors.Is(err, ErrNoRuns) {
+				t.Errorf("MinGap() error = %v, want ErrNoRuns", err)
+			}
+		})
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		expr string
+		from string
+		// want is in UTC, the wall clock is ambiguous or missing around a transition
+		want string
+	}{
+		{name: "daily run on the short day", zone: "Europe/Berlin", expr: "0 9 * * *", from: "2026-03-28 09:00", want: "2026-03-29 07:00"},
+		{name: "daily run on the long day", zone: "Europe/Berlin", expr: "0 9 * * *", from: "2026-10-24 09:00", want: "2026-10-25 08:00"},
+		{name: "run inside the skipped hour still happens", zone: "America/New_York", expr: "30 2 * * *", from: "2026-03-08 00:00", want: "2026-03-08 07:30"},
+		{name: "run after the skipped hour", zone: "America/New_York", expr: "0 3 * * *", from: "2026-03-08 00:00", want: "2026-03-08 07:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLocation(t, tt.zone)
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+
+			got, err := schedule.Next(mustTime(t, loc, tt.from))
+			if err != nil {
+				t.Fatalf("Next(%s): %v", tt.from, err)
+			}
+			if want := mustTime(t, time.UTC, tt.want); !got.Equal(want) {
+				t.Errorf("%q Next(%s %s) = %s, want %s UTC", tt.expr, tt.from, tt.zone, got.UTC(), want)
+			}
+			if got.Location() != loc {
+				t.Errorf("Next() location = %s, want %s", got.Location(), loc)
+			}
+		})
+	}
+}
+
+func TestNextRunsIncreaseAcrossDST(t *testing.T) {
+	tests := []struct {
+		zone string
+		from string
+	}{
+		{zone: "America/New_York", from: "2026-03-07 12:00"},
+		{zone: "America/New_York", from: "2026-10-31 12:00"},
+		{zone: "Europe/Berlin", from: "2026-03-28 12:00"},
+		{zone: "Europe/Berlin", from: "2026-10-24 12:00"},
+	}
+
+	schedule, err := Parse("*/30 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zone+" "+tt.from, func(t *testing.T) {
+			loc := mustLocation(t, tt.zone)
+			from := mustTime(t, loc, tt.from)
+
+			runs, err := schedule.NextRuns(from, 2*48)
+			if err != nil {
+				t.Fatalf("NextRuns(): %v", err)
+			}
+
+			prev := from
+			for _, run := range runs {
+				if !run.After(prev) {
+					t.Fatalf("run %s is not after %s", run, prev)
+				}
+				if run.Minute()%30 != 0 {
+					t.Errorf("run %s is off the half hour", run)
+				}
+				prev = run
+			}
+		})
+	}
+}
+
+func TestNextRuns(t *testing.T) {
+	loc := mustLocation(t, "Europe/Moscow")
+	schedule, err := Parse("0 9,18 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := schedule.NextRuns(mustTime(t, loc, "2026-03-06 12:00"), 4)
+	if err != nil {
+		t.Fatalf("NextRuns(): %v", err)
+	}
+
+	want := []string{"2026-03-06 18:00", "2026-03-09 09:00", "2026-03-09 18:00", "2026-03-10 09:00"}
+	if len(runs) != len(want) {
+		t.Fatalf("NextRuns() returned %d runs, want %d", len(runs), len(want))
+	}
+	for i, run := range runs {
+		if w := mustTime(t, loc, want[i]); !run.Equal(w) {
+			t.Errorf("run %d = %s, want %s", i, run, w)
+		}
+	}
+}
+
+func TestMinGap(t *testing.T) {
+	tests := []struct {
+		name  string
+		zone  string
+		expr  string
+		from  string
+		count int
+		want  time.Duration
+	}{
+		{name: "twice a weekday", zone: "Europe/Moscow", expr: "0 9,18 * * 1-5", from: "2026-03-06 12:00", count: 10, want: 9 * time.Hour},
+		{name: "half-hourly", zone: "Europe/Moscow", expr: "*/30 10-19 * * *", from: "2026-03-06 12:00", count: 10, want: 30 * time.Minute},
+		{name: "uneven list", zone: "Europe/Moscow", expr: "0,10,45 * * * *", from: "2026-03-06 12:00", count: 10, want: 10 * time.Minute},
+		{name: "weekly", zone: "Europe/Moscow", expr: "0 10 * * 1", from: "2026-03-06 12:00", count: 3, want: 7 * 24 * time.Hour},
+		{name: "daily across spring forward", zone: "Europe/Berlin", expr: "0 9 * * *", from: "2026-03-27 10:00", count: 3, want: 23 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLocation(t, tt.zone)
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+
+			got, err := schedule.MinGap(mustTime(t, loc, tt.from), tt.count)
+			if err != nil {
+				t.Fatalf("MinGap(): %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("%q MinGap() = %s, want %s", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package dictionaries
+
+import (
+	"context"
+	"time"
+
+	"hh-vacancy-bot/internal/api/headhunter"
+	"hh-vacancy-bot/internal/models"
+	"hh-vacancy-bot/internal/storage/redis"
+
+	"go.uber.org/zap"
+)
+
+// refreshInterval is how often the in-memory copy is re-read; Redis TTL bounds API calls
+const refreshInterval = time.Hour
+
+// Loader keeps models dictionaries in sync with HH /dictionaries via Redis
+type Loader struct {
+	hhClient *headhunter.Client
+	cache    *redis.Cache
+	logger   *zap.Logger
+}
+
+func NewLoader(hhClient *headhunter.Client, cache *redis.Cache, logger *zap.Logger) *Loader {
+	return &Loader{
+		hhClient: hhClient,
+		cache:    cache,
+		logger:   logger,
+	}
+}
+
+func (l *Loader) Start(ctx context.Context) {
+	l.refresh(ctx)
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.refresh(ctx)
+		}
+	}
+}
+
+func (l *Loader) refresh(ctx context.Context) {
+	loadCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	if err := l.Load(loadCtx); err != nil {
+		l.logger.Warn("failed to load dictionaries, using fallback", zap.Error(err))
+	}
+}
+
+// Load reads dictionaries from Redis, falling back to the API on a cache miss
+func (l *Loader) Load(ctx context.Context) error {
+	var dicts headhunter.Dictionaries
+	if err := l.cache.GetDictionaries(ctx, &dicts); err == nil {
+		models.SetDictionaries(convertDictionaries(&dicts))
+		return nil
+	}
+
+	fetched, err := l.hhClient.GetDictionaries(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := l.cache.SetDictionaries(ctx, fetched); err != nil {
+		l.logger.Warn("failed to cache dictionaries", zap.Error(err))
+	}
+
+	models.SetDictionaries(convertDictionaries(fetched))
+
+	l.logger.Info("dictionaries loaded from HH API")
+
+	return nil
+}
+
+func convertDictionaries(d *headhunter.Dictionaries) *models.Dictionaries {
+	result := &models.Dictionaries{
+		Experience:   convertItems(d.Experience),
+		Schedule:     convertItems(d.Schedule),
+		Employment:   convertItems(d.Employment),
+		SearchOrder:  convertItems(d.VacancySearchOrder),
+		SearchFields: convertItems(d.VacancySearchFields),
+		Labels:       convertItems(d.VacancyLabel),
+	}
+
+	for _, currency := range d.Currency {
+		result.Currency = append(result.Currency, models.Currency{
+			Code: currency.Code,
+			Abbr: currency.Abbr,
+			Name: currency.Name,
+		})
+	}
+
+	return result
+}
+
+func convertItems(items []headhunter.IDName) []models.DictionaryItem {
+	result := make([]models.DictionaryItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, models.DictionaryItem{ID: item.ID, Name: item.Name})
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Application tracks a vacancy the user applied to
+type Application struct {
+	ID              int64      `db:"id"`
+	UserID          int64      `db:"user_id"`
+	VacancyID       string     `db:"vacancy_id"`
+	Status          string     `db:"status"`
+	Note            *string    `db:"note"`
+	AppliedAt       time.Time  `db:"applied_at"`
+	StatusChangedAt time.Time  `db:"status_changed_at"`
+	RemindAt        *time.Time `db:"remind_at"` // next follow-up reminder, nil when none is due
+}
+
+const (
+	ApplicationApplied   = "applied"
+	ApplicationHRScreen  = "hr_screen"
+	ApplicationInterview = "interview"
+	ApplicationOffer     = "offer"
+	ApplicationRejected  = "rejected"
+	ApplicationWithdrawn = "withdrawn"
+)
+
+// ApplicationStatuses lists the statuses in the order an application moves through them
+var ApplicationStatuses = []string{
+	ApplicationApplied,
+	ApplicationHRScreen,
+	ApplicationInterview,
+	ApplicationOffer,
+	ApplicationRejected,
+	ApplicationWithdrawn,
+}
+
+func ValidApplicationStatus(status string) bool {
+	for _, s := range ApplicationStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AwaitingReply reports whether the ball is in the employer's court, so a follow-up reminder makes sense
+func (a *Application) AwaitingReply() bool {
+	switch a.Status {
+	case ApplicationApplied, ApplicationHRScreen, ApplicationInterview:
+		return true
+	default:
+		return false
+	}
+}
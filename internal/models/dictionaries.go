@@ -0,0 +1,162 @@
+package models
+
+import "sync/atomic"
+
+// DictionaryItem is one option of an HH dictionary (experience, schedule, ...)
+type DictionaryItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Currency struct {
+	Code string `json:"code"`
+	Abbr string `json:"abbr"`
+	Name string `json:"name"`
+}
+
+// Dictionaries holds filter option lists, normally loaded from HH /dictionaries
+type Dictionaries struct {
+	Experience   []DictionaryItem `json:"experience"`
+	Schedule     []DictionaryItem `json:"schedule"`
+	Employment   []DictionaryItem `json:"employment"`
+	Currency     []Currency       `json:"currency"`
+	SearchOrder  []DictionaryItem `json:"search_order"`
+	SearchFields []DictionaryItem `json:"search_fields"`
+	Labels       []DictionaryItem `json:"labels"`
+}
+
+// FallbackDictionaries are used until HH dictionaries are loaded or when the API is unreachable
+var FallbackDictionaries = &Dictionaries{
+	Experience: []DictionaryItem{
+		{ID: "noExperience", Name: "Нет опыта"},
+		{ID: "between1And3", Name: "От 1 года до 3 лет"},
+		{ID: "between3And6", Name: "От 3 до 6 лет"},
+		{ID: "moreThan6", Name: "Более 6 лет"},
+	},
+	Schedule: []DictionaryItem{
+		{ID: "fullDay", Name: "Полный день"},
+		{ID: "shift", Name: "Сменный график"},
+		{ID: "flexible", Name: "Гибкий график"},
+		{ID: "remote", Name: "Удаленная работа"},
+		{ID: "flyInFlyOut", Name: "Вахтовый метод"},
+	},
+	Employment: []DictionaryItem{
+		{ID: "full", Name: "Полная занятость"},
+		{ID: "part", Name: "Частичная занятость"},
+		{ID: "project", Name: "Проектная работа"},
+		{ID: "volunteer", Name: "Волонтерство"},
+		{ID: "probation", Name: "Стажировка"},
+	},
+	Currency: []Currency{
+		{Code: "RUR", Abbr: "₽", Name: "Рубли"},
+		{Code: "USD", Abbr: "$", Name: "Доллары"},
+		{Code: "EUR", Abbr: "€", Name: "Евро"},
+		{Code: "KZT", Abbr: "₸", Name: "Тенге"},
+		{Code: "BYR", Abbr: "Br", Name: "Белорусские рубли"},
+		{Code: "UAH", Abbr: "₴", Name: "Гривны"},
+		{Code: "UZS", Abbr: "so'm", Name: "Сумы"},
+	},
+	SearchOrder: []DictionaryItem{
+		{ID: "relevance", Name: "по соответствию"},
+		{ID: "publication_time", Name: "по дате изменения"},
+		{ID: "salary_desc", Name: "по убыванию дохода"},
+		{ID: "salary_asc", Name: "по возрастанию дохода"},
+	},
+	SearchFields: []DictionaryItem{
+		{ID: "name", Name: "в названии вакансии"},
+		{ID: "company_name", Name: "в названии компании"},
+		{ID: "description", Name: "в описании вакансии"},
+	},
+	Labels: []DictionaryItem{
+		{ID: "with_address", Name: "С адресом"},
+		{ID: "accept_handicapped", Name: "Доступные людям с инвалидностью"},
+		{ID: "not_from_agency", Name: "Без вакансий агентств"},
+		{ID: "accept_kids", Name: "Доступные с 14 лет"},
+		{ID: "accredited_it", Name: "От аккредитованных ИТ-компаний"},
+		{ID: "low_performance", Name: "Меньше 10 откликов"},
+	},
+}
+
+var currentDictionaries atomic.Pointer[Dictionaries]
+
+// SetDictionaries replaces option lists; empty lists keep their fallback values
+func SetDictionaries(d *Dictionaries) {
+	merged := *d
+	fallback := FallbackDictionaries
+
+	mergeItems := func(dst *[]DictionaryItem, src []DictionaryItem) {
+		if len(*dst) == 0 {
+			*dst = src
+		}
+	}
+
+	mergeItems(&merged.Experience, fallback.Experience)
+	mergeItems(&merged.Schedule, fallback.Schedule)
+	mergeItems(&merged.Employment, fallback.Employment)
+	mergeItems(&merged.SearchOrder, fallback.SearchOrder)
+	mergeItems(&merged.SearchFields, fallback.SearchFields)
+	mergeItems(&merged.Labels, fallback.Labels)
+
+	if len(merged.Currency) == 0 {
+		merged.Currency = fallback.Currency
+	}
+
+	currentDictionaries.Store(&merged)
+}
+
+// GetDictionaries returns loaded dictionaries or the fallback
+func GetDictionaries() *Dictionaries {
+	if d := currentDictionaries.Load(); d != nil {
+		return d
+	}
+	return FallbackDictionaries
+}
+
+// Options returns the dictionary backing a choice filter, nil for free-form filters
+func (d *Dictionaries) Options(filterType string) []DictionaryItem {
+	switch filterType {
+	case FilterTypeExperience:
+		return d.Experience
+	case FilterTypeSchedule:
+		return d.Schedule
+	case FilterTypeEmployment:
+		return d.Employment
+	case FilterTypeSearchField:
+		return d.SearchFields
+	case FilterTypeOrderBy:
+		return d.SearchOrder
+	case FilterTypeLabel:
+		return d.Labels
+	case FilterTypeCurrency:
+		items := make([]DictionaryItem, 0, len(d.Currency))
+		for _, currency := range d.Currency {
+			items = append(items, DictionaryItem{ID: currency.Code, Name: currency.Abbr + " " + currency.Name})
+		}
+		return items
+	default:
+		return nil
+	}
+}
+
+// GetOptionDisplayName resolves an id of a choice filter to its dictionary name
+func GetOptionDisplayName(filterType, id string) string {
+	return findDictionaryName(GetDictionaries().Options(filterType), id)
+}
+
+func findDictionaryName(items []DictionaryItem, id string) string {
+	for _, item := range items {
+		if item.ID == id {
+			return item.Name
+		}
+	}
+	return id
+}
+
+func GetCurrencyAbbr(code string) string {
+	for _, currency := range GetDictionaries().Currency {
+		if currency.Code == code {
+			return currency.Abbr
+		}
+	}
+	return code
+}
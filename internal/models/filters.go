@@ -1,88 +1,57 @@
 package models
 
-var ExperienceMapping = map[string]string{
-	"Нет опыта":     "noExperience",
-	"От 1 до 3 лет": "between1And3",
-	"От 3 до 6 лет": "between3And6",
-	"Более 6 лет":   "moreThan6",
-}
+import "strings"
 
-var ExperienceDisplayNames = map[string]string{
-	"noExperience": "Нет опыта",
-	"between1And3": "От 1 до 3 лет",
-	"between3And6": "От 3 до 6 лет",
-	"moreThan6":    "Более 6 лет",
+func GetExperienceDisplayName(id string) string {
+	return findDictionaryName(GetDictionaries().Experience, id)
 }
 
-var ScheduleMapping = map[string]string{
-	"Полный день":      "fullDay",
-	"Сменный график":   "shift",
-	"Гибкий график":    "flexible",
-	"Удаленная работа": "remote",
-	"Вахтовый метод":   "flyInFlyOut",
+func GetScheduleDisplayName(id string) string {
+	return findDictionaryName(GetDictionaries().Schedule, id)
 }
 
-var ScheduleDisplayNames = map[string]string{
-	"fullDay":     "Полный день",
-	"shift":       "Сменный график",
-	"flexible":    "Гибкий график",
-	"remote":      "Удаленная работа",
-	"flyInFlyOut": "Вахтовый метод",
+func GetEmploymentDisplayName(id string) string {
+	return findDictionaryName(GetDictionaries().Employment, id)
 }
 
-func ExperienceOptions() []string {
-	return []string{
-		"Нет опыта",
-		"От 1 до 3 лет",
-		"От 3 до 6 лет",
-		"Более 6 лет",
-	}
-}
+// Multi-value filters (area, schedule, experience, employment, roles) keep their values comma-separated
 
-func ScheduleOptions() []string {
-	return []string{
-		"Полный день",
-		"Сменный график",
-		"Гибкий график",
-		"Удаленная работа",
-		"Вахтовый метод",
+func SplitFilterValues(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
 	}
+	return values
 }
 
-func IsValidExperience(text string) bool {
-	_, ok := ExperienceMapping[text]
-	return ok
-}
-
-func IsValidSchedule(text string) bool {
-	_, ok := ScheduleMapping[text]
-	return ok
+func JoinFilterValues(values []string) string {
+	return strings.Join(values, ",")
 }
 
-func GetExperienceID(displayName string) string {
-	if id, ok := ExperienceMapping[displayName]; ok {
-		return id
+// ToggleFilterValue adds value to the set or removes it if already present
+func ToggleFilterValue(values []string, value string) []string {
+	out := make([]string, 0, len(values)+1)
+	found := false
+	for _, v := range values {
+		if v == value {
+			found = true
+			continue
+		}
+		out = append(out, v)
 	}
-	return ""
-}
-
-func GetScheduleID(displayName string) string {
-	if id, ok := ScheduleMapping[displayName]; ok {
-		return id
+	if !found {
+		out = append(out, value)
 	}
-	return ""
+	return out
 }
 
-func GetExperienceDisplayName(id string) string {
-	if name, ok := ExperienceDisplayNames[id]; ok {
-		return name
+func ContainsFilterValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
-	return id
+	return false
 }
-
-func GetScheduleDisplayName(id string) string {
-	if name, ok := ScheduleDisplayNames[id]; ok {
-		return name
-	}
-	return id
-}
\ No newline at end of file
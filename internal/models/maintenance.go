@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// MaintenanceRun records one retention run and how much it pruned
+type MaintenanceRun struct {
+	ID                   int64     `db:"id"`
+	StartedAt            time.Time `db:"started_at"`
+	FinishedAt           time.Time `db:"finished_at"`
+	VacanciesDeleted     int64     `db:"vacancies_deleted"`
+	SeenDeleted          int64     `db:"seen_deleted"`
+	NotificationsDeleted int64     `db:"notifications_deleted"`
+	RedisKeysDeleted     int64     `db:"redis_keys_deleted"`
+	Error                *string   `db:"error"` // failed steps, nil when every step succeeded
+}
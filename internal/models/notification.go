@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Notification is a message queued in notification_outbox until the dispatcher delivers it
+type Notification struct {
+	ID                int64      `db:"id"`
+	UserID            int64      `db:"user_id"`
+	SearchID          *int64     `db:"search_id"`
+	VacancyID         *string    `db:"vacancy_id"`
+	Kind              string     `db:"kind"`
+	Payload           RawJSON    `db:"payload"`
+	Status            string     `db:"status"`
+	Attempts          int        `db:"attempts"`
+	NextAttemptAt     time.Time  `db:"next_attempt_at"`
+	LastError         *string    `db:"last_error"`
+	TelegramMessageID *int64     `db:"telegram_message_id"`
+	CreatedAt         time.Time  `db:"created_at"`
+	SentAt            *time.Time `db:"sent_at"`
+	DigestID          *int64     `db:"digest_id"` // digest a held item went out in
+}
+
+const (
+	NotificationKindSummary = "summary" // "new vacancies" header before the cards of one search
+	NotificationKindVacancy = "vacancy"
+	NotificationKindMore    = "more" // link to the vacancies a check found but did not send
+	// HeldSummary heads everything held during one quiet-hours window, inserted once per window
+	NotificationKindHeldSummary = "held_summary"
+	// DigestItem is a vacancy held for the user's digest, Digest is the message that delivers them
+	NotificationKindDigestItem = "digest_item"
+	NotificationKindDigest     = "digest"
+	// ApplicationReminder asks whether to follow up on an application with no reply
+	NotificationKindApplicationReminder = "app_reminder"
+)
+
+// ApplicationReminderPayload points at the application a follow-up reminder is about.
+// The store writes and matches it, the dispatcher reads it.
+type ApplicationReminderPayload struct {
+	ApplicationID int64 `json:"application_id"`
+}
+
+const (
+	NotificationPending = "pending"
+	NotificationHeld    = "held" // waits for the user's digest, never claimed by the dispatcher
+	NotificationSent    = "sent"
+	NotificationDead    = "dead" // gave up: permanent Telegram error or too many attempts
+)
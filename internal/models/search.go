@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Search is a named set of filters checked on its own interval
+type Search struct {
+	ID             int64      `db:"id"`
+	UserID         int64      `db:"user_id"`
+	Name           string     `db:"name"`
+	NotifyInterval int        `db:"notify_interval"` // in min
+	LastCheck      *time.Time `db:"last_check"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+// SearchFeed is an RSS/Atom job feed aggregated into a search next to hh.ru
+type SearchFeed struct {
+	ID        int64     `db:"id"`
+	SearchID  int64     `db:"search_id"`
+	URL       string    `db:"url"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+const (
+	DefaultSearchName   = "Основной поиск"
+	MaxSearchesPerUser  = 10
+	MaxSearchNameLength = 50
+	MaxFeedsPerSearch   = 5
+)
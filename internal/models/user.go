@@ -3,35 +3,173 @@ package models
 import "time"
 
 type User struct {
-	ID             int64      `db:"id"`
-	Username       *string    `db:"username"`
-	FirstName      *string    `db:"first_name"`
-	LastName       *string    `db:"last_name"`
-	CreatedAt      time.Time  `db:"created_at"`
-	LastCheck      *time.Time `db:"last_check"`
-	CheckEnabled   bool       `db:"check_enabled"`
-	NotifyInterval int        `db:"notify_interval"` // in min
+	ID                  int64      `db:"id"`
+	Username            *string    `db:"username"`
+	FirstName           *string    `db:"first_name"`
+	LastName            *string    `db:"last_name"`
+	CreatedAt           time.Time  `db:"created_at"`
+	LastCheck           *time.Time `db:"last_check"`
+	CheckEnabled        bool       `db:"check_enabled"`
+	NotifyInterval      int        `db:"notify_interval"` // in min, default for new searches
+	ActiveSearchID      *int64     `db:"active_search_id"`
+	NextCheckAt         *time.Time `db:"next_check_at"`        // when the user is due: next scheduled run, earliest search interval or retry
+	ConsecutiveFailures int        `db:"consecutive_failures"` // transient failures since the last successful check
+	DeactivatedReason   *string    `db:"deactivated_reason"`   // set when Telegram says the user is unreachable
+	DeactivatedAt       *time.Time `db:"deactivated_at"`
+	Timezone            string     `db:"timezone"`    // IANA name, e.g. Europe/Moscow
+	QuietStart          *int       `db:"quiet_start"` // local hour quiet hours begin, nil when off
+	QuietEnd            *int       `db:"quiet_end"`   // local hour they end
+	DeliveryMode        string     `db:"delivery_mode"`
+	DigestHour          int        `db:"digest_hour"`    // local hour the digest goes out
+	DigestWeekday       int        `db:"digest_weekday"` // time.Weekday of the weekly digest
+	DigestGroup         string     `db:"digest_group"`
+	LastDigestAt        *time.Time `db:"last_digest_at"` // last digest, or when digests were turned on
+	CheckSchedule       *string    `db:"check_schedule"` // cron expression replacing per-search intervals, nil when unset
+}
+
+// Delivery modes: a card per vacancy as soon as it is found, or one digest a day or a week
+const (
+	DeliveryInstant = "instant"
+	DeliveryDaily   = "daily"
+	DeliveryWeekly  = "weekly"
+)
+
+// How a digest groups its vacancies
+const (
+	DigestGroupEmployer = "employer"
+	DigestGroupCity     = "city"
+)
+
+const DefaultTimezone = "Europe/Moscow"
+
+// Location is the user's time zone, Moscow when unset or unknown
+func (u *User) Location() *time.Location {
+	if u.Timezone != "" {
+		if loc, err := time.LoadLocation(u.Timezone); err == nil {
+			return loc
+		}
+	}
+
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// QuietUntil reports whether now falls into the user's quiet hours and when they end, in now's location.
+// A window like 22–8 wraps over midnight.
+func (u *User) QuietUntil(now time.Time) (time.Time, bool) {
+	if u.QuietStart == nil || u.QuietEnd == nil || *u.QuietStart == *u.QuietEnd {
+		return time.Time{}, false
+	}
+
+	local := now.In(u.Location())
+	start, end, hour := *u.QuietStart, *u.QuietEnd, local.Hour()
+
+	quiet := hour >= start && hour < end
+	if start > end {
+		quiet = hour >= start || hour < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end, 0, 0, 0, local.Location())
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+
+	// back in now's zone: timestamp columns drop the offset, so a local wall time would be stored as is
+	return until.In(now.Location()), true
+}
+
+// WantsDigest reports whether new vacancies are held for a daily or weekly digest
+func (u *User) WantsDigest() bool {
+	return u.DeliveryMode == DeliveryDaily || u.DeliveryMode == DeliveryWeekly
+}
+
+// NextDigestAt is the first digest time after the given moment, in the user's time zone
+func (u *User) NextDigestAt(after time.Time) time.Time {
+	local := after.In(u.Location())
+	next := time.Date(local.Year(), local.Month(), local.Day(), u.DigestHour, 0, 0, 0, local.Location())
+
+	if u.DeliveryMode == DeliveryWeekly {
+		next = next.AddDate(0, 0, (u.DigestWeekday-int(next.Weekday())+7)%7)
+		if !next.After(local) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// DigestDue reports whether held vacancies should go out now.
+// Users who switched back to instant delivery get whatever is still held right away.
+func (u *User) DigestDue(now time.Time) bool {
+	if u.DeliveryMode == DeliveryInstant || u.LastDigestAt == nil {
+		return true
+	}
+	return !u.NextDigestAt(*u.LastDigestAt).After(now)
+}
+
+// Reasons a user was deactivated, stored in users.deactivated_reason
+const (
+	DeactivatedBlocked      = "blocked"        // the user blocked the bot
+	DeactivatedChatNotFound = "chat_not_found" // the chat no longer exists
+	DeactivatedUserDeleted  = "user_deleted"   // the Telegram account was deleted
+)
+
+// BotStats is the overview shown to admins
+type BotStats struct {
+	TotalUsers           int
+	CheckEnabled         int
+	Deactivated          map[string]int // by deactivated_reason
+	PendingNotifications int
+	DeadNotifications    int
 }
 
 type UserFilter struct {
 	ID          int64     `db:"id"`
 	UserID      int64     `db:"user_id"`
+	SearchID    int64     `db:"search_id"`
 	FilterType  string    `db:"filter_type"`  // text, area, salary, experience, schedule
 	FilterValue string    `db:"filter_value"` // JSON or string
 	CreatedAt   time.Time `db:"created_at"`
 }
 
 const (
-	FilterTypeText            = "text"
-	FilterTypeArea            = "area"
-	FilterTypeSalary          = "salary"
-	FilterTypeExperience      = "experience"
-	FilterTypeSchedule        = "schedule"
-	FilterTypePublishedWithin = "published_within"
+	FilterTypeText             = "text"
+	FilterTypeArea             = "area"
+	FilterTypeSalary           = "salary"
+	FilterTypeExperience       = "experience"
+	FilterTypeSchedule         = "schedule"
+	FilterTypePublishedWithin  = "published_within"
+	FilterTypeEmployment       = "employment"
+	FilterTypeProfessionalRole = "professional_role"
+	FilterTypeIndustry         = "industry"
+	FilterTypeEmployer         = "employer_id"
+	FilterTypeSearchField      = "search_field" // name, company_name, description
+	FilterTypeOrderBy          = "order_by"
+	FilterTypeLabel            = "label"
+	FilterTypeMetro            = "metro"
+	FilterTypeCurrency         = "currency"
+	FilterTypeOnlyWithSalary   = "only_with_salary" // "true" when set
+	FilterTypeSalaryTo         = "salary_to"
+
+	FilterTypeExcludedEmployers = "excluded_employers" // HH employer ids
+	FilterTypeExcludedWords     = "excluded_words"     // matched against title and snippet
 )
 
 const (
 	DefaultPublishedWithinDays = 30
 	MinPublishedWithinDays     = 1
 	MaxPublishedWithinDays     = 180
+
+	MaxAreasPerSearch = 10
+	MaxIDsPerFilter   = 20
 )
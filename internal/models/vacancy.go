@@ -8,6 +8,8 @@ import (
 
 type Vacancy struct {
 	ID          string    `db:"id"`
+	Source      string    `db:"source"`      // hh, rss, ...
+	ExternalID  string    `db:"external_id"` // id within the source
 	Title       string    `db:"title"`
 	Company     *string   `db:"company"`
 	SalaryFrom  *int      `db:"salary_from"`
@@ -30,6 +32,17 @@ type UserSeenVacancy struct {
 	SeenAt    time.Time `db:"seen_at"`
 }
 
+// SavedVacancy is a vacancy the user bookmarked
+type SavedVacancy struct {
+	ID         int64      `db:"id"`
+	UserID     int64      `db:"user_id"`
+	VacancyID  string     `db:"vacancy_id"`
+	Note       *string    `db:"note"`
+	ArchivedAt *time.Time `db:"archived_at"` // when the source reported the vacancy closed
+	CheckedAt  *time.Time `db:"checked_at"`  // last time the source was asked about it
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
 type RawJSON json.RawMessage
 
 func (r RawJSON) Value() (driver.Value, error) {
@@ -0,0 +1,95 @@
+package source
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Registry builds the set of sources a search aggregates
+type Registry struct {
+	primary VacancySource
+	feed    func(url string) VacancySource
+	logger  *zap.Logger
+}
+
+func NewRegistry(primary VacancySource, feed func(url string) VacancySource, logger *zap.Logger) *Registry {
+	return &Registry{
+		primary: primary,
+		feed:    feed,
+		logger:  logger,
+	}
+}
+
+// ForSearch returns the primary board plus the search's extra feeds
+func (r *Registry) ForSearch(feedURLs []string) VacancySource {
+	if len(feedURLs) == 0 || r.feed == nil {
+		return r.primary
+	}
+
+	feeds := make([]VacancySource, 0, len(feedURLs))
+	for _, u := range feedURLs {
+		feeds = append(feeds, r.feed(u))
+	}
+
+	return &Aggregator{primary: r.primary, feeds: feeds, logger: r.logger}
+}
+
+// Feed returns the source for a single feed url, nil when feeds are disabled
+func (r *Registry) Feed(url string) VacancySource {
+	if r.feed == nil {
+		return nil
+	}
+	return r.feed(url)
+}
+
+// Aggregator merges a paginated primary source with unpaginated feeds.
+// Feeds are only read for the first page.
+type Aggregator struct {
+	primary VacancySource
+	feeds   []VacancySource
+	logger  *zap.Logger
+}
+
+func (a *Aggregator) Name() string {
+	return "aggregate"
+}
+
+func (a *Aggregator) Search(ctx context.Context, q Query) (*Result, error) {
+	result, err := a.primary.Search(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", a.primary.Name(), err)
+	}
+
+	if q.Page > 0 {
+		return result, nil
+	}
+
+	seen := make(map[string]bool, len(result.Items))
+	for i := range result.Items {
+		seen[result.Items[i].CacheID()] = true
+	}
+
+	for _, feed := range a.feeds {
+		feedResult, err := feed.Search(ctx, q)
+		if err != nil {
+			// one broken feed should not hide the rest
+			a.logger.Warn("failed to search feed",
+				zap.String("source", feed.Name()),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		for _, item := range feedResult.Items {
+			if seen[item.CacheID()] {
+				continue
+			}
+			seen[item.CacheID()] = true
+			result.Items = append(result.Items, item)
+		}
+	}
+
+	return result, nil
+}
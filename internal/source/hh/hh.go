@@ -0,0 +1,110 @@
+package hh
+
+import (
+	"context"
+
+	"hh-vacancy-bot/internal/api/headhunter"
+	"hh-vacancy-bot/internal/bot/query"
+	"hh-vacancy-bot/internal/source"
+)
+
+// Source adapts the HeadHunter API to source.VacancySource
+type Source struct {
+	client *headhunter.Client
+}
+
+func New(client *headhunter.Client) *Source {
+	return &Source{client: client}
+}
+
+func (s *Source) Name() string {
+	return source.SourceHH
+}
+
+func (s *Source) Search(ctx context.Context, q source.Query) (*source.Result, error) {
+	params := query.BuildSearchParams(q.Filters)
+	params.Page = q.Page
+	if q.PerPage > 0 {
+		params.PerPage = q.PerPage
+	}
+	if !q.Since.IsZero() {
+		if params.DateFrom == nil || q.Since.After(*params.DateFrom) {
+			since := q.Since
+			params.DateFrom = &since
+		}
+		// newest first, so the pages read within the budget are the freshest ones
+		params.OrderBy = headhunter.OrderByPublicationTime
+	}
+
+	response, err := s.client.SearchVacancies(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]source.Vacancy, 0, len(response.Items))
+	for i := range response.Items {
+		items = append(items, ConvertVacancy(&response.Items[i]))
+	}
+
+	return &source.Result{
+		Items:               items,
+		Found:               response.Found,
+		Page:                response.Page,
+		Pages:               response.Pages,
+		PublishedWithinDays: params.PublishedWithinDays,
+	}, nil
+}
+
+// ConvertVacancy maps an HH search item to the source-agnostic model
+func ConvertVacancy(item *headhunter.VacancyItem) source.Vacancy {
+	vacancy := source.Vacancy{
+		Source:      source.SourceHH,
+		ID:          item.ID,
+		SourceTitle: "hh.ru",
+		Title:       item.Name,
+		URL:         item.AlternateURL,
+		Company:     item.Employer.Name,
+		EmployerID:  item.Employer.ID,
+		Area:        item.Area.Name,
+		AreaID:      item.Area.ID,
+		PublishedAt: item.PublishedAt.Time,
+	}
+
+	if item.Salary != nil {
+		vacancy.Salary = &source.Salary{
+			From:     item.Salary.From,
+			To:       item.Salary.To,
+			Currency: item.Salary.Currency,
+			Gross:    item.Salary.Gross,
+		}
+	}
+
+	if item.Experience != nil {
+		vacancy.Experience = item.Experience.Name
+	}
+
+	if item.Schedule != nil {
+		vacancy.Schedule = item.Schedule.Name
+	}
+
+	if item.Employment != nil {
+		vacancy.Employment = item.Employment.Name
+	}
+
+	for _, role := range item.ProfessionalRoles {
+		if role.Name != "" {
+			vacancy.Roles = append(vacancy.Roles, role.Name)
+		}
+	}
+
+	if item.Snippet != nil {
+		if item.Snippet.Requirement != nil {
+			vacancy.Requirement = *item.Snippet.Requirement
+		}
+		if item.Snippet.Responsibility != nil {
+			vacancy.Responsibility = *item.Snippet.Responsibility
+		}
+	}
+
+	return vacancy
+}
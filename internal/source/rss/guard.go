@@ -0,0 +1,96 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for feeds on loopback, private or link-local addresses.
+// Feed urls come from users, so without it the bot would fetch its own network on their behalf.
+var ErrForbiddenAddress = errors.New("feed address is not public")
+
+// CheckFeedURL rejects feeds whose host is or resolves to a non-public address
+func CheckFeedURL(ctx context.Context, feedURL string) error {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return fmt.Errorf("parse feed url: %w", err)
+	}
+
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if forbiddenAddr(addr) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("resolve feed host: %w", err)
+	}
+
+	for _, addr := range addrs {
+		if forbiddenAddr(addr) {
+			return ErrForbiddenAddress
+		}
+	}
+
+	return nil
+}
+
+// NewHTTPClient builds the client feeds are fetched with.
+// Its dialer refuses non-public addresses at connect time, so redirects and DNS rebinding cannot get around CheckFeedURL.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: refuseForbidden,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// no proxy: the dialer must see the feed's own address
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+func refuseForbidden(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", address, err)
+	}
+	if forbiddenAddr(addrPort.Addr()) {
+		return fmt.Errorf("dial %s: %w", address, ErrForbiddenAddress)
+	}
+	return nil
+}
+
+func forbiddenAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsLoopback() ||
+		addr.IsPrivate() || // 10/8, 172.16/12, 192.168/16, fc00::/7
+		addr.IsLinkLocalUnicast() || // 169.254/16 with cloud metadata, fe80::/10
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() ||
+		sharedAddressSpace.Contains(addr) ||
+		thisNetwork.Contains(addr)
+}
+
+var (
+	sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10") // carrier-grade NAT
+	thisNetwork        = netip.MustParsePrefix("0.0.0.0/8")
+)
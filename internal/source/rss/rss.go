@@ -0,0 +1,292 @@
+package rss
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"hh-vacancy-bot/internal/bot/query"
+	"hh-vacancy-bot/internal/source"
+
+	"go.uber.org/zap"
+)
+
+const (
+	SourceRSS = "rss"
+
+	maxFeedSize = 2 << 20
+)
+
+var tagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+// Source reads vacancies from an RSS 2.0 or Atom job feed
+type Source struct {
+	feedURL    string
+	httpClient *http.Client
+	logger     *zap.Logger
+}
+
+func New(feedURL string, httpClient *http.Client, logger *zap.Logger) *Source {
+	return &Source{
+		feedURL:    feedURL,
+		httpClient: httpClient,
+		logger:     logger,
+	}
+}
+
+func (s *Source) Name() string {
+	return SourceRSS + ":" + s.feedURL
+}
+
+func (s *Source) Search(ctx context.Context, q source.Query) (*source.Result, error) {
+	data, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := Parse(data, feedHost(s.feedURL))
+	if err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+
+	days := query.BuildSearchParams(q.Filters).PublishedWithinDays
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	if q.Since.After(since) {
+		since = q.Since
+	}
+
+	var fresh []source.Vacancy
+	for _, item := range items {
+		// undated entries are kept, seen tracking stops repeats
+		if !item.PublishedAt.IsZero() && item.PublishedAt.Before(since) {
+			continue
+		}
+		fresh = append(fresh, item)
+		if q.PerPage > 0 && len(fresh) == q.PerPage {
+			break
+		}
+	}
+
+	s.logger.Debug("feed vacancies found",
+		zap.String("feed", s.feedURL),
+		zap.Int("total", len(items)),
+		zap.Int("returned", len(fresh)),
+	)
+
+	return &source.Result{
+		Items:               fresh,
+		Found:               len(fresh),
+		Pages:               1,
+		PublishedWithinDays: days,
+	}, nil
+}
+
+func (s *Source) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "HH-Vacancy-Bot/1.0")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetch feed: unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("read feed: %w", err)
+	}
+
+	return data, nil
+}
+
+// ==================== Parsing ====================
+
+type rssFeed struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Description string `xml:"description"`
+	Author      string `xml:"author"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+type atomFeed struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title string `xml:"title"`
+	ID    string `xml:"id"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+// Parse reads RSS 2.0 or Atom into vacancies; fallbackTitle names feeds without a title
+func Parse(data []byte, fallbackTitle string) ([]source.Vacancy, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	switch root.XMLName.Local {
+	case "rss":
+		var feed rssFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, err
+		}
+		return convertRSS(&feed, fallbackTitle), nil
+	case "feed":
+		var feed atomFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, err
+		}
+		return convertAtom(&feed, fallbackTitle), nil
+	default:
+		return nil, fmt.Errorf("unsupported feed format: %s", root.XMLName.Local)
+	}
+}
+
+func convertRSS(feed *rssFeed, fallbackTitle string) []source.Vacancy {
+	title := firstNonEmpty(strings.TrimSpace(feed.Channel.Title), fallbackTitle)
+
+	vacancies := make([]source.Vacancy, 0, len(feed.Channel.Items))
+	for _, item := range feed.Channel.Items {
+		// an item without a link has nothing to open in its card
+		link := strings.TrimSpace(item.Link)
+		if link == "" {
+			continue
+		}
+		key := firstNonEmpty(strings.TrimSpace(item.GUID), link)
+
+		vacancies = append(vacancies, source.Vacancy{
+			Source:      SourceRSS,
+			ID:          hashID(key),
+			SourceTitle: title,
+			Title:       cleanText(item.Title),
+			URL:         link,
+			Company:     cleanText(firstNonEmpty(item.Creator, item.Author)),
+			Requirement: cleanText(item.Description),
+			PublishedAt: parseTime(item.PubDate),
+		})
+	}
+
+	return vacancies
+}
+
+func convertAtom(feed *atomFeed, fallbackTitle string) []source.Vacancy {
+	title := firstNonEmpty(cleanText(feed.Title), fallbackTitle)
+
+	vacancies := make([]source.Vacancy, 0, len(feed.Entries))
+	for _, entry := range feed.Entries {
+		var link string
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = strings.TrimSpace(l.Href)
+				break
+			}
+		}
+
+		if link == "" {
+			continue
+		}
+		key := firstNonEmpty(strings.TrimSpace(entry.ID), link)
+
+		vacancies = append(vacancies, source.Vacancy{
+			Source:      SourceRSS,
+			ID:          hashID(key),
+			SourceTitle: title,
+			Title:       cleanText(entry.Title),
+			URL:         link,
+			Company:     cleanText(entry.Author.Name),
+			Requirement: cleanText(firstNonEmpty(entry.Summary, entry.Content)),
+			PublishedAt: parseTime(firstNonEmpty(entry.Published, entry.Updated)),
+		})
+	}
+
+	return vacancies
+}
+
+// hashID keeps feed ids short enough for vacancies_cache.id
+func hashID(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02T15:04:05Z0700",
+}
+
+func parseTime(raw string) time.Time {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func cleanText(raw string) string {
+	text := html.UnescapeString(tagRegexp.ReplaceAllString(raw, " "))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func feedHost(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return feedURL
+	}
+	return u.Hostname()
+}
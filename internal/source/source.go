@@ -0,0 +1,137 @@
+package source
+
+import (
+	"context"
+	"time"
+
+	"hh-vacancy-bot/internal/models"
+)
+
+const SourceHH = "hh"
+
+// Vacancy is a job posting normalized across boards
+type Vacancy struct {
+	Source         string // board the vacancy came from, e.g. "hh" or "rss"
+	ID             string // id within the source
+	SourceTitle    string // human-readable board or feed name
+	Title          string
+	URL            string
+	Company        string
+	EmployerID     string
+	Area           string
+	AreaID         string
+	Salary         *Salary
+	Experience     string
+	Schedule       string
+	Employment     string
+	Roles          []string
+	Requirement    string
+	Responsibility string
+	PublishedAt    time.Time
+}
+
+type Salary struct {
+	From     *int
+	To       *int
+	Currency string
+	Gross    bool
+}
+
+// CacheID is the vacancies_cache key. HH ids are kept bare so existing rows stay valid.
+func (v *Vacancy) CacheID() string {
+	if v.Source == SourceHH || v.Source == "" {
+		return v.ID
+	}
+	return v.Source + ":" + v.ID
+}
+
+// CacheRecord converts the vacancy to its vacancies_cache row
+func (v *Vacancy) CacheRecord() *models.Vacancy {
+	record := &models.Vacancy{
+		ID:          v.CacheID(),
+		Source:      v.Source,
+		ExternalID:  v.ID,
+		Title:       v.Title,
+		Area:        v.Area,
+		AreaID:      v.AreaID,
+		URL:         v.URL,
+		PublishedAt: v.PublishedAt,
+	}
+
+	if record.Source == "" {
+		record.Source = SourceHH
+	}
+
+	if v.Company != "" {
+		record.Company = &v.Company
+	}
+
+	if v.Salary != nil {
+		record.SalaryFrom = v.Salary.From
+		record.SalaryTo = v.Salary.To
+		record.Currency = &v.Salary.Currency
+	}
+
+	if v.Experience != "" {
+		record.Experience = &v.Experience
+	}
+
+	if v.Schedule != "" {
+		record.Schedule = &v.Schedule
+	}
+
+	if v.Employment != "" {
+		record.Employment = &v.Employment
+	}
+
+	return record
+}
+
+// Query is a source-agnostic search: sources map the stored filters themselves
+type Query struct {
+	Filters map[string]string
+	Page    int
+	PerPage int
+	// Since narrows background checks to vacancies published after it, newest first
+	Since time.Time
+}
+
+type Result struct {
+	Items []Vacancy
+	// Found is the hh.ru total; feeds are read whole, so their items are never left unread
+	Found int
+	Page  int
+	Pages int
+	// PublishedWithinDays is the period the primary source searched
+	PublishedWithinDays int
+}
+
+// Unread is how many hh.ru results were not fetched
+func (r *Result) Unread() int {
+	read := 0
+	for i := range r.Items {
+		if r.Items[i].Source == SourceHH {
+			read++
+		}
+	}
+
+	if unread := r.Found - read; unread > 0 {
+		return unread
+	}
+	return 0
+}
+
+// VacancySource is a job board the bot can search
+type VacancySource interface {
+	Name() string
+	Search(ctx context.Context, q Query) (*Result, error)
+}
+
+// CacheIDs returns vacancies_cache keys of the vacancies
+func CacheIDs(vacancies []Vacancy) []string {
+	ids := make([]string, len(vacancies))
+	for i := range vacancies {
+		ids[i] = vacancies[i].CacheID()
+	}
+	return ids
+}
@@ -0,0 +1,277 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"hh-vacancy-bot/internal/models"
+
+	"github.com/gocraft/dbr/v2"
+	"go.uber.org/zap"
+)
+
+// CreateApplication records that the user applied to a cached vacancy; it returns false when already recorded
+func (s *Store) CreateApplication(ctx context.Context, userID int64, vacancyID string, remindAt time.Time) (bool, error) {
+	now := time.Now()
+
+	res, err := s.sess.InsertBySql(`
+		INSERT INTO user_applications (user_id, vacancy_id, status, applied_at, status_changed_at, remind_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, vacancy_id) DO NOTHING
+	`, userID, vacancyID, models.ApplicationApplied, now, now, remindAt).ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to create application",
+			zap.Int64("user_id", userID),
+			zap.String("vacancy_id", vacancyID),
+			zap.Error(err),
+		)
+		return false, fmt.Errorf("create application: %w", err)
+	}
+
+	created, _ := res.RowsAffected()
+
+	return created > 0, nil
+}
+
+// GetApplications returns all of the user's applications, most recently updated first
+func (s *Store) GetApplications(ctx context.Context, userID int64) ([]models.Application, error) {
+	var applications []models.Application
+
+	_, err := s.sess.
+		Select("*").
+		From("user_applications").
+		Where("user_id = ?", userID).
+		OrderDesc("status_changed_at").
+		OrderDesc("id").
+		LoadContext(ctx, &applications)
+
+	if err != nil {
+		s.logger.Error("failed to get applications",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("get applications: %w", err)
+	}
+
+	return applications, nil
+}
+
+// GetApplication returns the user's application, nil when there is none
+func (s *Store) GetApplication(ctx context.Context, userID, applicationID int64) (*models.Application, error) {
+	var application models.Application
+
+	err := s.sess.
+		Select("*").
+		From("user_applications").
+		Where("id = ? AND user_id = ?", applicationID, userID).
+		LoadOneContext(ctx, &application)
+
+	if err == dbr.ErrNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		s.logger.Error("failed to get application",
+			zap.Int64("application_id", applicationID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("get application: %w", err)
+	}
+
+	return &application, nil
+}
+
+// SetApplicationStatus moves the application on and replaces its reminder.
+// A reminder already queued for the old status is dropped.
+func (s *Store) SetApplicationStatus(ctx context.Context, userID, applicationID int64, status string, remindAt *time.Time) error {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	_, err = tx.Update("user_applications").
+		Set("status", status).
+		Set("status_changed_at", time.Now()).
+		Set("remind_at", remindAt).
+		Where("id = ? AND user_id = ?", applicationID, userID).
+		ExecContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to set application status",
+			zap.Int64("application_id", applicationID),
+			zap.String("status", status),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set application status: %w", err)
+	}
+
+	if err := s.dropQueuedReminders(ctx, tx, userID, applicationID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// SetApplicationReminder schedules the next follow-up reminder, nil turns reminders off
+func (s *Store) SetApplicationReminder(ctx context.Context, userID, applicationID int64, remindAt *time.Time) error {
+	_, err := s.sess.
+		Update("user_applications").
+		Set("remind_at", remindAt).
+		Where("id = ? AND user_id = ?", applicationID, userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to set application reminder",
+			zap.Int64("application_id", applicationID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set application reminder: %w", err)
+	}
+
+	return nil
+}
+
+// SetApplicationNote stores the note, nil removes it
+func (s *Store) SetApplicationNote(ctx context.Context, userID, applicationID int64, note *string) error {
+	_, err := s.sess.
+		Update("user_applications").
+		Set("note", note).
+		Where("id = ? AND user_id = ?", applicationID, userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to set application note",
+			zap.Int64("application_id", applicationID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set application note: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteApplication forgets the application together with its queued reminders
+func (s *Store) DeleteApplication(ctx context.Context, userID, applicationID int64) error {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	_, err = tx.DeleteFrom("user_applications").
+		Where("id = ? AND user_id = ?", applicationID, userID).
+		ExecContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to delete application",
+			zap.Int64("application_id", applicationID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("delete application: %w", err)
+	}
+
+	if err := s.dropQueuedReminders(ctx, tx, userID, applicationID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// dropQueuedReminders removes reminders for the application that have not been sent yet
+func (s *Store) dropQueuedReminders(ctx context.Context, tx *dbr.Tx, userID, applicationID int64) error {
+	payload, err := json.Marshal(models.ApplicationReminderPayload{ApplicationID: applicationID})
+	if err != nil {
+		return fmt.Errorf("encode reminder: %w", err)
+	}
+
+	_, err = tx.DeleteFrom("notification_outbox").
+		Where("user_id = ? AND kind = ? AND status = ?", userID, models.NotificationKindApplicationReminder, models.NotificationPending).
+		Where("payload @> ?::jsonb", string(payload)).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to drop queued application reminders",
+			zap.Int64("application_id", applicationID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("drop application reminders: %w", err)
+	}
+
+	return nil
+}
+
+// GetDueApplicationReminders returns applications whose reminder time has passed, for users who get notifications
+func (s *Store) GetDueApplicationReminders(ctx context.Context, limit int) ([]models.Application, error) {
+	var applications []models.Application
+
+	_, err := s.sess.SelectBySql(`
+		SELECT a.* FROM user_applications a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.remind_at <= NOW() AND u.check_enabled = true
+		ORDER BY a.remind_at
+		LIMIT ?
+	`, limit).LoadContext(ctx, &applications)
+
+	if err != nil {
+		s.logger.Error("failed to get due application reminders", zap.Error(err))
+		return nil, fmt.Errorf("get due application reminders: %w", err)
+	}
+
+	return applications, nil
+}
+
+// EnqueueApplicationReminder queues the reminder and clears remind_at in one transaction.
+// It returns false when the reminder was moved or turned off since it was read.
+func (s *Store) EnqueueApplicationReminder(ctx context.Context, application *models.Application, sendAt time.Time) (bool, error) {
+	payload, err := json.Marshal(models.ApplicationReminderPayload{ApplicationID: application.ID})
+	if err != nil {
+		return false, fmt.Errorf("encode reminder: %w", err)
+	}
+
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	res, err := tx.Update("user_applications").
+		Set("remind_at", nil).
+		Where("id = ? AND remind_at <= NOW()", application.ID).
+		ExecContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("clear application reminder: %w", err)
+	}
+
+	if cleared, _ := res.RowsAffected(); cleared == 0 {
+		return false, nil
+	}
+
+	_, err = tx.InsertBySql(`
+		INSERT INTO notification_outbox (user_id, vacancy_id, kind, payload, status, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, NOW())
+	`, application.UserID, application.VacancyID, models.NotificationKindApplicationReminder, string(payload), models.NotificationPending, sendAt).
+		ExecContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to enqueue application reminder",
+			zap.Int64("application_id", application.ID),
+			zap.Error(err),
+		)
+		return false, fmt.Errorf("enqueue application reminder: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return true, nil
+}
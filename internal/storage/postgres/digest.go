@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hh-vacancy-bot/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// SetDeliveryMode switches between instant cards and digests.
+// The digest clock starts now, so the first digest goes out at the next chosen time.
+func (s *Store) SetDeliveryMode(ctx context.Context, userID int64, mode string, hour, weekday int) error {
+	_, err := s.sess.
+		Update("users").
+		Set("delivery_mode", mode).
+		Set("digest_hour", hour).
+		Set("digest_weekday", weekday).
+		Set("last_digest_at", time.Now()).
+		Where("id = ?", userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to set delivery mode",
+			zap.Int64("user_id", userID),
+			zap.String("mode", mode),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set delivery mode: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) SetDigestGroup(ctx context.Context, userID int64, group string) error {
+	_, err := s.sess.
+		Update("users").
+		Set("digest_group", group).
+		Where("id = ?", userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to set digest grouping",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set digest group: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsersWithHeldVacancies returns active users that have vacancies waiting for a digest
+func (s *Store) GetUsersWithHeldVacancies(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+
+	_, err := s.sess.SelectBySql(`
+		SELECT u.* FROM users u
+		WHERE u.check_enabled = true
+		AND EXISTS (
+			SELECT 1 FROM notification_outbox n
+			WHERE n.user_id = u.id AND n.status = ?
+		)
+	`, models.NotificationHeld).LoadContext(ctx, &users)
+
+	if err != nil {
+		s.logger.Error("failed to get users with held vacancies", zap.Error(err))
+		return nil, fmt.Errorf("get users with held vacancies: %w", err)
+	}
+
+	return users, nil
+}
+
+// EnqueueDigest queues one digest message carrying everything held for the user.
+// It returns false when nothing was held, e.g. another replica got there first.
+func (s *Store) EnqueueDigest(ctx context.Context, userID int64) (bool, error) {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	var digestID int64
+	err = tx.InsertInto("notification_outbox").
+		Pair("user_id", userID).
+		Pair("kind", models.NotificationKindDigest).
+		Pair("payload", "{}").
+		Pair("status", models.NotificationPending).
+		Pair("next_attempt_at", time.Now()).
+		Pair("created_at", time.Now()).
+		Returning("id").
+		LoadContext(ctx, &digestID)
+	if err != nil {
+		s.logger.Error("failed to enqueue digest",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return false, fmt.Errorf("enqueue digest: %w", err)
+	}
+
+	// items count as sent with their digest; the digest row tracks delivery
+	res, err := tx.Update("notification_outbox").
+		Set("status", models.NotificationSent).
+		Set("digest_id", digestID).
+		Set("sent_at", time.Now()).
+		Where("user_id = ? AND status = ?", userID, models.NotificationHeld).
+		ExecContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to attach held vacancies to digest",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return false, fmt.Errorf("attach digest items: %w", err)
+	}
+
+	if attached, _ := res.RowsAffected(); attached == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Update("users").
+		Set("last_digest_at", time.Now()).
+		Where("id = ?", userID).
+		ExecContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("update last digest: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetDigestVacancies returns the vacancies that went out in a digest, in the order they were found
+func (s *Store) GetDigestVacancies(ctx context.Context, userID, digestID int64) ([]models.Vacancy, error) {
+	var vacancies []models.Vacancy
+
+	_, err := s.sess.SelectBySql(`
+		SELECT v.* FROM notification_outbox n
+		JOIN vacancies_cache v ON v.id = n.vacancy_id
+		WHERE n.digest_id = ? AND n.user_id = ?
+		ORDER BY n.id
+	`, digestID, userID).LoadContext(ctx, &vacancies)
+
+	if err != nil {
+		s.logger.Error("failed to get digest vacancies",
+			zap.Int64("digest_id", digestID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("get digest vacancies: %w", err)
+	}
+
+	return vacancies, nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"hh-vacancy-bot/internal/models"
+
+	"go.uber.org/zap"
+)
+
+func (s *Store) AddSearchFeed(ctx context.Context, searchID int64, url string) error {
+	_, err := s.sess.
+		InsertBySql(`
+			INSERT INTO search_feeds (search_id, url, created_at)
+			VALUES (?, ?, NOW())
+			ON CONFLICT (search_id, url) DO NOTHING
+		`, searchID, url).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to add search feed",
+			zap.Int64("search_id", searchID),
+			zap.String("url", url),
+			zap.Error(err),
+		)
+		return fmt.Errorf("add search feed: %w", err)
+	}
+
+	s.logger.Info("search feed added",
+		zap.Int64("search_id", searchID),
+		zap.String("url", url),
+	)
+
+	return nil
+}
+
+func (s *Store) GetSearchFeeds(ctx context.Context, searchID int64) ([]models.SearchFeed, error) {
+	var feeds []models.SearchFeed
+
+	_, err := s.sess.
+		Select("*").
+		From("search_feeds").
+		Where("search_id = ?", searchID).
+		OrderBy("id").
+		LoadContext(ctx, &feeds)
+
+	if err != nil {
+		s.logger.Error("failed to get search feeds",
+			zap.Int64("search_id", searchID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("get search feeds: %w", err)
+	}
+
+	return feeds, nil
+}
+
+// GetSearchFeedURLs returns just the urls, as consumed by source.Registry
+func (s *Store) GetSearchFeedURLs(ctx context.Context, searchID int64) ([]string, error) {
+	feeds, err := s.GetSearchFeeds(ctx, searchID)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(feeds))
+	for _, feed := range feeds {
+		urls = append(urls, feed.URL)
+	}
+
+	return urls, nil
+}
+
+func (s *Store) DeleteSearchFeed(ctx context.Context, searchID, feedID int64) error {
+	_, err := s.sess.
+		DeleteFrom("search_feeds").
+		Where("id = ? AND search_id = ?", feedID, searchID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to delete search feed",
+			zap.Int64("search_id", searchID),
+			zap.Int64("feed_id", feedID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("delete search feed: %w", err)
+	}
+
+	return nil
+}
@@ -12,9 +12,9 @@ import (
 
 func (s *Store) SaveFilter(ctx context.Context, filter *models.UserFilter) error {
 	query := `
-		INSERT INTO user_filters (user_id, filter_type, filter_value, created_at)
-		VALUES (?, ?, ?, NOW())
-		ON CONFLICT (user_id, filter_type)
+		INSERT INTO user_filters (user_id, search_id, filter_type, filter_value, created_at)
+		VALUES (?, ?, ?, ?, NOW())
+		ON CONFLICT (search_id, filter_type)
 		DO UPDATE SET 
 			filter_value = EXCLUDED.filter_value,
 			created_at   = NOW()
@@ -23,11 +23,12 @@ func (s *Store) SaveFilter(ctx context.Context, filter *models.UserFilter) error
 
 	var id int64
 	err := s.sess.
-		SelectBySql(query, filter.UserID, filter.FilterType, filter.FilterValue).
+		SelectBySql(query, filter.UserID, filter.SearchID, filter.FilterType, filter.FilterValue).
 		LoadOneContext(ctx, &id)
 	if err != nil {
 		s.logger.Error("failed to save filter",
 			zap.Int64("user_id", filter.UserID),
+			zap.Int64("search_id", filter.SearchID),
 			zap.String("filter_type", filter.FilterType),
 			zap.Error(err),
 		)
@@ -38,6 +39,7 @@ func (s *Store) SaveFilter(ctx context.Context, filter *models.UserFilter) error
 
 	s.logger.Info("filter saved",
 		zap.Int64("user_id", filter.UserID),
+		zap.Int64("search_id", filter.SearchID),
 		zap.String("filter_type", filter.FilterType),
 		zap.String("filter_value", filter.FilterValue),
 	)
@@ -66,13 +68,34 @@ func (s *Store) GetUserFilters(ctx context.Context, userID int64) ([]models.User
 	return filters, nil
 }
 
-func (s *Store) GetFilter(ctx context.Context, userID int64, filterType string) (*models.UserFilter, error) {
+func (s *Store) GetSearchFilters(ctx context.Context, searchID int64) ([]models.UserFilter, error) {
+	var filters []models.UserFilter
+
+	_, err := s.sess.
+		Select("*").
+		From("user_filters").
+		Where("search_id = ?", searchID).
+		OrderBy("filter_type").
+		LoadContext(ctx, &filters)
+
+	if err != nil {
+		s.logger.Error("failed to get search filters",
+			zap.Int64("search_id", searchID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("get search filters: %w", err)
+	}
+
+	return filters, nil
+}
+
+func (s *Store) GetFilter(ctx context.Context, searchID int64, filterType string) (*models.UserFilter, error) {
 	var filter models.UserFilter
 
 	err := s.sess.
 		Select("*").
 		From("user_filters").
-		Where("user_id = ? AND filter_type = ?", userID, filterType).
+		Where("search_id = ? AND filter_type = ?", searchID, filterType).
 		LoadOneContext(ctx, &filter)
 
 	if err == dbr.ErrNotFound {
@@ -81,7 +104,7 @@ func (s *Store) GetFilter(ctx context.Context, userID int64, filterType string)
 
 	if err != nil {
 		s.logger.Error("failed to get filter",
-			zap.Int64("user_id", userID),
+			zap.Int64("search_id", searchID),
 			zap.String("filter_type", filterType),
 			zap.Error(err),
 		)
@@ -91,15 +114,15 @@ func (s *Store) GetFilter(ctx context.Context, userID int64, filterType string)
 	return &filter, nil
 }
 
-func (s *Store) DeleteFilter(ctx context.Context, userID int64, filterType string) error {
+func (s *Store) DeleteFilter(ctx context.Context, searchID int64, filterType string) error {
 	result, err := s.sess.
 		DeleteFrom("user_filters").
-		Where("user_id = ? AND filter_type = ?", userID, filterType).
+		Where("search_id = ? AND filter_type = ?", searchID, filterType).
 		ExecContext(ctx)
 
 	if err != nil {
 		s.logger.Error("failed to delete filter",
-			zap.Int64("user_id", userID),
+			zap.Int64("search_id", searchID),
 			zap.String("filter_type", filterType),
 			zap.Error(err),
 		)
@@ -112,7 +135,7 @@ func (s *Store) DeleteFilter(ctx context.Context, userID int64, filterType strin
 	}
 
 	s.logger.Info("filter deleted",
-		zap.Int64("user_id", userID),
+		zap.Int64("search_id", searchID),
 		zap.String("filter_type", filterType),
 	)
 
@@ -143,6 +166,30 @@ func (s *Store) ClearUserFilters(ctx context.Context, userID int64) error {
 	return nil
 }
 
+func (s *Store) ClearSearchFilters(ctx context.Context, searchID int64) error {
+	result, err := s.sess.
+		DeleteFrom("user_filters").
+		Where("search_id = ?", searchID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to clear search filters",
+			zap.Int64("search_id", searchID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("clear search filters: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+
+	s.logger.Info("search filters cleared",
+		zap.Int64("search_id", searchID),
+		zap.Int64("count", rowsAffected),
+	)
+
+	return nil
+}
+
 func (s *Store) HasFilters(ctx context.Context, userID int64) (bool, error) {
 	var count int
 
@@ -163,8 +210,8 @@ func (s *Store) HasFilters(ctx context.Context, userID int64) (bool, error) {
 	return count > 0, nil
 }
 
-func (s *Store) GetFiltersMap(ctx context.Context, userID int64) (map[string]string, error) {
-	filters, err := s.GetUserFilters(ctx, userID)
+func (s *Store) GetSearchFiltersMap(ctx context.Context, searchID int64) (map[string]string, error) {
+	filters, err := s.GetSearchFilters(ctx, searchID)
 	if err != nil {
 		return nil, err
 	}
@@ -196,3 +243,55 @@ func (s *Store) CountUserFilters(ctx context.Context, userID int64) (int, error)
 
 	return count, nil
 }
+
+// ReplaceSearchFilters swaps all filters of a search for the given set in one
+// transaction. Filter types listed in keep are left untouched.
+func (s *Store) ReplaceSearchFilters(ctx context.Context, userID, searchID int64, filters map[string]string, keep ...string) error {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	del := tx.DeleteFrom("user_filters").Where("search_id = ?", searchID)
+	if len(keep) > 0 {
+		del = del.Where("filter_type NOT IN ?", keep)
+	}
+
+	if _, err := del.ExecContext(ctx); err != nil {
+		s.logger.Error("failed to delete search filters",
+			zap.Int64("search_id", searchID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("delete search filters: %w", err)
+	}
+
+	for filterType, value := range filters {
+		_, err := tx.InsertBySql(`
+			INSERT INTO user_filters (user_id, search_id, filter_type, filter_value, created_at)
+			VALUES (?, ?, ?, ?, NOW())
+			ON CONFLICT (search_id, filter_type)
+			DO UPDATE SET filter_value = EXCLUDED.filter_value, created_at = NOW()
+		`, userID, searchID, filterType, value).ExecContext(ctx)
+		if err != nil {
+			s.logger.Error("failed to insert search filter",
+				zap.Int64("search_id", searchID),
+				zap.String("filter_type", filterType),
+				zap.Error(err),
+			)
+			return fmt.Errorf("insert search filter: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	s.logger.Info("search filters replaced",
+		zap.Int64("user_id", userID),
+		zap.Int64("search_id", searchID),
+		zap.Int("count", len(filters)),
+	)
+
+	return nil
+}
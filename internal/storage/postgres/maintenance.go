@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"hh-vacancy-bot/internal/models"
+
+	"github.com/gocraft/dbr/v2"
+	"go.uber.org/zap"
+)
+
+func (s *Store) RecordMaintenanceRun(ctx context.Context, run *models.MaintenanceRun) error {
+	err := s.sess.
+		InsertInto("maintenance_runs").
+		Pair("started_at", run.StartedAt).
+		Pair("finished_at", run.FinishedAt).
+		Pair("vacancies_deleted", run.VacanciesDeleted).
+		Pair("seen_deleted", run.SeenDeleted).
+		Pair("notifications_deleted", run.NotificationsDeleted).
+		Pair("redis_keys_deleted", run.RedisKeysDeleted).
+		Pair("error", run.Error).
+		Returning("id").
+		LoadContext(ctx, &run.ID)
+
+	if err != nil {
+		s.logger.Error("failed to record maintenance run", zap.Error(err))
+		return fmt.Errorf("record maintenance run: %w", err)
+	}
+
+	return nil
+}
+
+// GetLastMaintenanceRun returns the latest run, nil before the first one
+func (s *Store) GetLastMaintenanceRun(ctx context.Context) (*models.MaintenanceRun, error) {
+	var run models.MaintenanceRun
+
+	err := s.sess.
+		Select("*").
+		From("maintenance_runs").
+		OrderDesc("started_at").
+		Limit(1).
+		LoadOneContext(ctx, &run)
+
+	if err == dbr.ErrNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		s.logger.Error("failed to get last maintenance run", zap.Error(err))
+		return nil, fmt.Errorf("get last maintenance run: %w", err)
+	}
+
+	return &run, nil
+}
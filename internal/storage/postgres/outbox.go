@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hh-vacancy-bot/internal/models"
+
+	"github.com/gocraft/dbr/v2"
+	"go.uber.org/zap"
+)
+
+// EnqueueVacancyNotifications caches the vacancies, marks them seen and queues the
+// notifications in one transaction, so a crash can neither lose nor duplicate them
+func (s *Store) EnqueueVacancyNotifications(ctx context.Context, userID int64, vacancies []*models.Vacancy, notifications []*models.Notification) error {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	if err := s.cacheAndMarkSeen(ctx, tx, userID, vacancies); err != nil {
+		return err
+	}
+
+	for _, n := range notifications {
+		sendAt := n.NextAttemptAt
+		if sendAt.IsZero() {
+			sendAt = time.Now()
+		}
+		status := n.Status
+		if status == "" {
+			status = models.NotificationPending
+		}
+
+		// rows held for the same quiet hours share one header
+		query := `
+			INSERT INTO notification_outbox (user_id, search_id, vacancy_id, kind, payload, status, next_attempt_at, created_at)
+			SELECT ?, ?, ?, ?, ?, ?, ?, NOW()
+		`
+		args := []interface{}{userID, n.SearchID, n.VacancyID, n.Kind, string(n.Payload), status, sendAt}
+		if n.Kind == models.NotificationKindHeldSummary {
+			query += `WHERE NOT EXISTS (
+				SELECT 1 FROM notification_outbox
+				WHERE user_id = ? AND kind = ? AND status = ?
+			)`
+			args = append(args, userID, n.Kind, models.NotificationPending)
+		}
+
+		_, err := tx.InsertBySql(query, args...).ExecContext(ctx)
+		if err != nil {
+			s.logger.Error("failed to enqueue notification",
+				zap.Int64("user_id", userID),
+				zap.String("kind", n.Kind),
+				zap.Error(err),
+			)
+			return fmt.Errorf("enqueue notification: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDueNotifications locks up to limit pending notifications for lease.
+// Claimed rows are hidden from other dispatchers until the lease passes, so a crashed dispatcher's rows are retried.
+func (s *Store) ClaimDueNotifications(ctx context.Context, limit int, lease time.Duration) ([]models.Notification, error) {
+	var notifications []models.Notification
+
+	_, err := s.sess.SelectBySql(`
+		UPDATE notification_outbox
+		SET next_attempt_at = NOW() + ? * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE status = ? AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, int(lease.Seconds()), models.NotificationPending, limit).LoadContext(ctx, &notifications)
+
+	if err != nil {
+		s.logger.Error("failed to claim notifications", zap.Error(err))
+		return nil, fmt.Errorf("claim notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (s *Store) MarkNotificationSent(ctx context.Context, id int64, messageID int) error {
+	_, err := s.sess.Update("notification_outbox").
+		Set("status", models.NotificationSent).
+		Set("telegram_message_id", messageID).
+		Set("sent_at", time.Now()).
+		Set("attempts", dbr.Expr("attempts + 1")).
+		Where("id = ?", id).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to mark notification sent",
+			zap.Int64("notification_id", id),
+			zap.Error(err),
+		)
+		return fmt.Errorf("mark notification sent: %w", err)
+	}
+
+	return nil
+}
+
+// RetryNotification records a failed attempt and schedules the next one
+func (s *Store) RetryNotification(ctx context.Context, id int64, cause string, nextAttemptAt time.Time) error {
+	_, err := s.sess.Update("notification_outbox").
+		Set("attempts", dbr.Expr("attempts + 1")).
+		Set("last_error", cause).
+		Set("next_attempt_at", nextAttemptAt).
+		Where("id = ?", id).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to reschedule notification",
+			zap.Int64("notification_id", id),
+			zap.Error(err),
+		)
+		return fmt.Errorf("retry notification: %w", err)
+	}
+
+	return nil
+}
+
+// DeadLetterNotification stops retrying a notification, keeping it for inspection
+func (s *Store) DeadLetterNotification(ctx context.Context, id int64, cause string) error {
+	_, err := s.sess.Update("notification_outbox").
+		Set("status", models.NotificationDead).
+		Set("attempts", dbr.Expr("attempts + 1")).
+		Set("last_error", cause).
+		Where("id = ?", id).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to dead-letter notification",
+			zap.Int64("notification_id", id),
+			zap.Error(err),
+		)
+		return fmt.Errorf("dead-letter notification: %w", err)
+	}
+
+	return nil
+}
+
+// CleanOldNotifications deletes sent and dead-lettered notifications older than daysOld days.
+// Pending and held ones are kept however old they are.
+func (s *Store) CleanOldNotifications(ctx context.Context, daysOld int) (int64, error) {
+	result, err := s.sess.
+		DeleteFrom("notification_outbox").
+		Where("status IN ?", []string{models.NotificationSent, models.NotificationDead}).
+		Where("COALESCE(sent_at, created_at) < NOW() - ? * INTERVAL '1 day'", daysOld).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to clean old notifications",
+			zap.Int("days_old", daysOld),
+			zap.Error(err),
+		)
+		return 0, fmt.Errorf("clean old notifications: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+
+	s.logger.Info("old notifications cleaned",
+		zap.Int("days_old", daysOld),
+		zap.Int64("count", rowsAffected),
+	)
+
+	return rowsAffected, nil
+}
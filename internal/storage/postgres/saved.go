@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hh-vacancy-bot/internal/models"
+
+	"github.com/gocraft/dbr/v2"
+	"go.uber.org/zap"
+)
+
+// SaveVacancy bookmarks a cached vacancy; it returns false when it was already saved
+func (s *Store) SaveVacancy(ctx context.Context, userID int64, vacancyID string) (bool, error) {
+	res, err := s.sess.InsertBySql(`
+		INSERT INTO user_saved_vacancies (user_id, vacancy_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id, vacancy_id) DO NOTHING
+	`, userID, vacancyID, time.Now()).ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to save vacancy",
+			zap.Int64("user_id", userID),
+			zap.String("vacancy_id", vacancyID),
+			zap.Error(err),
+		)
+		return false, fmt.Errorf("save vacancy: %w", err)
+	}
+
+	created, _ := res.RowsAffected()
+
+	return created > 0, nil
+}
+
+// GetSavedVacancies returns one page of bookmarks, newest first
+func (s *Store) GetSavedVacancies(ctx context.Context, userID int64, limit, offset int) ([]models.SavedVacancy, error) {
+	var saved []models.SavedVacancy
+
+	_, err := s.sess.
+		Select("*").
+		From("user_saved_vacancies").
+		Where("user_id = ?", userID).
+		OrderDesc("created_at").
+		OrderDesc("id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		LoadContext(ctx, &saved)
+
+	if err != nil {
+		s.logger.Error("failed to get saved vacancies",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("get saved vacancies: %w", err)
+	}
+
+	return saved, nil
+}
+
+func (s *Store) CountSavedVacancies(ctx context.Context, userID int64) (int, error) {
+	var count int
+
+	err := s.sess.
+		Select("COUNT(*)").
+		From("user_saved_vacancies").
+		Where("user_id = ?", userID).
+		LoadOneContext(ctx, &count)
+
+	if err != nil {
+		s.logger.Error("failed to count saved vacancies",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return 0, fmt.Errorf("count saved vacancies: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *Store) DeleteSavedVacancy(ctx context.Context, userID, savedID int64) error {
+	_, err := s.sess.
+		DeleteFrom("user_saved_vacancies").
+		Where("id = ? AND user_id = ?", savedID, userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to delete saved vacancy",
+			zap.Int64("user_id", userID),
+			zap.Int64("saved_id", savedID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("delete saved vacancy: %w", err)
+	}
+
+	return nil
+}
+
+// SetSavedVacancyNote stores the note, nil removes it
+func (s *Store) SetSavedVacancyNote(ctx context.Context, userID, savedID int64, note *string) error {
+	_, err := s.sess.
+		Update("user_saved_vacancies").
+		Set("note", note).
+		Where("id = ? AND user_id = ?", savedID, userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to set saved vacancy note",
+			zap.Int64("user_id", userID),
+			zap.Int64("saved_id", savedID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set saved vacancy note: %w", err)
+	}
+
+	return nil
+}
+
+// MarkSavedVacancyChecked records a status check; an archived vacancy keeps the time it was first seen archived
+func (s *Store) MarkSavedVacancyChecked(ctx context.Context, savedID int64, archived bool) error {
+	now := time.Now()
+
+	update := s.sess.
+		Update("user_saved_vacancies").
+		Set("checked_at", now).
+		Where("id = ?", savedID)
+
+	if archived {
+		update = update.Set("archived_at", dbr.Expr("COALESCE(archived_at, ?)", now))
+	}
+
+	_, err := update.ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to mark saved vacancy checked",
+			zap.Int64("saved_id", savedID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("mark saved vacancy checked: %w", err)
+	}
+
+	return nil
+}
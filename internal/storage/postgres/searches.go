@@ -0,0 +1,273 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hh-vacancy-bot/internal/models"
+
+	"github.com/gocraft/dbr/v2"
+	"go.uber.org/zap"
+)
+
+func (s *Store) CreateSearch(ctx context.Context, search *models.Search) error {
+	query := `
+		INSERT INTO user_searches (user_id, name, notify_interval, created_at)
+		VALUES (?, ?, ?, NOW())
+		RETURNING id
+	`
+
+	var id int64
+	err := s.sess.
+		SelectBySql(query, search.UserID, search.Name, search.NotifyInterval).
+		LoadOneContext(ctx, &id)
+	if err != nil {
+		s.logger.Error("failed to create search",
+			zap.Int64("user_id", search.UserID),
+			zap.String("name", search.Name),
+			zap.Error(err),
+		)
+		return fmt.Errorf("create search: %w", err)
+	}
+
+	search.ID = id
+	s.pullCheckForward(ctx, search.UserID)
+
+	s.logger.Info("search created",
+		zap.Int64("user_id", search.UserID),
+		zap.Int64("search_id", id),
+		zap.String("name", search.Name),
+	)
+
+	return nil
+}
+
+func (s *Store) GetSearch(ctx context.Context, searchID int64) (*models.Search, error) {
+	var search models.Search
+
+	err := s.sess.
+		Select("*").
+		From("user_searches").
+		Where("id = ?", searchID).
+		LoadOneContext(ctx, &search)
+
+	if err == dbr.ErrNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		s.logger.Error("failed to get search",
+			zap.Int64("search_id", searchID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("get search: %w", err)
+	}
+
+	return &search, nil
+}
+
+func (s *Store) GetUserSearches(ctx context.Context, userID int64) ([]models.Search, error) {
+	var searches []models.Search
+
+	_, err := s.sess.
+		Select("*").
+		From("user_searches").
+		Where("user_id = ?", userID).
+		OrderBy("id").
+		LoadContext(ctx, &searches)
+
+	if err != nil {
+		s.logger.Error("failed to get user searches",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("get user searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+// GetDueSearches returns user's searches whose own interval has elapsed
+func (s *Store) GetDueSearches(ctx context.Context, userID int64) ([]models.Search, error) {
+	var searches []models.Search
+
+	query := `
+		SELECT * FROM user_searches
+		WHERE user_id = ?
+		AND (
+			last_check IS NULL
+			OR NOW() - last_check >= (notify_interval || ' minutes')::interval
+		)
+		ORDER BY id
+	`
+
+	_, err := s.sess.
+		SelectBySql(query, userID).
+		LoadContext(ctx, &searches)
+
+	if err != nil {
+		s.logger.Error("failed to get due searches",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("get due searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+func (s *Store) CountUserSearches(ctx context.Context, userID int64) (int, error) {
+	var count int
+
+	err := s.sess.
+		Select("COUNT(*)").
+		From("user_searches").
+		Where("user_id = ?", userID).
+		LoadOneContext(ctx, &count)
+
+	if err != nil {
+		s.logger.Error("failed to count user searches",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return 0, fmt.Errorf("count user searches: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *Store) RenameSearch(ctx context.Context, userID, searchID int64, name string) error {
+	result, err := s.sess.
+		Update("user_searches").
+		Set("name", name).
+		Where("id = ? AND user_id = ?", searchID, userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to rename search",
+			zap.Int64("search_id", searchID),
+			zap.String("name", name),
+			zap.Error(err),
+		)
+		return fmt.Errorf("rename search: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("search not found")
+	}
+
+	return nil
+}
+
+func (s *Store) DeleteSearch(ctx context.Context, userID, searchID int64) error {
+	result, err := s.sess.
+		DeleteFrom("user_searches").
+		Where("id = ? AND user_id = ?", searchID, userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to delete search",
+			zap.Int64("user_id", userID),
+			zap.Int64("search_id", searchID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("delete search: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("search not found")
+	}
+
+	s.logger.Info("search deleted",
+		zap.Int64("user_id", userID),
+		zap.Int64("search_id", searchID),
+	)
+
+	return nil
+}
+
+func (s *Store) SetActiveSearch(ctx context.Context, userID, searchID int64) error {
+	_, err := s.sess.
+		Update("users").
+		Set("active_search_id", searchID).
+		Where("id = ?", userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to set active search",
+			zap.Int64("user_id", userID),
+			zap.Int64("search_id", searchID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set active search: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) SetSearchInterval(ctx context.Context, searchID int64, intervalMinutes int) error {
+	var userID int64
+	err := s.sess.
+		SelectBySql(`UPDATE user_searches SET notify_interval = ? WHERE id = ? RETURNING user_id`, intervalMinutes, searchID).
+		LoadOneContext(ctx, &userID)
+
+	if err != nil {
+		s.logger.Error("failed to set search interval",
+			zap.Int64("search_id", searchID),
+			zap.Int("interval", intervalMinutes),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set search interval: %w", err)
+	}
+
+	s.pullCheckForward(ctx, userID)
+
+	s.logger.Info("search interval updated",
+		zap.Int64("search_id", searchID),
+		zap.Int("interval", intervalMinutes),
+	)
+
+	return nil
+}
+
+// pullCheckForward moves an interval-driven user's next check up to their earliest due search,
+// so a new or more frequent search does not wait for the old due time.
+// Users on a schedule or waiting out a retry keep theirs.
+func (s *Store) pullCheckForward(ctx context.Context, userID int64) {
+	_, err := s.sess.UpdateBySql(`
+		UPDATE users SET next_check_at = LEAST(next_check_at, (
+			SELECT MIN(COALESCE(us.last_check + (us.notify_interval || ' minutes')::interval, NOW()))
+			FROM user_searches us
+			WHERE us.user_id = users.id
+		))
+		WHERE id = ? AND check_schedule IS NULL AND consecutive_failures = 0
+	`, userID).ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to reschedule user check",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *Store) UpdateSearchLastCheck(ctx context.Context, searchID int64) error {
+	_, err := s.sess.
+		Update("user_searches").
+		Set("last_check", time.Now()).
+		Where("id = ?", searchID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to update search last check",
+			zap.Int64("search_id", searchID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("update search last check: %w", err)
+	}
+
+	return nil
+}
@@ -99,12 +99,15 @@ func (s *Store) UpdateUser(ctx context.Context, user *models.User) error {
 	return nil
 }
 
-func (s *Store) UpdateLastCheck(ctx context.Context, userID int64) error {
+// UpdateLastCheck records a successful check, clears any retry backoff and sets when the user is due next
+func (s *Store) UpdateLastCheck(ctx context.Context, userID int64, nextCheckAt time.Time) error {
 	now := time.Now()
 
 	_, err := s.sess.
 		Update("users").
 		Set("last_check", now).
+		Set("next_check_at", nextCheckAt).
+		Set("consecutive_failures", 0).
 		Where("id = ?", userID).
 		ExecContext(ctx)
 
@@ -119,13 +122,81 @@ func (s *Store) UpdateLastCheck(ctx context.Context, userID int64) error {
 	return nil
 }
 
-func (s *Store) SetCheckEnabled(ctx context.Context, userID int64, enabled bool) error {
+// SetCheckSchedule stores the cron expression, nil goes back to per-search intervals.
+// next_check_at is the first run under the new rule.
+func (s *Store) SetCheckSchedule(ctx context.Context, userID int64, schedule *string, nextCheckAt time.Time) error {
 	_, err := s.sess.
 		Update("users").
-		Set("check_enabled", enabled).
+		Set("check_schedule", schedule).
+		Set("next_check_at", nextCheckAt).
 		Where("id = ?", userID).
 		ExecContext(ctx)
 
+	if err != nil {
+		s.logger.Error("failed to set check schedule",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set check schedule: %w", err)
+	}
+
+	return nil
+}
+
+// DeferUserCheck postpones the user's next check after a transient failure
+func (s *Store) DeferUserCheck(ctx context.Context, userID int64, nextCheckAt time.Time) error {
+	_, err := s.sess.
+		Update("users").
+		Set("next_check_at", nextCheckAt).
+		Set("consecutive_failures", dbr.Expr("consecutive_failures + 1")).
+		Where("id = ?", userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to defer user check",
+			zap.Int64("user_id", userID),
+			zap.Time("next_check_at", nextCheckAt),
+			zap.Error(err),
+		)
+		return fmt.Errorf("defer user check: %w", err)
+	}
+
+	return nil
+}
+
+// RescheduleUserCheck moves the user's next check without touching last_check or the failure count
+func (s *Store) RescheduleUserCheck(ctx context.Context, userID int64, nextCheckAt time.Time) error {
+	_, err := s.sess.
+		Update("users").
+		Set("next_check_at", nextCheckAt).
+		Where("id = ?", userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to reschedule user check",
+			zap.Int64("user_id", userID),
+			zap.Time("next_check_at", nextCheckAt),
+			zap.Error(err),
+		)
+		return fmt.Errorf("reschedule user check: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) SetCheckEnabled(ctx context.Context, userID int64, enabled bool) error {
+	update := s.sess.
+		Update("users").
+		Set("check_enabled", enabled).
+		Where("id = ?", userID)
+
+	// a user who turns checks on by hand is reachable again
+	if enabled {
+		update = update.Set("deactivated_reason", nil).Set("deactivated_at", nil)
+	}
+
+	_, err := update.ExecContext(ctx)
+
 	if err != nil {
 		s.logger.Error("failed to set check enabled",
 			zap.Int64("user_id", userID),
@@ -167,6 +238,45 @@ func (s *Store) SetNotifyInterval(ctx context.Context, userID int64, intervalMin
 	return nil
 }
 
+func (s *Store) SetUserTimezone(ctx context.Context, userID int64, timezone string) error {
+	_, err := s.sess.
+		Update("users").
+		Set("timezone", timezone).
+		Where("id = ?", userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to set timezone",
+			zap.Int64("user_id", userID),
+			zap.String("timezone", timezone),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set timezone: %w", err)
+	}
+
+	return nil
+}
+
+// SetQuietHours stores the local hours notifications are held; nil start and end turn them off
+func (s *Store) SetQuietHours(ctx context.Context, userID int64, start, end *int) error {
+	_, err := s.sess.
+		Update("users").
+		Set("quiet_start", start).
+		Set("quiet_end", end).
+		Where("id = ?", userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to set quiet hours",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("set quiet hours: %w", err)
+	}
+
+	return nil
+}
+
 func (s *Store) GetActiveUsers(ctx context.Context) ([]models.User, error) {
 	var users []models.User
 
@@ -184,16 +294,15 @@ func (s *Store) GetActiveUsers(ctx context.Context) ([]models.User, error) {
 	return users, nil
 }
 
+// GetUsersToCheck returns enabled users whose next_check_at has passed, longest waiting first
 func (s *Store) GetUsersToCheck(ctx context.Context) ([]models.User, error) {
 	var users []models.User
 
 	query := `
-		SELECT * FROM users
-		WHERE check_enabled = true
-		AND (
-			last_check IS NULL
-			OR NOW() - last_check >= (notify_interval || ' minutes')::interval
-		)
+		SELECT u.* FROM users u
+		WHERE u.check_enabled = true
+		AND u.next_check_at <= NOW()
+		ORDER BY u.next_check_at
 	`
 
 	_, err := s.sess.
@@ -260,4 +369,148 @@ func (s *Store) GetUserStats(ctx context.Context, userID int64) (map[string]inte
 	stats["seen_vacancies_count"] = seenCount
 
 	return stats, nil
-}
\ No newline at end of file
+}
+
+// DeactivateUser stops checks for a user Telegram no longer delivers to and drops their pending notifications
+func (s *Store) DeactivateUser(ctx context.Context, userID int64, reason string) error {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	_, err = tx.Update("users").
+		Set("check_enabled", false).
+		Set("deactivated_reason", reason).
+		Set("deactivated_at", time.Now()).
+		Where("id = ? AND deactivated_reason IS NULL", userID).
+		ExecContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to deactivate user",
+			zap.Int64("user_id", userID),
+			zap.String("reason", reason),
+			zap.Error(err),
+		)
+		return fmt.Errorf("deactivate user: %w", err)
+	}
+
+	_, err = tx.Update("notification_outbox").
+		Set("status", models.NotificationDead).
+		Set("last_error", "user deactivated: "+reason).
+		Where("user_id = ? AND status IN ?", userID, []string{models.NotificationPending, models.NotificationHeld}).
+		ExecContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to drop notifications of deactivated user",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("drop pending notifications: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	s.logger.Info("user deactivated",
+		zap.Int64("user_id", userID),
+		zap.String("reason", reason),
+	)
+
+	return nil
+}
+
+// ReactivateUser turns checks back on for a deactivated user; false means the user was not deactivated
+func (s *Store) ReactivateUser(ctx context.Context, userID int64) (bool, error) {
+	res, err := s.sess.
+		Update("users").
+		Set("check_enabled", true).
+		Set("deactivated_reason", nil).
+		Set("deactivated_at", nil).
+		Where("id = ? AND deactivated_reason IS NOT NULL", userID).
+		ExecContext(ctx)
+
+	if err != nil {
+		s.logger.Error("failed to reactivate user",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return false, fmt.Errorf("reactivate user: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("reactivate user: %w", err)
+	}
+
+	if n > 0 {
+		s.logger.Info("user reactivated", zap.Int64("user_id", userID))
+	}
+
+	return n > 0, nil
+}
+
+func (s *Store) GetBotStats(ctx context.Context) (*models.BotStats, error) {
+	stats := &models.BotStats{Deactivated: make(map[string]int)}
+
+	err := s.sess.
+		Select("COUNT(*)").
+		From("users").
+		LoadOneContext(ctx, &stats.TotalUsers)
+	if err != nil {
+		s.logger.Error("failed to count users", zap.Error(err))
+		return nil, fmt.Errorf("count users: %w", err)
+	}
+
+	err = s.sess.
+		Select("COUNT(*)").
+		From("users").
+		Where("check_enabled = ?", true).
+		LoadOneContext(ctx, &stats.CheckEnabled)
+	if err != nil {
+		s.logger.Error("failed to count enabled users", zap.Error(err))
+		return nil, fmt.Errorf("count enabled users: %w", err)
+	}
+
+	var reasons []struct {
+		Reason string `db:"deactivated_reason"`
+		Count  int    `db:"count"`
+	}
+	_, err = s.sess.
+		Select("deactivated_reason", "COUNT(*) AS count").
+		From("users").
+		Where("deactivated_reason IS NOT NULL").
+		GroupBy("deactivated_reason").
+		LoadContext(ctx, &reasons)
+	if err != nil {
+		s.logger.Error("failed to count deactivated users", zap.Error(err))
+		return nil, fmt.Errorf("count deactivated users: %w", err)
+	}
+	for _, r := range reasons {
+		stats.Deactivated[r.Reason] = r.Count
+	}
+
+	var outbox []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	_, err = s.sess.
+		Select("status", "COUNT(*) AS count").
+		From("notification_outbox").
+		Where("status IN ?", []string{models.NotificationPending, models.NotificationDead}).
+		GroupBy("status").
+		LoadContext(ctx, &outbox)
+	if err != nil {
+		s.logger.Error("failed to count notifications", zap.Error(err))
+		return nil, fmt.Errorf("count notifications: %w", err)
+	}
+	for _, o := range outbox {
+		switch o.Status {
+		case models.NotificationPending:
+			stats.PendingNotifications = o.Count
+		case models.NotificationDead:
+			stats.DeadNotifications = o.Count
+		}
+	}
+
+	return stats, nil
+}
@@ -12,55 +12,69 @@ import (
 	"go.uber.org/zap"
 )
 
+// cacheVacancyQuery upserts a vacancies_cache row, see cacheVacancyArgs
+const cacheVacancyQuery = `
+	INSERT INTO vacancies_cache (
+		id, title, company, salary_from, salary_to, currency,
+		area, area_id, url, published_at, experience, schedule,
+		employment, raw_data, cached_at, source, external_id
+	)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (source, external_id) DO UPDATE SET
+		title = EXCLUDED.title,
+		company = EXCLUDED.company,
+		salary_from = EXCLUDED.salary_from,
+		salary_to = EXCLUDED.salary_to,
+		currency = EXCLUDED.currency,
+		area = EXCLUDED.area,
+		area_id = EXCLUDED.area_id,
+		url = EXCLUDED.url,
+		published_at = EXCLUDED.published_at,
+		experience = EXCLUDED.experience,
+		schedule = EXCLUDED.schedule,
+		employment = EXCLUDED.employment,
+		raw_data = EXCLUDED.raw_data,
+		cached_at = EXCLUDED.cached_at
+`
+
+func cacheVacancyArgs(vacancy *models.Vacancy) []interface{} {
+	return []interface{}{
+		vacancy.ID,
+		vacancy.Title,
+		vacancy.Company,
+		vacancy.SalaryFrom,
+		vacancy.SalaryTo,
+		vacancy.Currency,
+		vacancy.Area,
+		vacancy.AreaID,
+		vacancy.URL,
+		vacancy.PublishedAt,
+		vacancy.Experience,
+		vacancy.Schedule,
+		vacancy.Employment,
+		vacancy.RawData,
+		time.Now(),
+		vacancy.Source,
+		vacancy.ExternalID,
+	}
+}
+
+const markSeenQuery = `
+	INSERT INTO user_seen_vacancies (user_id, vacancy_id, seen_at)
+	VALUES (?, ?, NOW())
+	ON CONFLICT (user_id, vacancy_id) DO NOTHING
+`
+
 func (s *Store) CacheVacancy(ctx context.Context, vacancy *models.Vacancy) error {
 	// using plain SQL via InsertBySql for ON CONFLICT
-	query := `
-		INSERT INTO vacancies_cache (
-			id, title, company, salary_from, salary_to, currency,
-			area, area_id, url, published_at, experience, schedule,
-			employment, raw_data, cached_at
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
-		ON CONFLICT (id) DO UPDATE SET
-			title = EXCLUDED.title,
-			company = EXCLUDED.company,
-			salary_from = EXCLUDED.salary_from,
-			salary_to = EXCLUDED.salary_to,
-			currency = EXCLUDED.currency,
-			area = EXCLUDED.area,
-			area_id = EXCLUDED.area_id,
-			url = EXCLUDED.url,
-			published_at = EXCLUDED.published_at,
-			experience = EXCLUDED.experience,
-			schedule = EXCLUDED.schedule,
-			employment = EXCLUDED.employment,
-			raw_data = EXCLUDED.raw_data,
-			cached_at = EXCLUDED.cached_at
-	`
-
 	_, err := s.sess.
-		InsertBySql(query,
-			vacancy.ID,
-			vacancy.Title,
-			vacancy.Company,
-			vacancy.SalaryFrom,
-			vacancy.SalaryTo,
-			vacancy.Currency,
-			vacancy.Area,
-			vacancy.AreaID,
-			vacancy.URL,
-			vacancy.PublishedAt,
-			vacancy.Experience,
-			vacancy.Schedule,
-			vacancy.Employment,
-			vacancy.RawData,
-			time.Now(),
-		).
+		InsertBySql(cacheVacancyQuery, cacheVacancyArgs(vacancy)...).
 		ExecContext(ctx)
 
 	if err != nil {
 		s.logger.Error("failed to cache vacancy",
 			zap.String("vacancy_id", vacancy.ID),
+			zap.String("source", vacancy.Source),
 			zap.Error(err),
 		)
 		return fmt.Errorf("cache vacancy: %w", err)
@@ -94,14 +108,8 @@ func (s *Store) GetVacancy(ctx context.Context, vacancyID string) (*models.Vacan
 }
 
 func (s *Store) MarkVacancyAsSeen(ctx context.Context, userID int64, vacancyID string) error {
-	query := `
-		INSERT INTO user_seen_vacancies (user_id, vacancy_id, seen_at)
-		VALUES ($1, $2, NOW())
-		ON CONFLICT (user_id, vacancy_id) DO NOTHING
-	`
-
 	_, err := s.sess.
-		InsertBySql(query, userID, vacancyID).
+		InsertBySql(markSeenQuery, userID, vacancyID).
 		ExecContext(ctx)
 
 	if err != nil {
@@ -116,6 +124,49 @@ func (s *Store) MarkVacancyAsSeen(ctx context.Context, userID int64, vacancyID s
 	return nil
 }
 
+// MarkVacanciesSeen caches the vacancies and marks them seen in one transaction;
+// seen rows reference vacancies_cache, so the cache must be written first
+func (s *Store) MarkVacanciesSeen(ctx context.Context, userID int64, vacancies []*models.Vacancy) error {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	if err := s.cacheAndMarkSeen(ctx, tx, userID, vacancies); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) cacheAndMarkSeen(ctx context.Context, tx *dbr.Tx, userID int64, vacancies []*models.Vacancy) error {
+	for _, vacancy := range vacancies {
+		if _, err := tx.InsertBySql(cacheVacancyQuery, cacheVacancyArgs(vacancy)...).ExecContext(ctx); err != nil {
+			s.logger.Error("failed to cache vacancy",
+				zap.String("vacancy_id", vacancy.ID),
+				zap.Error(err),
+			)
+			return fmt.Errorf("cache vacancy: %w", err)
+		}
+
+		if _, err := tx.InsertBySql(markSeenQuery, userID, vacancy.ID).ExecContext(ctx); err != nil {
+			s.logger.Error("failed to mark vacancy as seen",
+				zap.Int64("user_id", userID),
+				zap.String("vacancy_id", vacancy.ID),
+				zap.Error(err),
+			)
+			return fmt.Errorf("mark vacancy as seen: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (s *Store) IsVacancySeen(ctx context.Context, userID int64, vacancyID string) (bool, error) {
 	var count int
 
@@ -214,10 +265,16 @@ func (s *Store) GetUserSeenVacanciesCount(ctx context.Context, userID int64) (in
 	return count, nil
 }
 
+// CleanOldVacanciesCache deletes vacancies cached more than daysOld days ago
+// that no seen-mark, notification, bookmark or application refers to any more
 func (s *Store) CleanOldVacanciesCache(ctx context.Context, daysOld int) (int64, error) {
 	result, err := s.sess.
 		DeleteFrom("vacancies_cache").
-		Where("cached_at < NOW() - INTERVAL '? days'", daysOld).
+		Where("cached_at < NOW() - ? * INTERVAL '1 day'", daysOld).
+		Where("NOT EXISTS (SELECT 1 FROM user_seen_vacancies sv WHERE sv.vacancy_id = vacancies_cache.id)").
+		Where("NOT EXISTS (SELECT 1 FROM notification_outbox n WHERE n.vacancy_id = vacancies_cache.id)").
+		Where("NOT EXISTS (SELECT 1 FROM user_saved_vacancies b WHERE b.vacancy_id = vacancies_cache.id)").
+		Where("NOT EXISTS (SELECT 1 FROM user_applications a WHERE a.vacancy_id = vacancies_cache.id)").
 		ExecContext(ctx)
 
 	if err != nil {
@@ -241,7 +298,7 @@ func (s *Store) CleanOldVacanciesCache(ctx context.Context, daysOld int) (int64,
 func (s *Store) CleanOldSeenVacancies(ctx context.Context, daysOld int) (int64, error) {
 	result, err := s.sess.
 		DeleteFrom("user_seen_vacancies").
-		Where("seen_at < NOW() - INTERVAL '? days'", daysOld).
+		Where("seen_at < NOW() - ? * INTERVAL '1 day'", daysOld).
 		ExecContext(ctx)
 
 	if err != nil {
@@ -11,6 +11,7 @@ const (
 	VacancySearchCacheTTL  = 5 * time.Minute 
 	RateLimitWindowTTL     = 1 * time.Minute  
 	UserStateCacheTTL      = 30 * time.Minute 
+	DictionariesCacheTTL   = 7 * 24 * time.Hour
 )
 
 
@@ -18,16 +19,31 @@ func CitiesKey() string {
 	return "cities:russia"
 }
 
+func DictionariesKey() string {
+	return "dictionaries"
+}
+
 func VacancySearchKey(userID int64) string {
 	return fmt.Sprintf("search:user:%d", userID)
 }
 
+// SharedSearchKey holds results of a search query shared by every user with the same filters
+func SharedSearchKey(queryKey string) string {
+	return "search:shared:" + queryKey
+}
+
 func RateLimitKey(userID int64) string {
 	return fmt.Sprintf("ratelimit:user:%d", userID)
 }
 
-func HHAPIRateLimitKey() string {
-	return "ratelimit:hhapi"
+// HHAPIBucketKey is the token bucket of one HH API budget, e.g. "interactive"
+func HHAPIBucketKey(budget string) string {
+	return "ratelimit:hhapi:" + budget
+}
+
+// TelegramBucketKey is the token bucket of messages sent by the bot, shared by all instances
+func TelegramBucketKey() string {
+	return "ratelimit:telegram:global"
 }
 
 func UserStateKey(userID int64) string {
@@ -47,6 +63,14 @@ func (c *Cache) SetCities(ctx context.Context, cities interface{}) error {
 	return c.Set(ctx, CitiesKey(), cities, CitiesCacheTTL)
 }
 
+func (c *Cache) GetDictionaries(ctx context.Context, dest interface{}) error {
+	return c.Get(ctx, DictionariesKey(), dest)
+}
+
+func (c *Cache) SetDictionaries(ctx context.Context, dictionaries interface{}) error {
+	return c.Set(ctx, DictionariesKey(), dictionaries, DictionariesCacheTTL)
+}
+
 func (c *Cache) GetVacancySearchResults(ctx context.Context, userID int64) (interface{}, error) {
 	var results interface{}
 	err := c.Get(ctx, VacancySearchKey(userID), &results)
@@ -64,6 +88,14 @@ func (c *Cache) InvalidateVacancySearchCache(ctx context.Context, userID int64)
 	return c.Delete(ctx, VacancySearchKey(userID))
 }
 
+func (c *Cache) GetSharedSearch(ctx context.Context, queryKey string, dest interface{}) error {
+	return c.Get(ctx, SharedSearchKey(queryKey), dest)
+}
+
+func (c *Cache) SetSharedSearch(ctx context.Context, queryKey string, results interface{}, ttl time.Duration) error {
+	return c.Set(ctx, SharedSearchKey(queryKey), results, ttl)
+}
+
 func (c *Cache) IncrementUserRateLimit(ctx context.Context, userID int64) (int64, error) {
 	key := RateLimitKey(userID)
 	return c.IncrementWithExpiry(ctx, key, RateLimitWindowTTL)
@@ -74,14 +106,12 @@ func (c *Cache) GetUserRateLimit(ctx context.Context, userID int64) (int64, erro
 	return c.GetInt(ctx, key)
 }
 
-func (c *Cache) IncrementHHAPIRateLimit(ctx context.Context) (int64, error) {
-	key := HHAPIRateLimitKey()
-	return c.IncrementWithExpiry(ctx, key, RateLimitWindowTTL)
+func (c *Cache) TakeHHAPIToken(ctx context.Context, budget string, rate float64, burst int) (bool, time.Duration, error) {
+	return c.TakeToken(ctx, HHAPIBucketKey(budget), rate, burst)
 }
 
-func (c *Cache) GetHHAPIRateLimit(ctx context.Context) (int64, error) {
-	key := HHAPIRateLimitKey()
-	return c.GetInt(ctx, key)
+func (c *Cache) TakeTelegramToken(ctx context.Context, rate float64, burst int) (bool, time.Duration, error) {
+	return c.TakeToken(ctx, TelegramBucketKey(), rate, burst)
 }
 
 func (c *Cache) SetUserState(ctx context.Context, userID int64, state string) error {
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and takes tokens in one step so concurrent callers cannot overshoot.
+// Redis TIME keeps every bot instance on the same clock.
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local requested = tonumber(ARGV[3])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+
+local state = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local wait_ms = 0
+if tokens >= requested then
+	tokens = tokens - requested
+	allowed = 1
+else
+	wait_ms = math.ceil((requested - tokens) / rate * 1000)
+end
+
+redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', key, math.ceil(burst / rate * 1000) + 1000)
+
+return {allowed, wait_ms}
+`)
+
+// TakeToken takes one token from the bucket at key.
+// rate is tokens per second, burst the bucket size; when denied, wait says when a token frees up.
+func (c *Cache) TakeToken(ctx context.Context, key string, rate float64, burst int) (allowed bool, wait time.Duration, err error) {
+	if rate <= 0 || burst <= 0 {
+		return false, 0, fmt.Errorf("invalid token bucket: rate %v, burst %d", rate, burst)
+	}
+
+	res, err := tokenBucketScript.Run(ctx, c.client, []string{key}, rate, burst, 1).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("token bucket %s: %w", key, err)
+	}
+
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("token bucket %s: unexpected reply %v", key, res)
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
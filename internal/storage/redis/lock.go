@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// refreshLockScript extends the lock only while it still holds our token
+var refreshLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLockScript deletes the lock only while it still holds our token
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func LeaderLockKey(name string) string {
+	return "lock:leader:" + name
+}
+
+func UserCheckLockKey(userID int64) string {
+	return fmt.Sprintf("lock:check:user:%d", userID)
+}
+
+// NewLockToken returns a random value identifying one lock holder
+func NewLockToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate lock token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// AcquireLock takes key for token unless someone else holds it; the lock expires after ttl
+func (c *Cache) AcquireLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	ok, err := c.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("acquire lock %s: %w", key, err)
+	}
+	return ok, nil
+}
+
+// RefreshLock resets the lock ttl; false means the lock expired or passed to another holder
+func (c *Cache) RefreshLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	res, err := refreshLockScript.Run(ctx, c.client, []string{key}, token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("refresh lock %s: %w", key, err)
+	}
+	return res == 1, nil
+}
+
+// ReleaseLock frees the lock if token still holds it
+func (c *Cache) ReleaseLock(ctx context.Context, key, token string) error {
+	if err := releaseLockScript.Run(ctx, c.client, []string{key}, token).Err(); err != nil {
+		return fmt.Errorf("release lock %s: %w", key, err)
+	}
+	return nil
+}
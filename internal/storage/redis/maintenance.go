@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// tempKeyPatterns match per-user scratch data and conversation state, always written with a TTL.
+// Handlers keep the state under user:<id>:state, UserStateKey under state:user:<id>.
+var tempKeyPatterns = []string{"temp:*", "state:*", "user:*:state"}
+
+const vacuumScanBatch = 500
+
+// VacuumTempKeys deletes temp and state keys that have no TTL.
+// Such a key was left by a bug or a manual write and would otherwise never expire.
+func (c *Cache) VacuumTempKeys(ctx context.Context) (int64, error) {
+	var deleted int64
+
+	for _, pattern := range tempKeyPatterns {
+		iter := c.client.Scan(ctx, 0, pattern, vacuumScanBatch).Iterator()
+
+		batch := make([]string, 0, vacuumScanBatch)
+		for iter.Next(ctx) {
+			batch = append(batch, iter.Val())
+			if len(batch) < vacuumScanBatch {
+				continue
+			}
+
+			n, err := c.deletePersistent(ctx, batch)
+			deleted += n
+			if err != nil {
+				return deleted, err
+			}
+			batch = batch[:0]
+		}
+		if err := iter.Err(); err != nil {
+			c.logger.Error("failed to scan temp keys",
+				zap.String("pattern", pattern),
+				zap.Error(err),
+			)
+			return deleted, fmt.Errorf("scan %s: %w", pattern, err)
+		}
+
+		n, err := c.deletePersistent(ctx, batch)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// deletePersistent deletes the keys among keys that have no expiry
+func (c *Cache) deletePersistent(ctx context.Context, keys []string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	pipe := c.client.Pipeline()
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		ttls[i] = pipe.TTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("get key ttls: %w", err)
+	}
+
+	var stale []string
+	for i, ttl := range ttls {
+		// -1 means the key exists without an expiry, -2 that it is already gone
+		if ttl.Val() == -1 {
+			stale = append(stale, keys[i])
+		}
+	}
+
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	n, err := c.client.Del(ctx, stale...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("delete stale keys: %w", err)
+	}
+
+	return n, nil
+}
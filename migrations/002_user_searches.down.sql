@@ -0,0 +1,14 @@
+ALTER TABLE user_filters DROP CONSTRAINT IF EXISTS user_filters_search_id_filter_type_key;
+
+-- keep only the filters of each user's oldest search
+DELETE FROM user_filters f
+USING user_searches s
+WHERE f.search_id = s.id
+AND s.id <> (SELECT MIN(id) FROM user_searches WHERE user_id = s.user_id);
+
+ALTER TABLE user_filters DROP COLUMN IF EXISTS search_id;
+ALTER TABLE user_filters ADD CONSTRAINT user_filters_user_id_filter_type_key UNIQUE (user_id, filter_type);
+
+ALTER TABLE users DROP COLUMN IF EXISTS active_search_id;
+
+DROP TABLE IF EXISTS user_searches;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS user_searches (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    notify_interval INT NOT NULL DEFAULT 60,
+    last_check TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_searches_user_id ON user_searches(user_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS active_search_id INT REFERENCES user_searches(id) ON DELETE SET NULL;
+
+-- every user with filters gets one default search holding them
+INSERT INTO user_searches (user_id, name, notify_interval, last_check)
+SELECT u.id, 'Основной поиск', u.notify_interval, u.last_check
+FROM users u
+WHERE EXISTS (SELECT 1 FROM user_filters f WHERE f.user_id = u.id)
+ON CONFLICT (user_id, name) DO NOTHING;
+
+ALTER TABLE user_filters ADD COLUMN IF NOT EXISTS search_id INT REFERENCES user_searches(id) ON DELETE CASCADE;
+
+UPDATE user_filters f
+SET search_id = s.id
+FROM user_searches s
+WHERE s.user_id = f.user_id AND f.search_id IS NULL;
+
+ALTER TABLE user_filters ALTER COLUMN search_id SET NOT NULL;
+ALTER TABLE user_filters DROP CONSTRAINT IF EXISTS user_filters_user_id_filter_type_key;
+ALTER TABLE user_filters ADD CONSTRAINT user_filters_search_id_filter_type_key UNIQUE (search_id, filter_type);
+
+UPDATE users u
+SET active_search_id = s.id
+FROM user_searches s
+WHERE s.user_id = u.id AND u.active_search_id IS NULL;
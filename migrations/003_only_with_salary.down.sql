@@ -0,0 +1 @@
+DELETE FROM user_filters WHERE filter_type = 'only_with_salary';
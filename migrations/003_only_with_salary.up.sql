@@ -0,0 +1,6 @@
+-- salary used to imply only_with_salary; keep existing searches behaving the same
+INSERT INTO user_filters (user_id, search_id, filter_type, filter_value, created_at)
+SELECT user_id, search_id, 'only_with_salary', 'true', NOW()
+FROM user_filters
+WHERE filter_type = 'salary'
+ON CONFLICT (search_id, filter_type) DO NOTHING;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS search_feeds;
+
+DELETE FROM user_seen_vacancies
+WHERE vacancy_id IN (SELECT id FROM vacancies_cache WHERE source <> 'hh');
+DELETE FROM vacancies_cache WHERE source <> 'hh';
+
+ALTER TABLE vacancies_cache DROP CONSTRAINT IF EXISTS vacancies_cache_source_external_id_key;
+ALTER TABLE vacancies_cache DROP COLUMN IF EXISTS external_id;
+ALTER TABLE vacancies_cache DROP COLUMN IF EXISTS source;
@@ -0,0 +1,17 @@
+-- vacancies can come from several boards; dedupe them by source + id within the source
+ALTER TABLE vacancies_cache ADD COLUMN IF NOT EXISTS source VARCHAR(30) NOT NULL DEFAULT 'hh';
+ALTER TABLE vacancies_cache ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+
+UPDATE vacancies_cache SET external_id = id WHERE external_id IS NULL;
+
+ALTER TABLE vacancies_cache ALTER COLUMN external_id SET NOT NULL;
+ALTER TABLE vacancies_cache ADD CONSTRAINT vacancies_cache_source_external_id_key UNIQUE (source, external_id);
+
+-- extra feeds aggregated into a search next to hh.ru
+CREATE TABLE IF NOT EXISTS search_feeds (
+    id SERIAL PRIMARY KEY,
+    search_id INT NOT NULL REFERENCES user_searches(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(search_id, url)
+);
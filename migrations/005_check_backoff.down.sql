@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS consecutive_failures;
+ALTER TABLE users DROP COLUMN IF EXISTS next_check_at;
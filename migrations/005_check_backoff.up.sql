@@ -0,0 +1,3 @@
+-- checks that fail for transient reasons are retried with backoff instead of being marked done
+ALTER TABLE users ADD COLUMN IF NOT EXISTS next_check_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS consecutive_failures INT NOT NULL DEFAULT 0;
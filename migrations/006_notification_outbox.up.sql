@@ -0,0 +1,20 @@
+-- vacancy notifications are queued together with the seen-marks and delivered by a dispatcher
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    search_id INT REFERENCES user_searches(id) ON DELETE CASCADE,
+    vacancy_id VARCHAR(50) REFERENCES vacancies_cache(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    telegram_message_id BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_pending
+    ON notification_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_user_id ON notification_outbox(user_id);
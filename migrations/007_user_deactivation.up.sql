@@ -0,0 +1,3 @@
+-- users who blocked the bot or deleted their account stop being checked until they /start again
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_reason VARCHAR(50);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP;
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS quiet_end;
+ALTER TABLE users DROP COLUMN IF EXISTS quiet_start;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,4 @@
+-- notifications found during quiet hours are held until the window ends in the user's time zone
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'Europe/Moscow';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS quiet_start INT CHECK (quiet_start BETWEEN 0 AND 23);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS quiet_end INT CHECK (quiet_end BETWEEN 0 AND 23);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_notification_outbox_digest_id;
+DROP INDEX IF EXISTS idx_notification_outbox_held;
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS digest_id;
+ALTER TABLE users DROP COLUMN IF EXISTS last_digest_at;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_group;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_weekday;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_hour;
+ALTER TABLE users DROP COLUMN IF EXISTS delivery_mode;
@@ -0,0 +1,16 @@
+-- users may get new vacancies as one daily or weekly digest instead of a card per vacancy
+ALTER TABLE users ADD COLUMN IF NOT EXISTS delivery_mode VARCHAR(20) NOT NULL DEFAULT 'instant'
+    CHECK (delivery_mode IN ('instant', 'daily', 'weekly'));
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_hour INT NOT NULL DEFAULT 9 CHECK (digest_hour BETWEEN 0 AND 23);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_weekday INT NOT NULL DEFAULT 1 CHECK (digest_weekday BETWEEN 0 AND 6);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_group VARCHAR(20) NOT NULL DEFAULT 'employer'
+    CHECK (digest_group IN ('employer', 'city'));
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_digest_at TIMESTAMP;
+
+-- held items wait for the digest and then point at the digest row they went out in
+ALTER TABLE notification_outbox ADD COLUMN IF NOT EXISTS digest_id BIGINT REFERENCES notification_outbox(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_held
+    ON notification_outbox(user_id) WHERE status = 'held';
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_digest_id
+    ON notification_outbox(digest_id) WHERE digest_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_next_check_at;
+ALTER TABLE users ALTER COLUMN next_check_at DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN next_check_at DROP DEFAULT;
+ALTER TABLE users DROP COLUMN IF EXISTS check_schedule;
@@ -0,0 +1,8 @@
+-- users may replace per-search intervals with a cron schedule; next_check_at becomes the single due time
+ALTER TABLE users ADD COLUMN IF NOT EXISTS check_schedule VARCHAR(100);
+
+UPDATE users SET next_check_at = NOW() WHERE next_check_at IS NULL;
+ALTER TABLE users ALTER COLUMN next_check_at SET DEFAULT NOW();
+ALTER TABLE users ALTER COLUMN next_check_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_next_check_at ON users(next_check_at) WHERE check_enabled = true;
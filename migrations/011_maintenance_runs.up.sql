@@ -0,0 +1,13 @@
+-- one row per retention run: what was pruned and whether any step failed
+CREATE TABLE IF NOT EXISTS maintenance_runs (
+    id BIGSERIAL PRIMARY KEY,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    vacancies_deleted BIGINT NOT NULL DEFAULT 0,
+    seen_deleted BIGINT NOT NULL DEFAULT 0,
+    notifications_deleted BIGINT NOT NULL DEFAULT 0,
+    redis_keys_deleted BIGINT NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_runs_started_at ON maintenance_runs(started_at);
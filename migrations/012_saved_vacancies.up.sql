@@ -0,0 +1,13 @@
+-- vacancies the user bookmarked from a card, with an optional note
+CREATE TABLE IF NOT EXISTS user_saved_vacancies (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    vacancy_id VARCHAR(50) NOT NULL REFERENCES vacancies_cache(id),
+    note TEXT,
+    archived_at TIMESTAMP,
+    checked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, vacancy_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_saved_vacancies_user_id ON user_saved_vacancies(user_id, created_at DESC);
@@ -0,0 +1,17 @@
+-- vacancies the user applied to and how each application is going
+CREATE TABLE IF NOT EXISTS user_applications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    vacancy_id VARCHAR(50) NOT NULL REFERENCES vacancies_cache(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'applied'
+        CHECK (status IN ('applied', 'hr_screen', 'interview', 'offer', 'rejected', 'withdrawn')),
+    note TEXT,
+    applied_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    status_changed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    remind_at TIMESTAMP, -- next follow-up reminder, NULL when none is due
+    UNIQUE(user_id, vacancy_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_applications_user_id ON user_applications(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_applications_remind_at
+    ON user_applications(remind_at) WHERE remind_at IS NOT NULL;